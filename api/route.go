@@ -13,6 +13,7 @@
 // @SubApi Users management API [/users]
 // @SubApi Location management API [/locations]
 // @SubApi Upload management API [/upload]
+// @SubApi Search API [/search]
 
 package api
 
@@ -53,5 +54,20 @@ func RouteAPI(parentRoute *echo.Echo) {
 		v1.InitProblems(route)
 		v1.InitReason(route)
 		v1.InitAds(route)
+		v1.InitSearch(route)
+		v1.InitRail(route)
+		v1.InitRating(route)
+		v1.InitReview(route)
+		v1.InitPayment(route)
+		v1.InitRefund(route)
+		v1.InitWallet(route)
+		v1.InitPromotion(route)
+		v1.InitReferral(route)
+		v1.InitPayout(route)
+		v1.InitEarning(route)
+		v1.InitOnboarding(route)
+		v1.InitShift(route)
+		v1.InitTracking(route)
+		v1.InitSurge(route)
 	}
 }
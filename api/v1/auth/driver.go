@@ -8,6 +8,7 @@ import (
 	"../../../service/authService"
 	"../../../service/authService/driverService"
 	"../../../service/authService/permission"
+	"../../../service/onboardingService"
 	"../../../util/crypto"
 	"../../../util/timeHelper"
 	"../../response"
@@ -99,6 +100,7 @@ func registerDriver(c echo.Context) error {
 	if err != nil {
 		return response.KnownErrJSON(c, "err.driver.create", err)
 	}
+	onboardingService.RefreshOnboarding(driver.ID)
 	// send to verification email to driver email
 	authService.SendVerifyCode(driver.Email, config.RoleDriver, config.TwilloMethod)
 
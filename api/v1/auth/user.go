@@ -8,6 +8,7 @@ import (
 	"../../../service/authService"
 	"../../../service/authService/permission"
 	"../../../service/authService/userService"
+	"../../../service/referralService"
 	"../../../util/crypto"
 	"../../../util/timeHelper"
 	"../../response"
@@ -77,6 +78,8 @@ func loginUser(c echo.Context) error {
 // @Param   lastname   	form   string   true	"User Lastname."
 // @Param   email       form   string   true	"User Email."
 // @Param   password	form   string 	true	"User Password."
+// @Param   referralCode	form   string 	false	"Referral code of inviter."
+// @Param   deviceId	form   string 	false	"Device ID of user."
 // @Success 200 {object} UserForm				"Returns registered user"
 // @Failure 400 {object} response.BasicResponse "err.user.bind"
 // @Failure 400 {object} response.BasicResponse "err.user.exist"
@@ -101,11 +104,16 @@ func registerUser(c echo.Context) error {
 		}
 		userService.DeleteUser(u.ID)
 	}
+	// check referral code of inviter
+	if err := referralService.ValidateReferralCode(user.ReferralCode); err != nil {
+		return response.KnownErrJSON(c, "err.user.referral", err)
+	}
 	// create user with registered info
 	user, err := userService.CreateUser(user)
 	if err != nil {
 		return response.KnownErrJSON(c, "err.user.create", err)
 	}
+	referralService.ApplyReferral(user)
 	// send to verification email to user email
 	authService.SendVerifyCode(user.Email, config.RoleUser, config.TwilloMethod)
 
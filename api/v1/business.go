@@ -9,6 +9,10 @@ import (
 	"../../model"
 	"../../service/authService/businessService"
 	"../../service/authService/permission"
+	"../../service/locationService"
+	"../../service/railService"
+	"../../service/searchService"
+	"../../service/zoneService"
 	"../response"
 
 	"github.com/labstack/echo"
@@ -34,8 +38,10 @@ func InitBusinesses(parentRoute *echo.Group) {
 
 	route.GET("/:id/dietary", permission.AuthRequired(readBusinessDietary))
 	route.GET("/:id/mealKind", permission.AuthRequired(readBusinessMealKind))
+	route.PUT("/:id/delivery", permission.AuthRequired(updateBusinessDelivery))
 
 	route.POST("/query", permission.AuthRequired(readQueryBusinesses))
+	route.POST("/discover", permission.AuthRequired(discoverBusinesses))
 
 	businessService.InitService()
 }
@@ -216,51 +222,122 @@ func readQueryBusinesses(c echo.Context) error {
 		return response.KnownErrJSON(c, "err.query.bind", err)
 	}
 
-	lat := queryBusiness.Lat
-	lng := queryBusiness.Lng
-	if queryBusiness.Sort == config.SortRecommend && len(queryBusiness.Price) == 0 && len(queryBusiness.Dietary) == 0 {
-		// search popular
-		popular, _ := businessService.ReadNearbyPopularBusiness(lat, lng)
-		excepts := []bson.ObjectId{}
-		for _, b := range popular {
-			excepts = append(excepts, b.ID)
-			businessService.RetrieveBusinessBaseStructure(b)
-		}
-		// serach recommend
-		recommend, _ := businessService.ReadRecommendBusiness(lat, lng)
-		for _, b := range recommend {
-			excepts = append(excepts, b.ID)
-			businessService.RetrieveBusinessBaseStructure(b)
+	if queryBusiness.Query != "" {
+		// text search is ranked with relevance and distance
+		businesses, _, err := searchService.SearchBusinesses(queryBusiness, discoveryRadius(queryBusiness.PlaceID))
+		if err != nil {
+			return response.KnownErrJSON(c, "err.search.read", err)
 		}
-		// serarch unders
-		under, _ := businessService.ReadUnder30Business(lat, lng)
-		for _, b := range under {
-			excepts = append(excepts, b.ID)
+		for _, b := range businesses {
 			businessService.RetrieveBusinessBaseStructure(b)
 		}
-		// read more
-		more, _ := businessService.ReadMoreBusiness(lat, lng, excepts)
-		for _, b := range more {
-			businessService.RetrieveBusinessBaseStructure(b)
-		}
-
 		return response.SuccessInterface(c, []*model.ListBusiness{
-			{config.QueryPopular, true, popular},
-			{config.QueryRecommend, true, recommend},
-			{config.QueryUnder, true, under},
-			{config.QueryAll, false, more},
+			{QueryCode: config.QueryAll, Items: businesses},
 		})
 	}
 
-	businesses, err := businessService.ReadQueryBusiness(queryBusiness)
+	if queryBusiness.Sort == config.SortRecommend && len(queryBusiness.Price) == 0 && len(queryBusiness.Dietary) == 0 {
+		// home screen rails are configured by admin
+		rails, err := railService.ReadRails(true)
+		if err != nil {
+			return response.KnownErrJSON(c, "err.rail.read", err)
+		}
+		radius := discoveryRadius(queryBusiness.PlaceID)
+		lists := []*model.ListBusiness{}
+		shown := []bson.ObjectId{}
+		for _, rail := range rails {
+			query := model.DiscoveryQuery{}
+			if rail.Query != nil {
+				query = *rail.Query
+			}
+			query.Lat = queryBusiness.Lat
+			query.Lng = queryBusiness.Lng
+			// rail of all businesses shows other businesses than rails before it
+			if rail.Code == config.QueryAll {
+				query.Exclude = shown
+			}
+			result, err := businessService.DiscoverBusinesses(&query, radius)
+			if err != nil {
+				return response.KnownErrJSON(c, "err.business.discover", err)
+			}
+			for _, b := range result.Items {
+				businessService.RetrieveBusinessBaseStructure(b)
+				shown = append(shown, b.ID)
+			}
+			lists = append(lists, &model.ListBusiness{
+				QueryCode:  rail.Code,
+				Title:      rail.Title,
+				IsSlide:    rail.IsSlide,
+				Items:      result.Items,
+				NextCursor: result.NextCursor,
+			})
+		}
+		return response.SuccessInterface(c, lists)
+	}
+
+	businesses, err := businessService.ReadQueryBusiness(queryBusiness, discoveryRadius(queryBusiness.PlaceID))
 	if err != nil {
 		return response.KnownErrJSON(c, "err.business.mealKind.read", err)
 	}
+	businesses = zoneService.FilterDeliverable(businesses, []float64{queryBusiness.Lng, queryBusiness.Lat})
 	for _, b := range businesses {
 		businessService.RetrieveBusinessBaseStructure(b)
 	}
 
 	return response.SuccessInterface(c, []*model.ListBusiness{
-		{config.QueryAll, false, businesses},
+		{QueryCode: config.QueryAll, Items: businesses},
 	})
 }
+
+// @Title discoverBusinesses
+// @Description Discover nearby businesses with filters, facet counts and cursor pagination.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   lat				form   	float64 true	"Latitude of customer."
+// @Param   lng				form   	float64 true	"Longitude of customer."
+// @Param   placeId			form   	string  false	"Place id of location when coordinates are out of zones, radius of location is used."
+// @Param   price			form   	[]int   false	"Price levels."
+// @Param   dietary			form   	[]int   false	"Dietary codes."
+// @Param   mealKind		form   	[]int   false	"Meal kind codes."
+// @Param   openNow			form   	bool    false	"Only opened businesses."
+// @Param   freeDelivery	form   	bool    false	"Only businesses with free delivery."
+// @Param   minRating		form   	float32 false	"Minimum rating."
+// @Param   maxEta			form   	int     false	"Maximum ETA in minutes."
+// @Param   sort			form   	string  false	"distance, rating, popularity, eta"
+// @Param   cursor			form   	string  false	"Next cursor of previous page."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.DiscoveryResult 	"Returns page of businesses with facets"
+// @Failure 400 {object} response.BasicResponse "err.query.bind"
+// @Failure 400 {object} response.BasicResponse "err.business.discover"
+// @Resource /businesses
+// @Router /businesses/discover [post]
+func discoverBusinesses(c echo.Context) error {
+	query := &model.DiscoveryQuery{}
+	if err := c.Bind(query); err != nil {
+		return response.KnownErrJSON(c, "err.query.bind", err)
+	}
+
+	// city is resolved from coordinates of customer
+	if zone, err := zoneService.ResolveZone([]float64{query.Lng, query.Lat}); err == nil {
+		query.PlaceID = zone.PlaceID
+	}
+	result, err := businessService.DiscoverBusinesses(query, discoveryRadius(query.PlaceID))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.business.discover", err)
+	}
+	for _, b := range result.Items {
+		businessService.RetrieveBusinessBaseStructure(b)
+	}
+	return response.SuccessInterface(c, result)
+}
+
+// discoveryRadius returns search radius of location, default radius is used for unknown location
+func discoveryRadius(placeID string) float64 {
+	if placeID != "" {
+		if location, err := locationService.ReadLocationWithPlaceID(placeID); err == nil && location.SearchRadius > 0 {
+			return location.SearchRadius
+		}
+	}
+	return config.DefaultDiscoverRadius
+}
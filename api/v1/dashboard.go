@@ -1,12 +1,16 @@
 package v1
 
 import (
+	"strconv"
+
 	"../../config"
 	"../../service/authService/adminService"
 	"../../service/authService/businessService"
 	"../../service/authService/driverService"
 	"../../service/authService/permission"
 	"../../service/authService/userService"
+	"../../service/heatmapService"
+	"../../util/timeHelper"
 	"../response"
 
 	"github.com/labstack/echo"
@@ -21,6 +25,7 @@ func InitDashboard(parentRoute *echo.Group) {
 	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
 
 	route.GET("/general", permission.AuthRequired(readGeneralData))
+	route.GET("/heatmap", permission.RoleRequired(readHeatmap, config.RoleAdmin))
 }
 
 // @Title readGeneralData
@@ -49,3 +54,32 @@ func readGeneralData(c echo.Context) error {
 		"businessAvailables": businessAvailables,
 	})
 }
+
+// @Title readHeatmap
+// @Description Read pickups, dropoffs and unmet demand of orders in window and online drivers as geojson geohash cells.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   placeId			form    string	false	"City of heatmap, every city by default."
+// @Param   window			form    string	false	"Window of orders: 15m, 1h, 6h, 24h or 7d, 1h by default."
+// @Param   precision		form    int		false	"Length of geohash of cells from 4 to 7, 6 by default."
+// @Success 200 {object} model.Heatmap 		"Returns geojson feature collection of cells"
+// @Failure 400 {object} response.BasicResponse "err.heatmap.read"
+// @Resource /dashboard
+// @Router /dashboard/heatmap [get]
+func readHeatmap(c echo.Context) error {
+	window := c.FormValue("window")
+	if window == "" {
+		window = config.HeatmapDefaultWindow
+	}
+	precision := config.HeatmapPrecision
+	if c.FormValue("precision") != "" {
+		precision, _ = strconv.Atoi(c.FormValue("precision"))
+	}
+
+	heatmap, err := heatmapService.ReadHeatmap(c.FormValue("placeId"), window, precision, timeHelper.GetCurrentTime())
+	if err != nil {
+		return response.KnownErrJSON(c, "err.heatmap.read", err)
+	}
+	return response.SuccessInterface(c, heatmap)
+}
@@ -11,6 +11,10 @@ import (
 	"../../service/authService/permission"
 	"../../service/driverLocationService"
 	"../../service/locationService"
+	"../../service/onboardingService"
+	"../../service/orderService"
+	"../../service/shiftService"
+	"../../util/timeHelper"
 	"../response"
 
 	"github.com/labstack/echo"
@@ -66,6 +70,7 @@ func createDriver(c echo.Context) error {
 	if err != nil {
 		return response.KnownErrJSON(c, "err.driver.create", err)
 	}
+	go refreshOnboarding(driver.ID)
 
 	publicDriver := &model.PublicDriver{Driver: driver}
 	return response.SuccessInterface(c, publicDriver)
@@ -209,20 +214,20 @@ func readDetailDriver(c echo.Context) error {
 	if err != nil {
 		fmt.Println("err.location.read - ", err)
 	}
-	// // get recent 8 trips of driver
-	// trips, err := tripService.ReadTripsHistory("driver", publicDriver.ID, 0, 8)
-	// if err != nil {
-	// 	fmt.Println("err.trips.read - ", err)
-	// }
-
-	// cancelledCount := tripService.ReadTripsCount("driver", publicDriver.ID, config.TripCancelled)
-	// completedCount := tripService.ReadTripsCount("driver", publicDriver.ID, config.TripCompleted)
+	// get recent 8 trips of driver
+	trips, err := orderService.ReadDriverTrips(objid, 8)
+	if err != nil {
+		fmt.Println("err.trips.read - ", err)
+	}
+
+	cancelledCount := orderService.CountDriverTrips(objid, config.TripCancelled)
+	completedCount := orderService.CountDriverTrips(objid, config.TripCompleted)
 	return response.SuccessInterface(c, map[string]interface{}{
-		"driver":   publicDriver,
-		"location": location.City,
-		// "recent_trips":    trips,
-		// "cancelled_count": cancelledCount,
-		// "completed_count": completedCount,
+		"driver":          publicDriver,
+		"location":        location.City,
+		"recent_trips":    trips,
+		"cancelled_count": cancelledCount,
+		"completed_count": completedCount,
 	})
 }
 
@@ -238,6 +243,14 @@ func updateDriverLocation(c echo.Context) error {
 	if err != nil {
 		return response.KnownErrJSON(c, "err.driverLocation.update", err)
 	}
+	// online driver attends booked shift
+	if driverLocation.Status == config.Online {
+		go shiftService.CheckIn(driverLocation.DriverID)
+	}
+	// driver on trip arrives and drops by geofence
+	if driverLocation.Status != config.Offline && config.GeofenceMode != config.GeofenceOff {
+		go checkGeofence(driverLocation)
+	}
 
 	return response.SuccessInterface(c, driverLocation)
 }
@@ -250,15 +263,13 @@ func createDriverVehicle(c echo.Context) error {
 	}
 	objid := bson.ObjectIdHex(c.Param("id")) // driver id
 
-	for _, document := range driverVehicle.Documents {
-		if document.Status == config.DocumentNone {
-			document.Status = config.DocumentPending
-		}
-	}
+	// uploaded documents wait for review
+	onboardingService.MergeDocuments(nil, driverVehicle.Documents, timeHelper.GetCurrentTime())
 
 	if err := driverService.CreateDriverVehicle(objid, driverVehicle); err != nil {
 		return response.KnownErrJSON(c, "err.driverVehicle.create", err)
 	}
+	go refreshOnboarding(objid)
 
 	return response.SuccessInterface(c, "Driver vehicle is created correctly.")
 }
@@ -272,15 +283,23 @@ func updateDriverVehicle(c echo.Context) error {
 	objid := bson.ObjectIdHex(c.Param("id")) //driver id
 	number := c.Param("number")
 
-	for _, document := range driverVehicle.Documents {
-		if document.Status == config.DocumentNone {
-			document.Status = config.DocumentPending
+	// review is kept for documents that are not changed
+	driver, err := driverService.ReadDriver(objid)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.driverVehicle.update", err)
+	}
+	previous := []*model.VerifyDocument{}
+	for _, vehicle := range driver.DriverVehicles {
+		if vehicle.Number == number {
+			previous = vehicle.Documents
 		}
 	}
+	onboardingService.MergeDocuments(previous, driverVehicle.Documents, timeHelper.GetCurrentTime())
 
 	if err := driverService.UpdateDriverVehicle(objid, number, driverVehicle); err != nil {
 		return response.KnownErrJSON(c, "err.driverVehicle.update", err)
 	}
+	go refreshOnboarding(objid)
 
 	return response.SuccessInterface(c, "Driver vehicle is updated correctly.")
 }
@@ -296,6 +315,7 @@ func deleteDriverVehicle(c echo.Context) error {
 	if err := driverService.DeleteDriverVehicle(objid, driverVehicle); err != nil {
 		return response.KnownErrJSON(c, "err.driverVehicle.delete", err)
 	}
+	go refreshOnboarding(objid)
 
 	return response.SuccessInterface(c, "Driver vehicle is delete correctly.")
 }
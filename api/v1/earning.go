@@ -0,0 +1,294 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/permission"
+	"../../service/earningService"
+	"../../util/timeHelper"
+	"../response"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// InitEarning inits earning apis of drivers and payout apis of admin
+// @Title Earnings
+// @Description Earnings's router group.
+func InitEarning(parentRoute *echo.Group) {
+	driverRoute := parentRoute.Group("/drivers/:id/earnings")
+	driverRoute.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	driverRoute.GET("", permission.AuthRequired(readDriverEarnings))
+	driverRoute.GET("/statements", permission.AuthRequired(readDriverEarningStatements))
+	driverRoute.GET("/payouts", permission.AuthRequired(readDriverPayouts))
+	driverRoute.POST("/adjustments", permission.RoleRequired(addEarningAdjustment, config.RoleAdmin))
+
+	route := parentRoute.Group("/earnings/payouts")
+	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	route.POST("", permission.RoleRequired(createDriverPayoutBatch, config.RoleAdmin))
+	route.GET("", permission.RoleRequired(readDriverPayoutBatches, config.RoleAdmin))
+	route.GET("/:id", permission.RoleRequired(readDriverPayoutBatch, config.RoleAdmin))
+	route.GET("/:id/export", permission.RoleRequired(exportDriverPayoutBatch, config.RoleAdmin))
+	route.POST("/:id/paid", permission.RoleRequired(payDriverPayoutBatch, config.RoleAdmin))
+
+	earningService.InitService()
+}
+
+// earningDriverID returns driver of path that client can see
+func earningDriverID(c echo.Context) (bson.ObjectId, error) {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return "", errors.New("Retreived object id is invalid")
+	}
+	driverID := bson.ObjectIdHex(c.Param("id"))
+	clientID, role := permission.InfoFromToken(c)
+	if role != config.RoleAdmin && clientID != driverID {
+		return "", errors.New("This driver is not you")
+	}
+	return driverID, nil
+}
+
+// @Title readDriverEarnings
+// @Description Read earnings of driver with breakdown of fare.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Driver ID."
+// @Param   from			form    int		false	"Start time of earnings."
+// @Param   to				form    int		false	"End time of earnings."
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns earnings"
+// @Failure 400 {object} response.BasicResponse "err.earning.bind"
+// @Failure 400 {object} response.BasicResponse "err.earning.read"
+// @Resource /drivers
+// @Router /drivers/{id}/earnings [get]
+func readDriverEarnings(c echo.Context) error {
+	driverID, err := earningDriverID(c)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.earning.bind", err)
+	}
+	from, _ := strconv.ParseInt(c.FormValue("from"), 10, 64)
+	to, _ := strconv.ParseInt(c.FormValue("to"), 10, 64)
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	earnings, total, err := earningService.ReadEarnings(driverID, from, to, offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.earning.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, earnings})
+}
+
+// @Title readDriverEarningStatements
+// @Description Read daily or weekly statements of driver.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Driver ID."
+// @Param   period			form    string	false	"day(default) or week"
+// @Param   from			form    int		false	"Start time of statements, last 4 periods by default."
+// @Param   to				form    int		false	"End time of statements."
+// @Success 200 {object} model.ListForm 		"Returns statements"
+// @Failure 400 {object} response.BasicResponse "err.earning.bind"
+// @Failure 400 {object} response.BasicResponse "err.earning.read"
+// @Resource /drivers
+// @Router /drivers/{id}/earnings/statements [get]
+func readDriverEarningStatements(c echo.Context) error {
+	driverID, err := earningDriverID(c)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.earning.bind", err)
+	}
+	period := c.FormValue("period")
+	size := int64(24 * 3600)
+	if period == config.EarningWeekly {
+		size = 7 * 24 * 3600
+	} else {
+		period = config.EarningDaily
+	}
+	from, _ := strconv.ParseInt(c.FormValue("from"), 10, 64)
+	to, _ := strconv.ParseInt(c.FormValue("to"), 10, 64)
+	if from == 0 {
+		from = earningService.PeriodStart(timeHelper.GetCurrentTime(), period) - 3*size
+	}
+
+	statements, err := earningService.ReadStatements(driverID, period, from, to)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.earning.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{len(statements), statements})
+}
+
+// @Title readDriverPayouts
+// @Description Read payouts of earnings of driver.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Driver ID."
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns payouts"
+// @Failure 400 {object} response.BasicResponse "err.earning.bind"
+// @Failure 400 {object} response.BasicResponse "err.earning.read"
+// @Resource /drivers
+// @Router /drivers/{id}/earnings/payouts [get]
+func readDriverPayouts(c echo.Context) error {
+	driverID, err := earningDriverID(c)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.earning.bind", err)
+	}
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	payouts, total, err := earningService.ReadDriverPayouts(driverID, offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.earning.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, payouts})
+}
+
+// @Title addEarningAdjustment
+// @Description Add positive or negative adjustment to earnings of driver.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Driver ID."
+// @Param   adjustment		form    int		true	"Amount of adjustment in cents."
+// @Param   orderId			form    string	false	"Order that is adjusted."
+// @Param   note			form    string	true	"Note of adjustment."
+// @Success 200 {object} model.Earning 			"Returns adjustment"
+// @Failure 400 {object} response.BasicResponse "err.earning.bind"
+// @Failure 400 {object} response.BasicResponse "err.earning.create"
+// @Resource /drivers
+// @Router /drivers/{id}/earnings/adjustments [post]
+func addEarningAdjustment(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.earning.bind", errors.New("Retreived object id is invalid"))
+	}
+	earning := &model.Earning{}
+	if err := c.Bind(earning); err != nil {
+		return response.KnownErrJSON(c, "err.earning.bind", err)
+	}
+	earning.DriverID = bson.ObjectIdHex(c.Param("id"))
+	earning.IssuerID, _ = permission.InfoFromToken(c)
+
+	earning, err := earningService.AddAdjustment(earning)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.earning.create", err)
+	}
+	return response.SuccessInterface(c, earning)
+}
+
+// @Title createDriverPayoutBatch
+// @Description Create payout batch of unpaid earnings of drivers.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   until			form    int		false	"Earnings before time are paid, start of today by default."
+// @Success 200 {object} model.DriverPayoutBatch "Returns payout batch"
+// @Failure 400 {object} response.BasicResponse "err.earning.payout"
+// @Resource /earnings
+// @Router /earnings/payouts [post]
+func createDriverPayoutBatch(c echo.Context) error {
+	until, _ := strconv.ParseInt(c.FormValue("until"), 10, 64)
+	batch, err := earningService.CreatePayoutBatch(until)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.earning.payout", err)
+	}
+	return response.SuccessInterface(c, batch)
+}
+
+// @Title readDriverPayoutBatches
+// @Description Read payout batches of drivers.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns payout batches"
+// @Failure 400 {object} response.BasicResponse "err.earning.read"
+// @Resource /earnings
+// @Router /earnings/payouts [get]
+func readDriverPayoutBatches(c echo.Context) error {
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	batches, total, err := earningService.ReadPayoutBatches(offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.earning.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, batches})
+}
+
+// @Title readDriverPayoutBatch
+// @Description Read payout batch of drivers.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Batch ID."
+// @Success 200 {object} model.DriverPayoutBatch "Returns payout batch"
+// @Failure 400 {object} response.BasicResponse "err.earning.bind"
+// @Failure 400 {object} response.BasicResponse "err.earning.read"
+// @Resource /earnings
+// @Router /earnings/payouts/{id} [get]
+func readDriverPayoutBatch(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.earning.bind", errors.New("Retreived object id is invalid"))
+	}
+	batch, err := earningService.ReadPayoutBatch(bson.ObjectIdHex(c.Param("id")))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.earning.read", err)
+	}
+	return response.SuccessInterface(c, batch)
+}
+
+// @Title exportDriverPayoutBatch
+// @Description Download csv file of payout batch of drivers.
+// @Accept  json
+// @Produce	octet-stream
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Batch ID."
+// @Success 200 {file} file 					"Returns batch file"
+// @Failure 400 {object} response.BasicResponse "err.earning.bind"
+// @Failure 400 {object} response.BasicResponse "err.earning.export"
+// @Resource /earnings
+// @Router /earnings/payouts/{id}/export [get]
+func exportDriverPayoutBatch(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.earning.bind", errors.New("Retreived object id is invalid"))
+	}
+	data, err := earningService.ExportPayoutBatch(bson.ObjectIdHex(c.Param("id")))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.earning.export", err)
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=driver-payout-"+c.Param("id")+".csv")
+	return c.Blob(http.StatusOK, "text/csv", data)
+}
+
+// @Title payDriverPayoutBatch
+// @Description Mark payout batch of drivers as paid after bank transfer.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Batch ID."
+// @Success 200 {object} model.DriverPayoutBatch "Returns payout batch"
+// @Failure 400 {object} response.BasicResponse "err.earning.bind"
+// @Failure 400 {object} response.BasicResponse "err.earning.update"
+// @Resource /earnings
+// @Router /earnings/payouts/{id}/paid [post]
+func payDriverPayoutBatch(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.earning.bind", errors.New("Retreived object id is invalid"))
+	}
+	batch, err := earningService.PayPayoutBatch(bson.ObjectIdHex(c.Param("id")))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.earning.update", err)
+	}
+	return response.SuccessInterface(c, batch)
+}
@@ -1,12 +1,14 @@
 package v1
 
 import (
+	"errors"
 	"strconv"
 
 	"../../config"
 	"../../model"
 	"../../service/authService/permission"
 	"../../service/foodService"
+	"../../service/notificationService"
 	"../response"
 
 	"github.com/labstack/echo"
@@ -28,7 +30,13 @@ func InitFood(parentRoute *echo.Group) {
 
 	route.GET("", permission.AuthRequired(readFoods))
 	route.GET("/by/business", permission.AuthRequired(readFoodsByBusiness))
-	route.GET("/require/approved", permission.AuthRequired(requireApproved))
+	route.GET("/require/approved", permission.RoleRequired(requireApproved, config.RoleAdmin))
+	route.GET("/:id/revisions", permission.RoleRequired(readFoodRevisions, config.RoleAdmin, config.RoleBusiness))
+	route.POST("/revisions/:id/approve", permission.RoleRequired(approveFoodRevision, config.RoleAdmin))
+	route.POST("/revisions/:id/reject", permission.RoleRequired(rejectFoodRevision, config.RoleAdmin))
+	route.GET("/:id/versions", permission.AuthRequired(readFoodVersions))
+	route.GET("/:id/versions/:version", permission.AuthRequired(readFoodVersion))
+	route.POST("/:id/versions/:version/rollback", permission.RoleRequired(rollbackFood, config.RoleAdmin, config.RoleBusiness))
 
 	foodService.InitService()
 }
@@ -49,11 +57,27 @@ func createFood(c echo.Context) error {
 	if err := c.Bind(food); err != nil {
 		return response.KnownErrJSON(c, "err.food.bind", err)
 	}
+	// new food of business is hidden for customers until admin approves it
+	objid, role := permission.InfoFromToken(c)
+	if role == config.RoleBusiness {
+		food.BusinessID = objid
+		food.Status = false
+	} else {
+		food.Status = true
+	}
 	// Create food
 	food, err := foodService.CreateFood(food)
 	if err != nil {
 		return response.KnownErrJSON(c, "err.food.create", err)
 	}
+	if food, err = foodService.RecordFoodVersion(food.ID, nil, objid, role, "", ""); err != nil {
+		return response.KnownErrJSON(c, "err.food.version", err)
+	}
+	if !food.Status {
+		if food.PendingRevision, err = foodService.CreateFoodRevision(food, food, config.RevisionCreate); err != nil {
+			return response.KnownErrJSON(c, "err.food.revision", err)
+		}
+	}
 	return response.SuccessInterface(c, food)
 }
 
@@ -75,6 +99,9 @@ func readFood(c echo.Context) error {
 	if err != nil {
 		return response.KnownErrJSON(c, "err.food.read", err)
 	}
+	if revision, err := foodService.ReadPendingFoodRevision(objid); err == nil {
+		food.PendingRevision = revision
+	}
 	return response.SuccessInterface(c, food)
 }
 
@@ -96,14 +123,52 @@ func updateFood(c echo.Context) error {
 		return response.KnownErrJSON(c, "err.food.bind", err)
 	}
 
+	clientID, role := permission.InfoFromToken(c)
+	if role == config.RoleBusiness {
+		return updateFoodByBusiness(c, clientID, objid, food)
+	}
+
 	// Update food
-	food, err := foodService.UpdateFood(objid, food)
+	food, err := foodService.UpdateFood(objid, food, clientID, role)
 	if err != nil {
 		return response.KnownErrJSON(c, "err.food.update", err)
 	}
 	return response.SuccessInterface(c, food)
 }
 
+// updateFoodByBusiness keeps last approved food and creates revision for menu changes
+func updateFoodByBusiness(c echo.Context, businessID bson.ObjectId, objid bson.ObjectId, food *model.Food) error {
+	current, err := foodService.ReadFood(objid)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.food.read", err)
+	}
+	if current.BusinessID != businessID {
+		return response.KnownErrJSON(c, "err.food.permission", errors.New("This food is not registered in your business"))
+	}
+
+	// sold out and enabled are applied right now
+	result := current
+	if current.SoldOut != food.SoldOut || current.Enabled != food.Enabled {
+		if result, err = foodService.UpdateFoodAvailability(objid, food, businessID, config.RoleBusiness); err != nil {
+			return response.KnownErrJSON(c, "err.food.update", err)
+		}
+	}
+	revisionType := config.RevisionUpdate
+	if !current.Status {
+		revisionType = config.RevisionCreate
+	}
+	if foodService.ContentChanged(current, food) {
+		food.ID = objid
+		food.BusinessID = businessID
+		if result.PendingRevision, err = foodService.CreateFoodRevision(current, food, revisionType); err != nil {
+			return response.KnownErrJSON(c, "err.food.revision", err)
+		}
+	} else if revision, err := foodService.ReadPendingFoodRevision(objid); err == nil {
+		result.PendingRevision = revision
+	}
+	return response.SuccessInterface(c, result)
+}
+
 // @Title deleteFood
 // @Description Delete a food.
 // @Accept  json
@@ -210,12 +275,229 @@ func readFoodsByBusiness(c echo.Context) error {
 	return response.SuccessInterface(c, &model.ListForm{total, results})
 }
 
+// @Title requireApproved
+// @Description Read menu changes that are waiting for approval.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   businessId		form   	string  false	"Business ID."
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns pending revisions"
+// @Failure 400 {object} response.BasicResponse "err.revision.read"
+// @Resource /foods
+// @Router /foods/require/approved [get]
 func requireApproved(c echo.Context) error {
-	// Read Foods without approved
-	foods, err := foodService.ReadWithoutApprove()
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+	var businessID bson.ObjectId
+	if bson.IsObjectIdHex(c.FormValue("businessId")) {
+		businessID = bson.ObjectIdHex(c.FormValue("businessId"))
+	}
+
+	// Read pending revisions
+	revisions, total, err := foodService.ReadPendingFoodRevisions(businessID, offset, count)
 	if err != nil {
-		return response.KnownErrJSON(c, "err.food.read", err)
+		return response.KnownErrJSON(c, "err.revision.read", err)
 	}
 
-	return response.SuccessInterface(c, foods)
+	return response.SuccessInterface(c, &model.ListForm{total, revisions})
+}
+
+// @Title readFoodRevisions
+// @Description Read approval history of food by admin or business of food.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Food ID."
+// @Success 200 {object} model.FoodRevision 	"Returns revisions of food"
+// @Failure 400 {object} response.BasicResponse "err.revision.read"
+// @Resource /foods
+// @Router /foods/{id}/revisions [get]
+func readFoodRevisions(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.food.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	// business reads history of its own foods
+	if clientID, role := permission.InfoFromToken(c); role == config.RoleBusiness {
+		food, err := foodService.ReadFood(objid)
+		if err != nil {
+			return response.KnownErrJSON(c, "err.food.read", err)
+		}
+		if food.BusinessID != clientID {
+			return response.KnownErrJSON(c, "err.food.permission", errors.New("This food is not registered in your business"))
+		}
+	}
+
+	revisions, err := foodService.ReadFoodRevisions(objid)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.revision.read", err)
+	}
+	return response.SuccessInterface(c, revisions)
+}
+
+// @Title approveFoodRevision
+// @Description Approve menu change of business.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Revision ID."
+// @Param   comment			form   	string  false	"Comment for business."
+// @Success 200 {object} model.FoodRevision 	"Returns approved revision"
+// @Failure 400 {object} response.BasicResponse "err.revision.approve"
+// @Resource /foods
+// @Router /foods/revisions/{id}/approve [post]
+func approveFoodRevision(c echo.Context) error {
+	return reviewFoodRevision(c, config.RevisionApproved)
+}
+
+// @Title rejectFoodRevision
+// @Description Reject menu change of business.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Revision ID."
+// @Param   comment			form   	string  true	"Reason of rejection."
+// @Success 200 {object} model.FoodRevision 	"Returns rejected revision"
+// @Failure 400 {object} response.BasicResponse "err.revision.reject"
+// @Resource /foods
+// @Router /foods/revisions/{id}/reject [post]
+func rejectFoodRevision(c echo.Context) error {
+	return reviewFoodRevision(c, config.RevisionRejected)
+}
+
+func reviewFoodRevision(c echo.Context, status string) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.revision.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	review := &model.FoodRevision{}
+	if err := c.Bind(review); err != nil {
+		return response.KnownErrJSON(c, "err.revision.bind", err)
+	}
+	reviewerID, _ := permission.InfoFromToken(c)
+
+	var revision *model.FoodRevision
+	var err error
+	if status == config.RevisionApproved {
+		revision, err = foodService.ApproveFoodRevision(objid, reviewerID, review.Comment)
+		if err != nil {
+			return response.KnownErrJSON(c, "err.revision.approve", err)
+		}
+	} else {
+		revision, err = foodService.RejectFoodRevision(objid, reviewerID, review.Comment)
+		if err != nil {
+			return response.KnownErrJSON(c, "err.revision.reject", err)
+		}
+	}
+	// notify decision to business
+	go notificationService.PushWebsocketNotification(revision.BusinessID.Hex(), M{
+		"type":       revision.Status,
+		"foodId":     revision.FoodID,
+		"revisionId": revision.ID,
+		"comment":    revision.Comment,
+	})
+
+	return response.SuccessInterface(c, revision)
+}
+
+// @Title readFoodVersions
+// @Description Read version history of food to explain past prices.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Food ID."
+// @Param   from			form    int		false	"Start of period in unix time."
+// @Param   to				form    int		false	"End of period in unix time."
+// @Success 200 {object} model.FoodVersion 		"Returns versions of food"
+// @Failure 400 {object} response.BasicResponse "err.food.version"
+// @Resource /foods
+// @Router /foods/{id}/versions [get]
+func readFoodVersions(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.food.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	from, _ := strconv.ParseInt(c.FormValue("from"), 10, 64)
+	to, _ := strconv.ParseInt(c.FormValue("to"), 10, 64)
+
+	versions, err := foodService.ReadFoodVersions(objid, from, to)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.food.version", err)
+	}
+	return response.SuccessInterface(c, versions)
+}
+
+// @Title readFoodVersion
+// @Description Read a version of food.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Food ID."
+// @Param   version			path   	int  	true	"Version number."
+// @Success 200 {object} model.FoodVersion 		"Returns version of food"
+// @Failure 400 {object} response.BasicResponse "err.food.version"
+// @Resource /foods
+// @Router /foods/{id}/versions/{version} [get]
+func readFoodVersion(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.food.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	version, _ := strconv.Atoi(c.Param("version"))
+
+	foodVersion, err := foodService.ReadFoodVersion(objid, version)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.food.version", err)
+	}
+	return response.SuccessInterface(c, foodVersion)
+}
+
+// @Title rollbackFood
+// @Description Roll back food to prior version. Rollback of business is reviewed by admin.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Food ID."
+// @Param   version			path   	int  	true	"Version number."
+// @Success 200 {object} model.Food 			"Returns food"
+// @Failure 400 {object} response.BasicResponse "err.food.rollback"
+// @Resource /foods
+// @Router /foods/{id}/versions/{version}/rollback [post]
+func rollbackFood(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.food.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	version, _ := strconv.Atoi(c.Param("version"))
+	clientID, role := permission.InfoFromToken(c)
+
+	if role != config.RoleBusiness {
+		food, err := foodService.RollbackFood(objid, version, clientID, role)
+		if err != nil {
+			return response.KnownErrJSON(c, "err.food.rollback", err)
+		}
+		return response.SuccessInterface(c, food)
+	}
+
+	// rollback of business is menu change too
+	current, err := foodService.ReadFood(objid)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.food.read", err)
+	}
+	if current.BusinessID != clientID {
+		return response.KnownErrJSON(c, "err.food.permission", errors.New("This food is not registered in your business"))
+	}
+	foodVersion, err := foodService.ReadFoodVersion(objid, version)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.food.version", err)
+	}
+	if !foodService.ContentChanged(current, foodVersion.Food) {
+		return response.KnownErrJSON(c, "err.food.rollback", errors.New("Food is same with this version"))
+	}
+	if current.PendingRevision, err = foodService.CreateFoodRevision(current, foodVersion.Food, config.RevisionUpdate); err != nil {
+		return response.KnownErrJSON(c, "err.food.revision", err)
+	}
+	return response.SuccessInterface(c, current)
 }
@@ -7,7 +7,9 @@ import (
 	"../../config"
 	"../../model"
 	"../../service/authService/permission"
+	"../../service/foodService"
 	"../../service/foodTypeService"
+	"../../util/random"
 	"../response"
 
 	"github.com/labstack/echo"
@@ -38,6 +40,11 @@ func InitFoodType(parentRoute *echo.Group) {
 
 	route.GET("/by/spec/:id", permission.AuthRequired(readSpecs))
 
+	// version history
+	route.GET("/:id/versions", permission.AuthRequired(readFoodTypeVersions))
+	route.GET("/:id/versions/:version", permission.AuthRequired(readFoodTypeVersion))
+	route.POST("/:id/versions/:version/rollback", permission.RoleRequired(rollbackFoodType, config.RoleAdmin, config.RoleBusiness))
+
 	foodTypeService.InitService()
 }
 
@@ -64,6 +71,9 @@ func createFoodType(c echo.Context) error {
 	if err != nil {
 		return response.KnownErrJSON(c, "err.foodType.create", err)
 	}
+	if foodType, err = recordFoodTypeVersion(c, foodType.ID); err != nil {
+		return response.KnownErrJSON(c, "err.foodType.version", err)
+	}
 
 	return response.SuccessInterface(c, foodType)
 }
@@ -94,7 +104,7 @@ func readFoodType(c echo.Context) error {
 }
 
 // @Title updateFoodType
-// @Description Update a foodType.
+// @Description Update a foodType. Change of business is reviewed by admin and revision is returned.
 // @Accept  json
 // @Produce	json
 // @Param   Authorization	header 	string  true	"Bearer {token}"
@@ -120,7 +130,16 @@ func updateFoodType(c echo.Context) error {
 	}
 
 	objid := bson.ObjectIdHex(c.Param("id"))
-	foodType, err := foodTypeService.UpdateFoodType(objid, foodType)
+	authorID, role := permission.InfoFromToken(c)
+	if role == config.RoleBusiness {
+		return proposeFoodType(c, authorID, objid, func(proposed *model.FoodType) error {
+			proposed.Name = foodType.Name
+			proposed.Description = foodType.Description
+			proposed.Image = foodType.Image
+			return nil
+		})
+	}
+	foodType, err := foodTypeService.UpdateFoodType(objid, foodType, authorID, role)
 	if err != nil {
 		return response.KnownErrJSON(c, "err.foodType.read", err)
 	}
@@ -207,7 +226,15 @@ func createSpec(c echo.Context) error {
 	if err := c.Bind(&spec); err != nil {
 		return response.KnownErrJSON(c, "err.foodOption.bind", err)
 	}
-	spec, err := foodTypeService.CreateSpec(objid, spec)
+	authorID, role := permission.InfoFromToken(c)
+	if role == config.RoleBusiness {
+		return proposeFoodType(c, authorID, objid, func(proposed *model.FoodType) error {
+			spec.Number = random.GenerateRandomString(6)
+			proposed.FoodOption = append(proposed.FoodOption, spec)
+			return nil
+		})
+	}
+	spec, err := foodTypeService.CreateSpec(objid, spec, authorID, role)
 	if err != nil {
 		return response.KnownErrJSON(c, "err.foodOption.create", err)
 	}
@@ -241,7 +268,21 @@ func updateSpec(c echo.Context) error {
 	if err := c.Bind(&spec); err != nil {
 		return response.KnownErrJSON(c, "err.foodOption.bind", err)
 	}
-	spec, err := foodTypeService.UpdateSpec(objid, number, spec)
+	authorID, role := permission.InfoFromToken(c)
+	if role == config.RoleBusiness {
+		return proposeFoodType(c, authorID, objid, func(proposed *model.FoodType) error {
+			for i, option := range proposed.FoodOption {
+				if option.Number == number {
+					changed := *option
+					changed.Options = spec.Options
+					proposed.FoodOption[i] = &changed
+					return nil
+				}
+			}
+			return errors.New("Spec is not existed")
+		})
+	}
+	spec, err := foodTypeService.UpdateSpec(objid, number, spec, authorID, role)
 	if err != nil {
 		return response.KnownErrJSON(c, "err.foodOption.create", err)
 	}
@@ -256,7 +297,20 @@ func deleteSpec(c echo.Context) error {
 	objid := bson.ObjectIdHex(c.Param("id"))
 	number := c.Param("number")
 
-	err := foodTypeService.DeleteSpec(objid, number)
+	authorID, role := permission.InfoFromToken(c)
+	if role == config.RoleBusiness {
+		return proposeFoodType(c, authorID, objid, func(proposed *model.FoodType) error {
+			options := []*model.FoodOption{}
+			for _, option := range proposed.FoodOption {
+				if option.Number != number {
+					options = append(options, option)
+				}
+			}
+			proposed.FoodOption = options
+			return nil
+		})
+	}
+	err := foodTypeService.DeleteSpec(objid, number, authorID, role)
 	if err != nil {
 		return response.KnownErrJSON(c, "err.foodOption.create", err)
 	}
@@ -277,3 +331,123 @@ func readSpecs(c echo.Context) error {
 	}
 	return response.SuccessInterface(c, specs)
 }
+
+// proposeFoodType creates revision with change of business on food type, change is applied when admin approves it
+// and customers see last approved food type until then
+func proposeFoodType(c echo.Context, businessID bson.ObjectId, objid bson.ObjectId, change func(*model.FoodType) error) error {
+	current, err := foodTypeService.ReadFoodType(objid)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.foodType.read", err)
+	}
+	if current.BusinessID != businessID {
+		return response.KnownErrJSON(c, "err.foodType.permission", errors.New("This foodType is not registered in your business"))
+	}
+	// changes of business are added to pending revision
+	proposed := *current
+	if revision, err := foodService.ReadPendingFoodTypeRevision(objid); err == nil && revision.ProposedFoodType != nil {
+		proposed = *revision.ProposedFoodType
+	}
+	proposed.FoodOption = append([]*model.FoodOption{}, proposed.FoodOption...)
+	if err := change(&proposed); err != nil {
+		return response.KnownErrJSON(c, "err.foodOption.bind", err)
+	}
+	revision, err := foodService.CreateFoodTypeRevision(current, &proposed)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.foodType.revision", err)
+	}
+	return response.SuccessInterface(c, revision)
+}
+
+// recordFoodTypeVersion saves created food type as first version with author of token
+func recordFoodTypeVersion(c echo.Context, objid bson.ObjectId) (*model.FoodType, error) {
+	authorID, role := permission.InfoFromToken(c)
+	return foodTypeService.RecordFoodTypeVersion(objid, nil, authorID, role, "")
+}
+
+// @Title readFoodTypeVersions
+// @Description Read version history of food type and its options.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"FoodType ID."
+// @Param   from			form    int		false	"Start of period in unix time."
+// @Param   to				form    int		false	"End of period in unix time."
+// @Success 200 {object} model.FoodTypeVersion 	"Returns versions of foodType"
+// @Failure 400 {object} response.BasicResponse "err.foodType.version"
+// @Resource /foodTypes
+// @Router /foodTypes/{id}/versions [get]
+func readFoodTypeVersions(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.foodType.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	from, _ := strconv.ParseInt(c.FormValue("from"), 10, 64)
+	to, _ := strconv.ParseInt(c.FormValue("to"), 10, 64)
+
+	versions, err := foodTypeService.ReadFoodTypeVersions(objid, from, to)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.foodType.version", err)
+	}
+	return response.SuccessInterface(c, versions)
+}
+
+// @Title readFoodTypeVersion
+// @Description Read a version of food type.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"FoodType ID."
+// @Param   version			path   	int  	true	"Version number."
+// @Success 200 {object} model.FoodTypeVersion 	"Returns version of foodType"
+// @Failure 400 {object} response.BasicResponse "err.foodType.version"
+// @Resource /foodTypes
+// @Router /foodTypes/{id}/versions/{version} [get]
+func readFoodTypeVersion(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.foodType.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	version, _ := strconv.Atoi(c.Param("version"))
+
+	foodTypeVersion, err := foodTypeService.ReadFoodTypeVersion(objid, version)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.foodType.version", err)
+	}
+	return response.SuccessInterface(c, foodTypeVersion)
+}
+
+// @Title rollbackFoodType
+// @Description Roll back food type and its options to prior version.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"FoodType ID."
+// @Param   version			path   	int  	true	"Version number."
+// @Success 200 {object} model.FoodType 		"Returns foodType"
+// @Failure 400 {object} response.BasicResponse "err.foodType.rollback"
+// @Resource /foodTypes
+// @Router /foodTypes/{id}/versions/{version}/rollback [post]
+func rollbackFoodType(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.foodType.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	version, _ := strconv.Atoi(c.Param("version"))
+	clientID, role := permission.InfoFromToken(c)
+
+	if role == config.RoleBusiness {
+		foodType, err := foodTypeService.ReadFoodType(objid)
+		if err != nil {
+			return response.KnownErrJSON(c, "err.foodType.read", err)
+		}
+		if foodType.BusinessID != clientID {
+			return response.KnownErrJSON(c, "err.foodType.permission", errors.New("This food type is not registered in your business"))
+		}
+	}
+
+	foodType, err := foodTypeService.RollbackFoodType(objid, version, clientID, role)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.foodType.rollback", err)
+	}
+	return response.SuccessInterface(c, foodType)
+}
@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/driverService"
+	"../../service/geofenceService"
+	"../../service/notificationService"
+	"../../service/orderService"
+	"../../util/log"
+	"../response"
+
+	"github.com/labstack/echo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// @Title readTripAnomalies
+// @Description Read trips that driver marked arrival or drop far from expected point.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   driverId		form    string	false	"Driver of trips."
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns orders with anomalies"
+// @Failure 400 {object} response.BasicResponse "err.anomaly.bind"
+// @Failure 400 {object} response.BasicResponse "err.anomaly.read"
+// @Resource /orders
+// @Router /orders/anomalies [get]
+func readTripAnomalies(c echo.Context) error {
+	var driverID bson.ObjectId
+	if value := c.FormValue("driverId"); value != "" {
+		if !bson.IsObjectIdHex(value) {
+			return response.KnownErrJSON(c, "err.anomaly.bind", errors.New("Retreived object id is invalid"))
+		}
+		driverID = bson.ObjectIdHex(value)
+	}
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	orders, total, err := orderService.ReadAnomalousTrips(driverID, offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.anomaly.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, orders})
+}
+
+// checkGeofence suggests or applies arrival and drop when driver dwells in geofence of trip
+func checkGeofence(driverLocation *model.DriverLocation) {
+	order, transition, err := geofenceService.ProcessLocation(driverLocation)
+	if !log.CheckErrorNoStackWithMessage(err, "Failed to check geofence of driver %s", driverLocation.DriverID.Hex()) || transition == "" {
+		return
+	}
+
+	place := "the delivery location"
+	if transition == config.TripArrived && order.Business != nil {
+		place = order.Business.Name
+	}
+	switch config.GeofenceMode {
+	case config.GeofenceAuto:
+		// driver may have marked it already
+		updated, err := orderService.TransitTrip(order.ID, order.TripStatus, transition)
+		if err != nil {
+			return
+		}
+		notifyGeofence(order, config.GeofenceApplied, transition, "You are at "+place+", order "+order.Number+" is updated.")
+		runProcessTripForDriver(updated)
+	case config.GeofenceSuggest:
+		notifyGeofence(order, config.GeofenceSuggested, transition, "You are at "+place+". Update order "+order.Number+"?")
+	}
+}
+
+// flagTripTransition flags arrival or drop that driver marked far from expected point
+func flagTripTransition(orderID bson.ObjectId, tripStatus string) {
+	if tripStatus != config.TripArrived && tripStatus != config.TripDropped {
+		return
+	}
+	anomaly, err := geofenceService.FlagTransition(orderID, tripStatus)
+	if log.CheckErrorNoStackWithMessage(err, "Failed to check transition of order %s", orderID.Hex()) && anomaly != nil {
+		log.Warnf("Order %s is marked %s %.0f meters from expected point", orderID.Hex(), tripStatus, anomaly.Distance)
+	}
+}
+
+func notifyGeofence(order *model.Order, notificationType string, tripStatus string, message string) {
+	driver, err := driverService.ReadDriver(order.DriverID)
+	if err != nil || driver.OneSignalPlayerID == "" {
+		return
+	}
+
+	notification := &model.OneSignalNotification{}
+	notification.AppID = config.DriverAppID
+	notification.PlayerIds = []string{driver.OneSignalPlayerID}
+	notification.Title = M{"en": config.DriverAppName}
+
+	data := M{}
+	data["type"] = notificationType
+	data["orderId"] = order.ID
+	data["tripStatus"] = tripStatus
+	notification.Data = data
+
+	notification.Message = M{"en": message}
+
+	notificationService.PushOneSignalNotification(notification, config.DriverAPIKey)
+}
@@ -10,6 +10,7 @@ import (
 	"../../model"
 	"../../service/authService/permission"
 	"../../service/locationService"
+	"../../service/zoneService"
 
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
@@ -21,6 +22,7 @@ import (
 // @Description Location's router group.
 func InitLocation(parentRoute *echo.Group) {
 	parentRoute.GET("/public/locations", readLocations)
+	parentRoute.GET("/public/zones/resolve", resolveZone)
 
 	route := parentRoute.Group("/locations")
 	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
@@ -35,7 +37,13 @@ func InitLocation(parentRoute *echo.Group) {
 	route.GET("/vehicles/:placeId", permission.AuthRequired(readLocationVehicles))
 	route.PUT("/update/vehicleInfo/:id", permission.AuthRequired(updateVehicleInfos))
 
+	route.GET("/:id/zones", permission.RoleRequired(readLocationZones, config.RoleAdmin))
+	route.POST("/:id/zones", permission.RoleRequired(createLocationZone, config.RoleAdmin))
+	route.PUT("/zones/:id", permission.RoleRequired(updateLocationZone, config.RoleAdmin))
+	route.DELETE("/zones/:id", permission.RoleRequired(deleteLocationZone, config.RoleAdmin))
+
 	locationService.InitService()
+	zoneService.InitService()
 }
 
 // @Title createLocation
@@ -0,0 +1,249 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/driverService"
+	"../../service/authService/permission"
+	"../../service/documentService"
+	"../../service/notificationService"
+	"../../service/onboardingService"
+	"../../util/log"
+	"../../util/timeHelper"
+	"../response"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// InitOnboarding inits onboarding api of drivers and document review apis of admin
+// @Title Onboarding
+// @Description Onboarding's router group.
+func InitOnboarding(parentRoute *echo.Group) {
+	driverRoute := parentRoute.Group("/drivers/:id/onboarding")
+	driverRoute.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	driverRoute.GET("", permission.AuthRequired(readDriverOnboarding))
+
+	route := parentRoute.Group("/onboarding")
+	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	route.GET("/documents", permission.RoleRequired(readPendingDocuments, config.RoleAdmin))
+	route.POST("/documents/:id/:number/:documentId/approve", permission.RoleRequired(approveDocument, config.RoleAdmin))
+	route.POST("/documents/:id/:number/:documentId/reject", permission.RoleRequired(rejectDocument, config.RoleAdmin))
+	route.GET("/expirations", permission.RoleRequired(readExpirationReport, config.RoleAdmin))
+
+	// onboarding of every driver is checked in background so that server starts without waiting for it
+	go onboardingService.InitService()
+	go runDocumentExpiry()
+}
+
+// @Title readDriverOnboarding
+// @Description Read onboarding status of driver with missing, pending and rejected documents of vehicles.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Driver ID."
+// @Success 200 {object} model.Onboarding 		"Returns onboarding"
+// @Failure 400 {object} response.BasicResponse "err.onboarding.bind"
+// @Failure 400 {object} response.BasicResponse "err.onboarding.read"
+// @Resource /drivers
+// @Router /drivers/{id}/onboarding [get]
+func readDriverOnboarding(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.onboarding.bind", errors.New("Retreived object id is invalid"))
+	}
+	driverID := bson.ObjectIdHex(c.Param("id"))
+	clientID, role := permission.InfoFromToken(c)
+	if role != config.RoleAdmin && clientID != driverID {
+		return response.KnownErrJSON(c, "err.onboarding.bind", errors.New("This driver is not you"))
+	}
+
+	// required documents may be changed by admin, so status is saved again
+	onboarding, _, err := onboardingService.RefreshOnboarding(driverID)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.onboarding.read", err)
+	}
+	return response.SuccessInterface(c, onboarding)
+}
+
+// @Title readPendingDocuments
+// @Description Read review queue of uploaded documents, oldest upload first.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns pending documents"
+// @Failure 400 {object} response.BasicResponse "err.onboarding.read"
+// @Resource /onboarding
+// @Router /onboarding/documents [get]
+func readPendingDocuments(c echo.Context) error {
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	documents, total, err := driverService.ReadPendingDocuments(offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.onboarding.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, documents})
+}
+
+// @Title approveDocument
+// @Description Approve pending document of driver's vehicle.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Driver ID."
+// @Param   number			path   	string  true	"Vehicle number."
+// @Param   documentId		path   	string  true	"Document ID."
+// @Success 200 {object} model.VerifyDocument 	"Returns reviewed document"
+// @Failure 400 {object} response.BasicResponse "err.onboarding.bind"
+// @Failure 400 {object} response.BasicResponse "err.onboarding.review"
+// @Resource /onboarding
+// @Router /onboarding/documents/{id}/{number}/{documentId}/approve [post]
+func approveDocument(c echo.Context) error {
+	return reviewDocument(c, true)
+}
+
+// @Title rejectDocument
+// @Description Reject pending document of driver's vehicle with reason.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Driver ID."
+// @Param   number			path   	string  true	"Vehicle number."
+// @Param   documentId		path   	string  true	"Document ID."
+// @Param   reason			form   	string  true	"Reason that is shown to driver."
+// @Success 200 {object} model.VerifyDocument 	"Returns reviewed document"
+// @Failure 400 {object} response.BasicResponse "err.onboarding.bind"
+// @Failure 400 {object} response.BasicResponse "err.onboarding.review"
+// @Resource /onboarding
+// @Router /onboarding/documents/{id}/{number}/{documentId}/reject [post]
+func rejectDocument(c echo.Context) error {
+	return reviewDocument(c, false)
+}
+
+func reviewDocument(c echo.Context, approve bool) error {
+	if !bson.IsObjectIdHex(c.Param("id")) || !bson.IsObjectIdHex(c.Param("documentId")) {
+		return response.KnownErrJSON(c, "err.onboarding.bind", errors.New("Retreived object id is invalid"))
+	}
+	driverID := bson.ObjectIdHex(c.Param("id"))
+
+	document, err := onboardingService.ReviewDocument(driverID, c.Param("number"), bson.ObjectIdHex(c.Param("documentId")), approve, c.FormValue("reason"))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.onboarding.review", err)
+	}
+	go notifyDocumentReview(driverID, document)
+	return response.SuccessInterface(c, document)
+}
+
+// @Title readExpirationReport
+// @Description Read documents of drivers that expire in coming days, grouped by city.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   days			form    int		false	"Days from now, 30 by default."
+// @Success 200 {object} model.ExpirationReport	"Returns expirations by city"
+// @Failure 400 {object} response.BasicResponse "err.onboarding.read"
+// @Resource /onboarding
+// @Router /onboarding/expirations [get]
+func readExpirationReport(c echo.Context) error {
+	days, _ := strconv.Atoi(c.FormValue("days"))
+	if days <= 0 {
+		days = 30
+	}
+	now := timeHelper.GetCurrentTime()
+
+	reports, err := driverService.ReadExpirationReport(now, now+int64(days)*24*3600)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.onboarding.read", err)
+	}
+	return response.SuccessInterface(c, reports)
+}
+
+// runDocumentExpiry scans documents of drivers in background
+func runDocumentExpiry() {
+	for {
+		checkDocumentExpiry()
+		time.Sleep(config.DocumentExpiryInterval)
+	}
+}
+
+// checkDocumentExpiry reminds drivers of expiring documents and tells drivers of expired documents
+func checkDocumentExpiry() {
+	expiries, err := onboardingService.ScanExpiry(timeHelper.GetCurrentTime())
+	if !log.CheckErrorNoStackWithMessage(err, "Failed to scan expiry of documents") {
+		return
+	}
+	for driverID, driverExpiries := range expiries {
+		for _, expiry := range driverExpiries {
+			unit := "days"
+			if expiry.Days == 1 {
+				unit = "day"
+			}
+			message := fmt.Sprintf("Your %s of %s expires in %d %s. Please upload a new one.", expiry.Name, expiry.Number, expiry.Days, unit)
+			if expiry.Days == 0 {
+				message = fmt.Sprintf("Your %s of %s is expired. You can't go online until a new one is approved.", expiry.Name, expiry.Number)
+			}
+			notifyOnboarding(driverID, message)
+		}
+	}
+}
+
+// notifyDocumentReview tells driver review result, and approval when driver can go online
+func notifyDocumentReview(driverID bson.ObjectId, document *model.VerifyDocument) {
+	name := "Your document"
+	if reviewed, err := documentService.ReadDocument(document.ID); err == nil {
+		name = "Your " + reviewed.Name
+	}
+	message := name + " is approved."
+	if document.Status == config.DocumentRejected {
+		message = name + " is rejected: " + document.Reason
+	}
+	if onboarding, changed, err := onboardingService.RefreshOnboarding(driverID); err == nil && changed && onboarding.Status == config.OnboardingApproved {
+		message = "You are approved to drive. You can go online now."
+	}
+	notifyOnboarding(driverID, message)
+}
+
+// refreshOnboarding saves onboarding status after documents of driver are changed
+func refreshOnboarding(driverID bson.ObjectId) {
+	onboarding, changed, err := onboardingService.RefreshOnboarding(driverID)
+	if err != nil || !changed {
+		return
+	}
+	switch onboarding.Status {
+	case config.OnboardingPending:
+		notifyOnboarding(driverID, "Your documents are submitted for review.")
+	case config.OnboardingIncomplete:
+		notifyOnboarding(driverID, "Please upload required documents to go online.")
+	}
+}
+
+func notifyOnboarding(driverID bson.ObjectId, message string) {
+	driver, err := driverService.ReadDriver(driverID)
+	if err != nil || driver.OneSignalPlayerID == "" {
+		return
+	}
+
+	notification := &model.OneSignalNotification{}
+	notification.AppID = config.DriverAppID
+	notification.PlayerIds = []string{driver.OneSignalPlayerID}
+	notification.Title = M{"en": config.DriverAppName}
+
+	data := M{}
+	data["type"] = config.OnboardingUpdated
+	data["status"] = driver.Onboarding
+	notification.Data = data
+
+	notification.Message = M{"en": message}
+
+	notificationService.PushOneSignalNotification(notification, config.DriverAPIKey)
+}
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -11,8 +12,14 @@ import (
 	"../../service/authService/permission"
 	"../../service/authService/userService"
 	"../../service/driverLocationService"
+	"../../service/earningService"
 	"../../service/notificationService"
 	"../../service/orderService"
+	"../../service/payoutService"
+	"../../service/ratingService"
+	"../../service/receiptService"
+	"../../service/referralService"
+	"../../service/reviewService"
 
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
@@ -29,7 +36,12 @@ func InitOrder(parentRoute *echo.Group) {
 	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
 
 	route.POST("", permission.AuthRequired(createOrder))
+	route.POST("/price", permission.AuthRequired(priceOrder))
+	route.POST("/:id/tip", permission.RoleRequired(tipOrder, config.RoleUser))
 	route.GET("/:id", permission.AuthRequired(readOrder))
+	route.GET("/:id/receipt", permission.AuthRequired(readOrderReceipt))
+	route.GET("/:id/trail", permission.AuthRequired(readOrderTrail))
+	route.GET("/anomalies", permission.RoleRequired(readTripAnomalies, config.RoleAdmin))
 	route.PUT("/:id", permission.AuthRequired(updateOrder))
 	route.DELETE("/:id", permission.AuthRequired(deleteOrder))
 
@@ -41,11 +53,13 @@ func InitOrder(parentRoute *echo.Group) {
 	route.POST("/trip/request", permission.AuthRequired(requestTripToDriver))
 	route.POST("/trip/pickup", permission.AuthRequired(submitPickupScore))
 
-	route.POST("/submit/by/user", permission.AuthRequired(submitOrderByUser))
-	route.POST("/submit/by/business", permission.AuthRequired(submitOrderByBusiness))
-	route.POST("/submit/by/driver", permission.AuthRequired(submitOrderByDriver))
+	route.POST("/submit/by/user", permission.RoleRequired(submitOrderByUser, config.RoleUser))
+	route.POST("/submit/by/business", permission.RoleRequired(submitOrderByBusiness, config.RoleBusiness))
+	route.POST("/submit/by/driver", permission.RoleRequired(submitOrderByDriver, config.RoleDriver))
+	route.GET("/norated", permission.AuthRequired(readNoRatedOrders))
 
 	orderService.InitService()
+	receiptService.InitService()
 }
 
 // @Title createOrder
@@ -54,6 +68,9 @@ func InitOrder(parentRoute *echo.Group) {
 // @Produce	json
 // @Param   Authorization	header 	string  true	"Bearer {token}"
 // @Param   name       	form   	string  true	"Order name."
+// @Param   payment     	form   	model.OrderPayment false	"Payment method of order, default payment method is used without it."
+// @Param   tipPercent     	form   	int 	false	"Preset percent of tip."
+// @Param   tip     		form   	float64 false	"Custom amount of tip."
 // @Success 200 {object} model.Order             "Returns created order"
 // @Failure 400 {object} response.BasicResponse "err.order.bind"
 // @Failure 400 {object} response.BasicResponse "err.order.create"
@@ -64,6 +81,10 @@ func createOrder(c echo.Context) error {
 	if err := c.Bind(order); err != nil {
 		return response.KnownErrJSON(c, "err.order.bind", err)
 	}
+	// user pays own order
+	if objid, role := permission.InfoFromToken(c); role == config.RoleUser {
+		order.UserID = objid
+	}
 	// Create order
 	order, err := orderService.CreateOrder(order)
 	if err != nil {
@@ -79,6 +100,78 @@ func createOrder(c echo.Context) error {
 	return response.SuccessInterface(c, order)
 }
 
+// @Title priceOrder
+// @Description Price order with promo code before order is created.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string  true	"Bearer {token}"
+// @Param   businessId     	form   	string  true	"Business ID."
+// @Param   price       	form   	float64 true	"Price of foods."
+// @Param   tax       		form   	float64 false	"Tax of order."
+// @Param   bookingFee      form   	float64 false	"Booking fee of order."
+// @Param   deliveryLocation form  	model.GeoLocation false	"Delivery location, city is resolved from its zone."
+// @Param   promoCode       form   	string  false	"Promo code."
+// @Param   tipPercent     	form   	int 	false	"Preset percent of tip."
+// @Param   tip     		form   	float64 false	"Custom amount of tip."
+// @Success 200 {object} model.PriceQuote       "Returns price lines with discount"
+// @Failure 400 {object} response.BasicResponse "err.order.bind"
+// @Failure 400 {object} response.BasicResponse "err.order.price"
+// @Resource /orders
+// @Router /orders/price [post]
+func priceOrder(c echo.Context) error {
+	order := &model.Order{}
+	if err := c.Bind(order); err != nil {
+		return response.KnownErrJSON(c, "err.order.bind", err)
+	}
+	if objid, role := permission.InfoFromToken(c); role == config.RoleUser {
+		order.UserID = objid
+	}
+	quote, err := orderService.QuoteOrder(order)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.order.price", err)
+	}
+	return response.SuccessInterface(c, quote)
+}
+
+// @Title tipOrder
+// @Description User tips driver after trip is completed.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string  true	"Bearer {token}"
+// @Param   id				path   	string  true	"Order ID."
+// @Param   tipPercent		form   	int  	false	"Preset percent of price."
+// @Param   tip				form   	float64 false	"Custom amount of tip."
+// @Success 200 {object} model.Order            "Returns tipped order"
+// @Failure 400 {object} response.BasicResponse "err.order.bind"
+// @Failure 400 {object} response.BasicResponse "err.order.tip"
+// @Resource /orders
+// @Router /orders/{id}/tip [post]
+func tipOrder(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.order.bind", errors.New("Retreived object id is invalid"))
+	}
+	form := &model.Order{}
+	if err := c.Bind(form); err != nil {
+		return response.KnownErrJSON(c, "err.order.bind", err)
+	}
+	userID, _ := permission.InfoFromToken(c)
+
+	order, err := orderService.TipOrder(bson.ObjectIdHex(c.Param("id")), userID, form.TipPercent, form.Tip)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.order.tip", err)
+	}
+	// Send tip to driver via websocket
+	data := M{
+		"type":    config.TipReceived,
+		"orderId": order.ID,
+		"tip":     order.Tip,
+	}
+	go notificationService.PushWebsocketNotification(order.DriverID.Hex(), data)
+	go earningService.RecordTip(order)
+
+	return response.SuccessInterface(c, order)
+}
+
 // @Title readOrder
 // @Description Read a order.
 // @Accept  json
@@ -229,12 +322,21 @@ func runProcessOrderForBusiness(order *model.Order) {
 		notification.Message = M{"en": "Your order is accepted."}
 	case config.OrderDeclined:
 		notification.Message = M{"en": "Your order is declined."}
+		if order.Payment != nil {
+			go notifyRefund(order, order.Payment.Refunded+order.Payment.WalletRefunded)
+		}
 	case config.OrderCancelled:
 		notification.Message = M{"en": "Your order is cancelled."}
+		if order.Payment != nil {
+			go notifyRefund(order, order.Payment.Refunded+order.Payment.WalletRefunded)
+		}
 	case config.OrderPrepared:
 		notification.Message = M{"en": "Your order is preparing."}
 	case config.OrderCompleted:
 		notification.Message = M{"en": "Your order is completed."}
+		referralService.CompleteReferral(order.UserID, order.ID)
+		payoutService.RecordOrder(order)
+		go sendReceipt(order.ID)
 		notificationService.PushWebsocketNotification(order.BusinessID.Hex(), data)
 		return
 	}
@@ -254,6 +356,7 @@ func processTripForDriver(c echo.Context) error {
 	}
 	// run process
 	go runProcessTripForDriver(order)
+	go flagTripTransition(order.ID, order.TripStatus)
 
 	return response.SuccessInterface(c, order)
 }
@@ -294,6 +397,8 @@ func runProcessTripForDriver(order *model.Order) {
 		notification.Message = M{"en": "Your order trip is dropped."}
 	case config.TripCompleted:
 		notification.Message = M{"en": "Your order trip is completed."}
+		recordTripActuals(order.ID)
+		earningService.RecordTrip(order.ID)
 	}
 
 	notificationService.PushOneSignalNotification(notification, config.UserAPIKey)
@@ -316,6 +421,10 @@ func requestTripToDriver(c echo.Context) error {
 	if err := c.Bind(order); err != nil {
 		return response.KnownErrJSON(c, "err.order.bind", err)
 	}
+	// trip is requested only to driver that is approved
+	if driver, err := driverService.ReadDriver(order.DriverID); err != nil || driver.Onboarding != config.OnboardingApproved {
+		return response.KnownErrJSON(c, "err.order.driver", errors.New("Driver is not approved to take trips"))
+	}
 
 	// Update order
 	order, err := orderService.UpdateOrderProcess(order.ID, order)
@@ -360,26 +469,157 @@ func submitPickupScore(c echo.Context) error {
 	return response.SuccessInterface(c, order)
 }
 
+// @Title submitOrderByUser
+// @Description User rates business and driver of completed order.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization		header 	string	true	"Bearer {token}"
+// @Param   id					form   	string  true	"Order ID."
+// @Param   businessRate		form   	float32 false	"Rate of business."
+// @Param   businessFeedback	form   	string  false	"Feedback about business."
+// @Param   driverRate			form   	float32 false	"Rate of driver."
+// @Param   driverFeedback		form   	string  false	"Feedback about driver."
+// @Param   userTags			form   	[]string false	"Tags of feedback."
+// @Success 200 {object} model.Order 			"Returns rated order"
+// @Failure 400 {object} response.BasicResponse "err.order.bind"
+// @Failure 400 {object} response.BasicResponse "err.order.rate"
+// @Resource /orders
+// @Router /orders/submit/by/user [post]
 func submitOrderByUser(c echo.Context) error {
 	order := &model.Order{}
 	if err := c.Bind(order); err != nil {
 		return response.KnownErrJSON(c, "err.order.bind", err)
 	}
+	if err := checkRates(order.BusinessRate, order.DriverRate); err != nil {
+		return response.KnownErrJSON(c, "err.order.rate", err)
+	}
+	order.UserID, _ = permission.InfoFromToken(c)
 	// update feedback
-	err := orderService.UpdateOrderRate(order)
+	order, err := orderService.UpdateOrderRate(order)
 	if err != nil {
-		return response.KnownErrJSON(c, "err.order.update", err)
+		return response.KnownErrJSON(c, "err.order.rate", err)
+	}
+	go ratingService.AddRating(config.RatingBusiness, order.BusinessID, order.ID, order.BusinessRate)
+	go ratingService.AddRating(config.RatingDriver, order.DriverID, order.ID, order.DriverRate)
+	// written feedback about business becomes review
+	if order.BusinessFeedback != "" {
+		go createReview(order)
 	}
 
 	return response.SuccessInterface(c, order)
 }
 
+func createReview(order *model.Order) {
+	user, _ := userService.ReadUser(order.UserID)
+	review, err := reviewService.CreateReview(order, user)
+	if err != nil || review.Status != config.ReviewPublished {
+		return
+	}
+	data := M{
+		"type":     config.ReviewPublished,
+		"reviewId": review.ID,
+	}
+	notificationService.PushWebsocketNotification(order.BusinessID.Hex(), data)
+}
+
+// @Title submitOrderByBusiness
+// @Description Business rates user and driver of completed order.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization		header 	string	true	"Bearer {token}"
+// @Param   id					form   	string  true	"Order ID."
+// @Param   businessUserRate	form   	float32 false	"Rate of user."
+// @Param   businessDriverRate	form   	float32 false	"Rate of driver."
+// @Param   businessComment		form   	string  false	"Feedback about user and driver."
+// @Param   businessTags		form   	[]string false	"Tags of feedback."
+// @Success 200 {object} model.Order 			"Returns rated order"
+// @Failure 400 {object} response.BasicResponse "err.order.bind"
+// @Failure 400 {object} response.BasicResponse "err.order.rate"
+// @Resource /orders
+// @Router /orders/submit/by/business [post]
 func submitOrderByBusiness(c echo.Context) error {
+	order := &model.Order{}
+	if err := c.Bind(order); err != nil {
+		return response.KnownErrJSON(c, "err.order.bind", err)
+	}
+	if order.BusinessUserRate <= 0 && order.BusinessDriverRate <= 0 {
+		return response.KnownErrJSON(c, "err.order.rate", errors.New("Rate of user or driver is required"))
+	}
+	if err := checkRates(order.BusinessUserRate, order.BusinessDriverRate); err != nil {
+		return response.KnownErrJSON(c, "err.order.rate", err)
+	}
+	order.BusinessID, _ = permission.InfoFromToken(c)
+	// update rate of user and driver
+	order, err := orderService.UpdateOrderRateByBusiness(order)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.order.rate", err)
+	}
+	go ratingService.AddRating(config.RatingUser, order.UserID, order.ID, order.BusinessUserRate)
+	go ratingService.AddRating(config.RatingDriver, order.DriverID, order.ID, order.BusinessDriverRate)
 
-	return nil
+	return response.SuccessInterface(c, order)
 }
 
+// @Title submitOrderByDriver
+// @Description Driver rates user of completed order.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				form   	string  true	"Order ID."
+// @Param   userRate		form   	float32 true	"Rate of user."
+// @Param   userFeedback	form   	string  false	"Feedback about user."
+// @Param   driverTags		form   	[]string false	"Tags of feedback."
+// @Success 200 {object} model.Order 			"Returns rated order"
+// @Failure 400 {object} response.BasicResponse "err.order.bind"
+// @Failure 400 {object} response.BasicResponse "err.order.rate"
+// @Resource /orders
+// @Router /orders/submit/by/driver [post]
 func submitOrderByDriver(c echo.Context) error {
+	order := &model.Order{}
+	if err := c.Bind(order); err != nil {
+		return response.KnownErrJSON(c, "err.order.bind", err)
+	}
+	if order.UserRate <= 0 {
+		return response.KnownErrJSON(c, "err.order.rate", errors.New("Rate of user is required"))
+	}
+	if err := checkRates(order.UserRate); err != nil {
+		return response.KnownErrJSON(c, "err.order.rate", err)
+	}
+	order.DriverID, _ = permission.InfoFromToken(c)
+	// update rate of user
+	order, err := orderService.UpdateOrderRateByDriver(order)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.order.rate", err)
+	}
+	go ratingService.AddRating(config.RatingUser, order.UserID, order.ID, order.UserRate)
+
+	return response.SuccessInterface(c, order)
+}
 
+// checkRates returns error when rate that is given is out of stars
+func checkRates(rates ...float32) error {
+	for _, rate := range rates {
+		if rate != 0 && (rate < config.RatingMin || rate > config.RatingMax) {
+			return errors.New("Rate must be between 1 and 5")
+		}
+	}
 	return nil
 }
+
+// @Title readNoRatedOrders
+// @Description Read orders that client can rate yet, orders are for role of client.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Success 200 {object} model.Order 			"Returns orders"
+// @Failure 400 {object} response.BasicResponse "err.order.read"
+// @Resource /orders
+// @Router /orders/norated [get]
+func readNoRatedOrders(c echo.Context) error {
+	objid, role := permission.InfoFromToken(c)
+	orders, err := orderService.NoRatedOrders(objid, role)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.order.read", err)
+	}
+	return response.SuccessInterface(c, orders)
+}
@@ -0,0 +1,167 @@
+package v1
+
+import (
+	"errors"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/permission"
+	"../../service/orderService"
+	"../../service/paymentService"
+	"../response"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// InitPayment inits payment apis
+// @Title Payments
+// @Description Payments's router group.
+func InitPayment(parentRoute *echo.Group) {
+	route := parentRoute.Group("/payments")
+	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	route.POST("/methods", permission.RoleRequired(createPaymentMethod, config.RoleUser))
+	route.GET("/methods", permission.RoleRequired(readPaymentMethods, config.RoleUser))
+	route.PUT("/methods/:id/default", permission.RoleRequired(setDefaultPaymentMethod, config.RoleUser))
+	route.DELETE("/methods/:id", permission.RoleRequired(deletePaymentMethod, config.RoleUser))
+	route.GET("/orders/:id", permission.AuthRequired(readOrderPayments))
+
+	paymentService.InitService()
+}
+
+// @Title createPaymentMethod
+// @Description Save tokenized payment method of user.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   token			form   	string  true	"Token of payment method from gateway."
+// @Param   brand			form   	string  false	"Brand of card."
+// @Param   last4			form   	string  false	"Last 4 digits of card."
+// @Param   expMonth		form   	int  	false	"Expiration month."
+// @Param   expYear			form   	int  	false	"Expiration year."
+// @Param   default			form   	bool  	false	"Payment method is default."
+// @Success 200 {object} model.PublicPaymentMethod "Returns created payment method"
+// @Failure 400 {object} response.BasicResponse "err.payment.bind"
+// @Failure 400 {object} response.BasicResponse "err.payment.create"
+// @Resource /payments
+// @Router /payments/methods [post]
+func createPaymentMethod(c echo.Context) error {
+	method := &model.PaymentMethod{}
+	if err := c.Bind(method); err != nil {
+		return response.KnownErrJSON(c, "err.payment.bind", err)
+	}
+	method.UserID, _ = permission.InfoFromToken(c)
+
+	method, err := paymentService.CreatePaymentMethod(method)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payment.create", err)
+	}
+	return response.SuccessInterface(c, &model.PublicPaymentMethod{PaymentMethod: method})
+}
+
+// @Title readPaymentMethods
+// @Description Read payment methods of user.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Success 200 {object} model.PublicPaymentMethod "Returns payment methods"
+// @Failure 400 {object} response.BasicResponse "err.payment.read"
+// @Resource /payments
+// @Router /payments/methods [get]
+func readPaymentMethods(c echo.Context) error {
+	userID, _ := permission.InfoFromToken(c)
+
+	methods, err := paymentService.ReadPaymentMethods(userID)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payment.read", err)
+	}
+	publicMethods := []*model.PublicPaymentMethod{}
+	for _, method := range methods {
+		publicMethods = append(publicMethods, &model.PublicPaymentMethod{PaymentMethod: method})
+	}
+	return response.SuccessInterface(c, publicMethods)
+}
+
+// @Title setDefaultPaymentMethod
+// @Description Set default payment method of user.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Payment method ID."
+// @Success 200 {object} model.PublicPaymentMethod "Returns default payment method"
+// @Failure 400 {object} response.BasicResponse "err.payment.bind"
+// @Failure 400 {object} response.BasicResponse "err.payment.update"
+// @Resource /payments
+// @Router /payments/methods/{id}/default [put]
+func setDefaultPaymentMethod(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.payment.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	userID, _ := permission.InfoFromToken(c)
+
+	method, err := paymentService.SetDefaultPaymentMethod(objid, userID)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payment.update", err)
+	}
+	return response.SuccessInterface(c, &model.PublicPaymentMethod{PaymentMethod: method})
+}
+
+// @Title deletePaymentMethod
+// @Description Delete payment method of user.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Payment method ID."
+// @Success 200 {object} response.BasicResponse "Payment method is deleted"
+// @Failure 400 {object} response.BasicResponse "err.payment.bind"
+// @Failure 400 {object} response.BasicResponse "err.payment.delete"
+// @Resource /payments
+// @Router /payments/methods/{id} [delete]
+func deletePaymentMethod(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.payment.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	userID, _ := permission.InfoFromToken(c)
+
+	if err := paymentService.DeletePaymentMethod(objid, userID); err != nil {
+		return response.KnownErrJSON(c, "err.payment.delete", err)
+	}
+	return response.SuccessJSON(c, "Payment method is deleted.")
+}
+
+// @Title readOrderPayments
+// @Description Read payment intents of order.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Order ID."
+// @Success 200 {object} model.PaymentIntent 	"Returns payment intents"
+// @Failure 400 {object} response.BasicResponse "err.payment.bind"
+// @Failure 400 {object} response.BasicResponse "err.payment.read"
+// @Resource /payments
+// @Router /payments/orders/{id} [get]
+func readOrderPayments(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.payment.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	order, err := orderService.ReadOrder(objid)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payment.read", err)
+	}
+	// only parties of order can see payments
+	clientID, role := permission.InfoFromToken(c)
+	if role != config.RoleAdmin && clientID != order.UserID && clientID != order.BusinessID {
+		return response.KnownErrJSON(c, "err.payment.read", errors.New("This order is not yours"))
+	}
+
+	intents, err := paymentService.ReadOrderPaymentIntents(objid)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payment.read", err)
+	}
+	return response.SuccessInterface(c, intents)
+}
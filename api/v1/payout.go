@@ -0,0 +1,370 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/permission"
+	"../../service/payoutService"
+	"../response"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// InitPayout inits payout apis of businesses and settlement apis of admin
+// @Title Payouts
+// @Description Payouts's router group.
+func InitPayout(parentRoute *echo.Group) {
+	businessRoute := parentRoute.Group("/businesses/:id/payouts")
+	businessRoute.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	businessRoute.GET("", permission.AuthRequired(readBusinessPayouts))
+	businessRoute.GET("/pending", permission.AuthRequired(readBusinessPendingLines))
+	businessRoute.GET("/:statementId", permission.AuthRequired(readBusinessPayout))
+	businessRoute.GET("/:statementId/lines", permission.AuthRequired(readBusinessPayoutLines))
+
+	route := parentRoute.Group("/payouts")
+	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	route.GET("/commissions", permission.RoleRequired(readCommissions, config.RoleAdmin))
+	route.POST("/commissions", permission.RoleRequired(setCommission, config.RoleAdmin))
+	route.DELETE("/commissions/:id", permission.RoleRequired(deleteCommission, config.RoleAdmin))
+	route.POST("/statements", permission.RoleRequired(generatePayoutStatements, config.RoleAdmin))
+	route.GET("/batches", permission.RoleRequired(readPayoutBatches, config.RoleAdmin))
+	route.GET("/batches/:id/statements", permission.RoleRequired(readPayoutBatchStatements, config.RoleAdmin))
+	route.GET("/batches/:id/export", permission.RoleRequired(exportPayoutBatch, config.RoleAdmin))
+	route.POST("/batches/:id/paid", permission.RoleRequired(payPayoutBatch, config.RoleAdmin))
+
+	payoutService.InitService()
+}
+
+// payoutBusinessID returns business of path that client can see
+func payoutBusinessID(c echo.Context) (bson.ObjectId, error) {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return "", errors.New("Retreived object id is invalid")
+	}
+	businessID := bson.ObjectIdHex(c.Param("id"))
+	clientID, role := permission.InfoFromToken(c)
+	if role != config.RoleAdmin && clientID != businessID {
+		return "", errors.New("This business is not yours")
+	}
+	return businessID, nil
+}
+
+// @Title readBusinessPayouts
+// @Description Read weekly payout statements of business.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Business ID."
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns payout statements"
+// @Failure 400 {object} response.BasicResponse "err.payout.bind"
+// @Failure 400 {object} response.BasicResponse "err.payout.read"
+// @Resource /businesses
+// @Router /businesses/{id}/payouts [get]
+func readBusinessPayouts(c echo.Context) error {
+	businessID, err := payoutBusinessID(c)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payout.bind", err)
+	}
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	statements, total, err := payoutService.ReadStatements(businessID, offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payout.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, statements})
+}
+
+// @Title readBusinessPendingLines
+// @Description Read settlement lines of business that are not in statement yet.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Business ID."
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns settlement lines"
+// @Failure 400 {object} response.BasicResponse "err.payout.bind"
+// @Failure 400 {object} response.BasicResponse "err.payout.read"
+// @Resource /businesses
+// @Router /businesses/{id}/payouts/pending [get]
+func readBusinessPendingLines(c echo.Context) error {
+	businessID, err := payoutBusinessID(c)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payout.bind", err)
+	}
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	lines, total, err := payoutService.ReadLines(businessID, "", offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payout.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, lines})
+}
+
+// @Title readBusinessPayout
+// @Description Read payout statement of business.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Business ID."
+// @Param   statementId		path   	string  true	"Statement ID."
+// @Success 200 {object} model.PayoutStatement 	"Returns payout statement"
+// @Failure 400 {object} response.BasicResponse "err.payout.bind"
+// @Failure 400 {object} response.BasicResponse "err.payout.read"
+// @Resource /businesses
+// @Router /businesses/{id}/payouts/{statementId} [get]
+func readBusinessPayout(c echo.Context) error {
+	statement, err := businessStatement(c)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payout.read", err)
+	}
+	return response.SuccessInterface(c, statement)
+}
+
+// @Title readBusinessPayoutLines
+// @Description Read settlement lines of payout statement.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Business ID."
+// @Param   statementId		path   	string  true	"Statement ID."
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns settlement lines"
+// @Failure 400 {object} response.BasicResponse "err.payout.bind"
+// @Failure 400 {object} response.BasicResponse "err.payout.read"
+// @Resource /businesses
+// @Router /businesses/{id}/payouts/{statementId}/lines [get]
+func readBusinessPayoutLines(c echo.Context) error {
+	statement, err := businessStatement(c)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payout.read", err)
+	}
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	lines, total, err := payoutService.ReadLines(statement.BusinessID, statement.ID, offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payout.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, lines})
+}
+
+// businessStatement reads statement of path that belongs to business of path
+func businessStatement(c echo.Context) (*model.PayoutStatement, error) {
+	businessID, err := payoutBusinessID(c)
+	if err != nil {
+		return nil, err
+	}
+	if !bson.IsObjectIdHex(c.Param("statementId")) {
+		return nil, errors.New("Retreived object id is invalid")
+	}
+	statement, err := payoutService.ReadStatement(bson.ObjectIdHex(c.Param("statementId")))
+	if err != nil {
+		return nil, err
+	}
+	if statement.BusinessID != businessID {
+		return nil, errors.New("This statement is not of business")
+	}
+	return statement, nil
+}
+
+// @Title readCommissions
+// @Description Read commission rules of businesses and cities.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns commissions"
+// @Failure 400 {object} response.BasicResponse "err.commission.read"
+// @Resource /payouts
+// @Router /payouts/commissions [get]
+func readCommissions(c echo.Context) error {
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	commissions, total, err := payoutService.ReadCommissions(offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.commission.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, commissions})
+}
+
+// @Title setCommission
+// @Description Set commission of business or city.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   businessId		form   	string  false	"Business ID."
+// @Param   placeId			form   	string  false	"Place ID of city."
+// @Param   percent			form   	float64 true	"Commission percent of food price."
+// @Success 200 {object} model.Commission 		"Returns commission"
+// @Failure 400 {object} response.BasicResponse "err.commission.bind"
+// @Failure 400 {object} response.BasicResponse "err.commission.update"
+// @Resource /payouts
+// @Router /payouts/commissions [post]
+func setCommission(c echo.Context) error {
+	commission := &model.Commission{}
+	if err := c.Bind(commission); err != nil {
+		return response.KnownErrJSON(c, "err.commission.bind", err)
+	}
+	commission, err := payoutService.SetCommission(commission)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.commission.update", err)
+	}
+	return response.SuccessInterface(c, commission)
+}
+
+// @Title deleteCommission
+// @Description Delete commission rule, default commission is used.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Commission ID."
+// @Success 200 {object} response.BasicResponse "Commission is deleted"
+// @Failure 400 {object} response.BasicResponse "err.commission.bind"
+// @Failure 400 {object} response.BasicResponse "err.commission.delete"
+// @Resource /payouts
+// @Router /payouts/commissions/{id} [delete]
+func deleteCommission(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.commission.bind", errors.New("Retreived object id is invalid"))
+	}
+	if err := payoutService.DeleteCommission(bson.ObjectIdHex(c.Param("id"))); err != nil {
+		return response.KnownErrJSON(c, "err.commission.delete", err)
+	}
+	return response.SuccessJSON(c, "Commission is deleted")
+}
+
+// @Title generatePayoutStatements
+// @Description Generate statements of finished week and batch of payouts.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   periodStart		form   	int  	true	"Time in week of payouts."
+// @Success 200 {object} model.PayoutBatch 		"Returns payout batch"
+// @Failure 400 {object} response.BasicResponse "err.payout.bind"
+// @Failure 400 {object} response.BasicResponse "err.payout.create"
+// @Resource /payouts
+// @Router /payouts/statements [post]
+func generatePayoutStatements(c echo.Context) error {
+	periodStart, err := strconv.ParseInt(c.FormValue("periodStart"), 10, 64)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payout.bind", err)
+	}
+	batch, err := payoutService.GenerateStatements(periodStart)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payout.create", err)
+	}
+	return response.SuccessInterface(c, batch)
+}
+
+// @Title readPayoutBatches
+// @Description Read payout batches.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns payout batches"
+// @Failure 400 {object} response.BasicResponse "err.payout.read"
+// @Resource /payouts
+// @Router /payouts/batches [get]
+func readPayoutBatches(c echo.Context) error {
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	batches, total, err := payoutService.ReadBatches(offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payout.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, batches})
+}
+
+// @Title readPayoutBatchStatements
+// @Description Read statements of payout batch.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Batch ID."
+// @Success 200 {object} model.ListForm 		"Returns payout statements"
+// @Failure 400 {object} response.BasicResponse "err.payout.bind"
+// @Failure 400 {object} response.BasicResponse "err.payout.read"
+// @Resource /payouts
+// @Router /payouts/batches/{id}/statements [get]
+func readPayoutBatchStatements(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.payout.bind", errors.New("Retreived object id is invalid"))
+	}
+	statements, err := payoutService.ReadBatchStatements(bson.ObjectIdHex(c.Param("id")))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payout.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{len(statements), statements})
+}
+
+// @Title exportPayoutBatch
+// @Description Download payout batch file for bank.
+// @Accept  json
+// @Produce	octet-stream
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Batch ID."
+// @Param   format			form   	string  false	"csv(default) or nacha"
+// @Success 200 {file} file 					"Returns batch file"
+// @Failure 400 {object} response.BasicResponse "err.payout.bind"
+// @Failure 400 {object} response.BasicResponse "err.payout.export"
+// @Resource /payouts
+// @Router /payouts/batches/{id}/export [get]
+func exportPayoutBatch(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.payout.bind", errors.New("Retreived object id is invalid"))
+	}
+	format := c.FormValue("format")
+	if format == "" {
+		format = config.PayoutFormatCSV
+	}
+	data, err := payoutService.ExportBatch(bson.ObjectIdHex(c.Param("id")), format)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payout.export", err)
+	}
+	contentType, name := "text/csv", "payout-"+c.Param("id")+".csv"
+	if format == config.PayoutFormatNACHA {
+		contentType, name = "text/plain", "payout-"+c.Param("id")+".ach"
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+name)
+	return c.Blob(http.StatusOK, contentType, data)
+}
+
+// @Title payPayoutBatch
+// @Description Mark payout batch as paid after bank transfer.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Batch ID."
+// @Success 200 {object} model.PayoutBatch 		"Returns payout batch"
+// @Failure 400 {object} response.BasicResponse "err.payout.bind"
+// @Failure 400 {object} response.BasicResponse "err.payout.update"
+// @Resource /payouts
+// @Router /payouts/batches/{id}/paid [post]
+func payPayoutBatch(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.payout.bind", errors.New("Retreived object id is invalid"))
+	}
+	batch, err := payoutService.PayBatch(bson.ObjectIdHex(c.Param("id")))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.payout.update", err)
+	}
+	return response.SuccessInterface(c, batch)
+}
@@ -0,0 +1,179 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/permission"
+	"../../service/promotionService"
+	"../response"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// InitPromotion inits promotion CRUD apis
+// @Title Promotions
+// @Description Promotions's router group.
+func InitPromotion(parentRoute *echo.Group) {
+	route := parentRoute.Group("/promotions")
+	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	route.POST("", permission.RoleRequired(createPromotion, config.RoleAdmin))
+	route.GET("/:id", permission.RoleRequired(readPromotion, config.RoleAdmin))
+	route.PUT("/:id", permission.RoleRequired(updatePromotion, config.RoleAdmin))
+	route.DELETE("/:id", permission.RoleRequired(deletePromotion, config.RoleAdmin))
+	route.GET("/:id/redemptions", permission.RoleRequired(readPromotionRedemptions, config.RoleAdmin))
+
+	route.GET("", permission.RoleRequired(readPromotions, config.RoleAdmin))
+
+	promotionService.InitService()
+}
+
+// @Title createPromotion
+// @Description Create a promotion.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string  true	"Bearer {token}"
+// @Param   code       		form   	string  true	"Promo code."
+// @Param   type       		form   	string  true	"PromotionPercent, PromotionFixed, PromotionFreeDelivery"
+// @Param   value       	form   	float64 false	"Percent or amount of discount."
+// @Success 200 {object} model.Promotion        "Returns created promotion"
+// @Failure 400 {object} response.BasicResponse "err.promotion.bind"
+// @Failure 400 {object} response.BasicResponse "err.promotion.create"
+// @Resource /promotions
+// @Router /promotions [post]
+func createPromotion(c echo.Context) error {
+	promotion := &model.Promotion{}
+	if err := c.Bind(promotion); err != nil {
+		return response.KnownErrJSON(c, "err.promotion.bind", err)
+	}
+	promotion, err := promotionService.CreatePromotion(promotion)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.promotion.create", err)
+	}
+	return response.SuccessInterface(c, promotion)
+}
+
+// @Title readPromotion
+// @Description Read a promotion.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Promotion ID."
+// @Success 200 {object} model.Promotion 		"Returns read promotion"
+// @Failure 400 {object} response.BasicResponse "err.promotion.bind"
+// @Failure 400 {object} response.BasicResponse "err.promotion.read"
+// @Resource /promotions
+// @Router /promotions/{id} [get]
+func readPromotion(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.promotion.bind", errors.New("Retreived object id is invalid"))
+	}
+	promotion, err := promotionService.ReadPromotion(bson.ObjectIdHex(c.Param("id")))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.promotion.read", err)
+	}
+	return response.SuccessInterface(c, promotion)
+}
+
+// @Title updatePromotion
+// @Description Update promotion, code and redeemed count are not changed.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Promotion ID."
+// @Success 200 {object} model.Promotion 		"Returns updated promotion"
+// @Failure 400 {object} response.BasicResponse "err.promotion.bind"
+// @Failure 400 {object} response.BasicResponse "err.promotion.update"
+// @Resource /promotions
+// @Router /promotions/{id} [put]
+func updatePromotion(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.promotion.bind", errors.New("Retreived object id is invalid"))
+	}
+	promotion := &model.Promotion{}
+	if err := c.Bind(promotion); err != nil {
+		return response.KnownErrJSON(c, "err.promotion.bind", err)
+	}
+	promotion, err := promotionService.UpdatePromotion(bson.ObjectIdHex(c.Param("id")), promotion)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.promotion.update", err)
+	}
+	return response.SuccessInterface(c, promotion)
+}
+
+// @Title deletePromotion
+// @Description Delete a promotion.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Promotion ID."
+// @Success 200 {object} response.BasicResponse "Promotion is deleted"
+// @Failure 400 {object} response.BasicResponse "err.promotion.bind"
+// @Failure 400 {object} response.BasicResponse "err.promotion.delete"
+// @Resource /promotions
+// @Router /promotions/{id} [delete]
+func deletePromotion(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.promotion.bind", errors.New("Retreived object id is invalid"))
+	}
+	if err := promotionService.DeletePromotion(bson.ObjectIdHex(c.Param("id"))); err != nil {
+		return response.KnownErrJSON(c, "err.promotion.delete", err)
+	}
+	return response.SuccessJSON(c, "Promotion is deleted.")
+}
+
+// @Title readPromotions
+// @Description Read promotions.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   query			form    string	false	"Will search code and name."
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns promotions"
+// @Failure 400 {object} response.BasicResponse "err.promotion.read"
+// @Resource /promotions
+// @Router /promotions [get]
+func readPromotions(c echo.Context) error {
+	query := c.FormValue("query")
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	promotions, total, err := promotionService.ReadPromotions(query, offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.promotion.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, promotions})
+}
+
+// @Title readPromotionRedemptions
+// @Description Read redemptions of promotion.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Promotion ID."
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns redemptions"
+// @Failure 400 {object} response.BasicResponse "err.promotion.bind"
+// @Failure 400 {object} response.BasicResponse "err.promotion.read"
+// @Resource /promotions
+// @Router /promotions/{id}/redemptions [get]
+func readPromotionRedemptions(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.promotion.bind", errors.New("Retreived object id is invalid"))
+	}
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	redemptions, total, err := promotionService.ReadRedemptions(bson.ObjectIdHex(c.Param("id")), offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.promotion.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, redemptions})
+}
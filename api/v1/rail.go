@@ -0,0 +1,149 @@
+package v1
+
+import (
+	"errors"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/permission"
+	"../../service/railService"
+	"../response"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// InitRail inits home screen rail CRUD apis
+// @Title Rails
+// @Description Rails's router group.
+func InitRail(parentRoute *echo.Group) {
+	route := parentRoute.Group("/rails")
+	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	route.POST("", permission.RoleRequired(createRail, config.RoleAdmin))
+	route.GET("/:id", permission.AuthRequired(readRail))
+	route.PUT("/:id", permission.RoleRequired(updateRail, config.RoleAdmin))
+	route.DELETE("/:id", permission.RoleRequired(deleteRail, config.RoleAdmin))
+
+	route.GET("", permission.AuthRequired(readRails))
+
+	railService.InitService()
+}
+
+// @Title createRail
+// @Description Create a home screen rail.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string  true	"Bearer {token}"
+// @Param   title			form   	string  true	"Rail title."
+// @Param   query			form   	object  true	"Discovery filters and sort of rail."
+// @Success 200 {object} model.Rail 			"Returns created rail"
+// @Failure 400 {object} response.BasicResponse "err.rail.bind"
+// @Failure 400 {object} response.BasicResponse "err.rail.create"
+// @Resource /rails
+// @Router /rails [post]
+func createRail(c echo.Context) error {
+	rail := &model.Rail{}
+	if err := c.Bind(rail); err != nil {
+		return response.KnownErrJSON(c, "err.rail.bind", err)
+	}
+
+	rail, err := railService.CreateRail(rail)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.rail.create", err)
+	}
+	return response.SuccessInterface(c, rail)
+}
+
+// @Title readRail
+// @Description Read a home screen rail.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Rail ID."
+// @Success 200 {object} model.Rail 			"Returns read rail"
+// @Failure 400 {object} response.BasicResponse "err.rail.bind"
+// @Failure 400 {object} response.BasicResponse "err.rail.read"
+// @Resource /rails
+// @Router /rails/{id} [get]
+func readRail(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.rail.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+
+	rail, err := railService.ReadRail(objid)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.rail.read", err)
+	}
+	return response.SuccessInterface(c, rail)
+}
+
+// @Title updateRail
+// @Description Update a home screen rail.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Rail ID."
+// @Success 200 {object} model.Rail 			"Returns updated rail"
+// @Failure 400 {object} response.BasicResponse "err.rail.bind"
+// @Failure 400 {object} response.BasicResponse "err.rail.update"
+// @Resource /rails
+// @Router /rails/{id} [put]
+func updateRail(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.rail.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	rail := &model.Rail{}
+	if err := c.Bind(rail); err != nil {
+		return response.KnownErrJSON(c, "err.rail.bind", err)
+	}
+
+	rail, err := railService.UpdateRail(objid, rail)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.rail.update", err)
+	}
+	return response.SuccessInterface(c, rail)
+}
+
+// @Title deleteRail
+// @Description Delete a home screen rail.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Rail ID."
+// @Success 200 {object} response.BasicResponse "Rail is deleted correctly."
+// @Failure 400 {object} response.BasicResponse "err.rail.bind"
+// @Failure 400 {object} response.BasicResponse "err.rail.delete"
+// @Resource /rails
+// @Router /rails/{id} [delete]
+func deleteRail(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.rail.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+
+	if err := railService.DeleteRail(objid); err != nil {
+		return response.KnownErrJSON(c, "err.rail.delete", err)
+	}
+	return response.SuccessJSON(c, "Rail is deleted correctly.")
+}
+
+// @Title readRails
+// @Description Read home screen rails in order of position.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Success 200 {object} model.Rail 			"Returns rails"
+// @Failure 400 {object} response.BasicResponse "err.rail.read"
+// @Resource /rails
+// @Router /rails [get]
+func readRails(c echo.Context) error {
+	rails, err := railService.ReadRails(false)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.rail.read", err)
+	}
+	return response.SuccessInterface(c, rails)
+}
@@ -0,0 +1,123 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/permission"
+	"../../service/ratingService"
+	"../response"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// InitRating inits rating aggregation apis
+// @Title Ratings
+// @Description Ratings's router group.
+func InitRating(parentRoute *echo.Group) {
+	route := parentRoute.Group("/ratings")
+	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	route.GET("/:type", permission.RoleRequired(readRatingSummaries, config.RoleAdmin))
+	route.GET("/:type/distribution", permission.RoleRequired(readRatingDistribution, config.RoleAdmin))
+	route.POST("/:type/rebuild", permission.RoleRequired(rebuildRatings, config.RoleAdmin))
+	route.GET("/:type/:id", permission.AuthRequired(readRatingSummary))
+
+	ratingService.InitService()
+}
+
+// @Title readRatingSummary
+// @Description Read rating summary of business, driver or user.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   type			path   	string  true	"business, driver, user"
+// @Param   id				path   	string  true	"Business, driver or user ID."
+// @Success 200 {object} model.RatingSummary 	"Returns rating summary"
+// @Failure 400 {object} response.BasicResponse "err.rating.bind"
+// @Failure 400 {object} response.BasicResponse "err.rating.read"
+// @Resource /ratings
+// @Router /ratings/{type}/{id} [get]
+func readRatingSummary(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.rating.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+
+	summary, err := ratingService.ReadRatingSummary(objid)
+	if err != nil || summary.SubjectType != c.Param("type") {
+		return response.KnownErrJSON(c, "err.rating.read", errors.New("Rating is not existed"))
+	}
+	return response.SuccessInterface(c, summary)
+}
+
+// @Title readRatingSummaries
+// @Description Read rating summaries of type in order of average.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   type			path   	string  true	"business, driver, user"
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Param   sort			form    int		false	"Sort direction of average. -1:Descending(default), 1:Ascending"
+// @Success 200 {object} model.ListForm 		"Returns rating summaries"
+// @Failure 400 {object} response.BasicResponse "err.rating.read"
+// @Resource /ratings
+// @Router /ratings/{type} [get]
+func readRatingSummaries(c echo.Context) error {
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+	sort, _ := strconv.Atoi(c.FormValue("sort"))
+
+	summaries, total, err := ratingService.ReadRatingSummaries(c.Param("type"), offset, count, sort)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.rating.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, summaries})
+}
+
+// @Title readRatingDistribution
+// @Description Read count of each star for all subjects of type.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   type			path   	string  true	"business, driver, user"
+// @Success 200 {object} response.BasicResponse "Returns count of each star"
+// @Failure 400 {object} response.BasicResponse "err.rating.read"
+// @Resource /ratings
+// @Router /ratings/{type}/distribution [get]
+func readRatingDistribution(c echo.Context) error {
+	distribution, err := ratingService.ReadRatingDistribution(c.Param("type"))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.rating.read", err)
+	}
+	return response.SuccessInterface(c, distribution)
+}
+
+// @Title rebuildRatings
+// @Description Rebuild rating summaries from order history.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   type			path   	string  true	"business, driver, user"
+// @Param   id				form   	string  false	"Rebuild only this subject."
+// @Success 200 {object} response.BasicResponse "Returns count of rebuilt subjects"
+// @Failure 400 {object} response.BasicResponse "err.rating.rebuild"
+// @Resource /ratings
+// @Router /ratings/{type}/rebuild [post]
+func rebuildRatings(c echo.Context) error {
+	var subjectID bson.ObjectId
+	if bson.IsObjectIdHex(c.FormValue("id")) {
+		subjectID = bson.ObjectIdHex(c.FormValue("id"))
+	}
+
+	count, err := ratingService.RebuildRatings(c.Param("type"), subjectID)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.rating.rebuild", err)
+	}
+	return response.SuccessInterface(c, M{"count": count})
+}
@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"../../config"
+	"../../service/authService/permission"
+	"../../service/orderService"
+	"../../service/receiptService"
+	"../../util/email"
+	"../../util/log"
+	"../response"
+
+	"github.com/labstack/echo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// @Title readOrderReceipt
+// @Description Read or download receipt of completed order.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Order ID."
+// @Param   format			form   	string  false	"json(default), html or pdf"
+// @Success 200 {object} model.Receipt 			"Returns receipt"
+// @Failure 400 {object} response.BasicResponse "err.receipt.bind"
+// @Failure 400 {object} response.BasicResponse "err.receipt.read"
+// @Resource /orders
+// @Router /orders/{id}/receipt [get]
+func readOrderReceipt(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.receipt.bind", errors.New("Retreived object id is invalid"))
+	}
+	order, err := orderService.ReadOrder(bson.ObjectIdHex(c.Param("id")))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.receipt.read", err)
+	}
+	// only parties of order can see receipt
+	clientID, role := permission.InfoFromToken(c)
+	if role != config.RoleAdmin && clientID != order.UserID && clientID != order.BusinessID {
+		return response.KnownErrJSON(c, "err.receipt.read", errors.New("This order is not yours"))
+	}
+	receipt, err := receiptService.ReadReceipt(order)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.receipt.read", err)
+	}
+
+	switch c.FormValue("format") {
+	case config.ReceiptFormatHTML:
+		data, err := receiptService.RenderHTML(receipt)
+		if err != nil {
+			return response.KnownErrJSON(c, "err.receipt.read", err)
+		}
+		return c.HTMLBlob(http.StatusOK, data)
+	case config.ReceiptFormatPDF:
+		c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=receipt-"+receipt.Number+".pdf")
+		return c.Blob(http.StatusOK, "application/pdf", receiptService.RenderPDF(receipt))
+	}
+	return response.SuccessInterface(c, receipt)
+}
+
+// sendReceipt emails receipt of completed order to user once, sending is tried again when it fails
+func sendReceipt(orderID bson.ObjectId) {
+	order, err := orderService.ReadOrder(orderID)
+	if err != nil {
+		return
+	}
+	receipt, err := receiptService.ReadReceipt(order)
+	if !log.CheckErrorNoStackWithMessage(err, "Failed to issue receipt of order %s", orderID.Hex()) {
+		return
+	}
+	if receipt.CustomerEmail == "" || !receiptService.MarkEmailed(orderID) {
+		return
+	}
+	html, err := receiptService.RenderHTML(receipt)
+	if !log.CheckErrorNoStackWithMessage(err, "Failed to render receipt of order %s", orderID.Hex()) {
+		return
+	}
+	pdf := receiptService.RenderPDF(receipt)
+	for attempt := 1; ; attempt++ {
+		err = email.SendReceiptEmail(receipt.CustomerEmail, receipt.CustomerName, "Your GoGo receipt for order "+receipt.Number,
+			string(html), pdf, "receipt-"+receipt.Number+".pdf")
+		if err == nil {
+			return
+		}
+		if attempt >= config.ReceiptEmailAttempts {
+			break
+		}
+		time.Sleep(config.ReceiptEmailRetryDelay)
+	}
+	// receipt can be emailed again when sending failed
+	log.CheckErrorNoStackWithMessage(err, "Failed to email receipt of order %s", orderID.Hex())
+	receiptService.UnmarkEmailed(orderID)
+}
@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"strconv"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/permission"
+	"../../service/authService/userService"
+	"../../service/referralService"
+	"../response"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+// InitReferral inits referral apis
+// @Title Referrals
+// @Description Referrals's router group.
+func InitReferral(parentRoute *echo.Group) {
+	route := parentRoute.Group("/referrals")
+	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	route.GET("/me", permission.RoleRequired(readMyReferrals, config.RoleUser))
+	route.GET("/report", permission.RoleRequired(readReferralReport, config.RoleAdmin))
+	route.GET("", permission.RoleRequired(readReferrals, config.RoleAdmin))
+
+	referralService.InitService()
+}
+
+// @Title readMyReferrals
+// @Description Read referral code and referrals of user.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Success 200 {object} response.BasicResponse "Returns code and referrals"
+// @Failure 400 {object} response.BasicResponse "err.referral.read"
+// @Resource /referrals
+// @Router /referrals/me [get]
+func readMyReferrals(c echo.Context) error {
+	userID, _ := permission.InfoFromToken(c)
+	user, err := userService.ReadUser(userID)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.referral.read", err)
+	}
+	referrals, err := referralService.ReadUserReferrals(userID)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.referral.read", err)
+	}
+	return response.SuccessInterface(c, M{
+		"code":           user.PromoCode,
+		"referrerReward": config.ReferrerReward,
+		"refereeReward":  config.RefereeReward,
+		"referrals":      referrals,
+	})
+}
+
+// @Title readReferrals
+// @Description Read referrals with status.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   status			form    string	false	"ReferralPending, ReferralRewarded, ReferralRejected"
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns referrals"
+// @Failure 400 {object} response.BasicResponse "err.referral.read"
+// @Resource /referrals
+// @Router /referrals [get]
+func readReferrals(c echo.Context) error {
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	referrals, total, err := referralService.ReadReferrals(c.FormValue("status"), offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.referral.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, referrals})
+}
+
+// @Title readReferralReport
+// @Description Read referral results of referrers in order of rewarded referrals.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   from			form    int		false	"Start time of referrals."
+// @Param   to				form    int		false	"End time of referrals."
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns referral reports"
+// @Failure 400 {object} response.BasicResponse "err.referral.read"
+// @Resource /referrals
+// @Router /referrals/report [get]
+func readReferralReport(c echo.Context) error {
+	from, _ := strconv.ParseInt(c.FormValue("from"), 10, 64)
+	to, _ := strconv.ParseInt(c.FormValue("to"), 10, 64)
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	reports, total, err := referralService.ReadReferralReport(from, to, offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.referral.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, reports})
+}
@@ -0,0 +1,157 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/permission"
+	"../../service/authService/userService"
+	"../../service/notificationService"
+	"../../service/orderService"
+	"../../service/paymentService"
+	"../../service/payoutService"
+	"../response"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// InitRefund inits refund apis
+// @Title Refunds
+// @Description Refunds's router group.
+func InitRefund(parentRoute *echo.Group) {
+	route := parentRoute.Group("/refunds")
+	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	route.POST("", permission.RoleRequired(issueRefund, config.RoleAdmin))
+	route.GET("", permission.RoleRequired(readRefunds, config.RoleAdmin))
+	route.GET("/orders/:id", permission.AuthRequired(readOrderRefunds))
+}
+
+// @Title issueRefund
+// @Description Refund missing items or amount of order to card or wallet.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   orderId			form   	string  true	"Order ID."
+// @Param   items			form   	[]model.RefundItem false	"Refunded foods of order."
+// @Param   amount			form   	int  	false	"Refunded amount in cents without items."
+// @Param   method			form   	string  false	"RefundGateway(default) or RefundWallet for store credit."
+// @Param   note			form   	string  true	"Note of refund."
+// @Success 200 {object} model.Refund 			"Returns refund"
+// @Failure 400 {object} response.BasicResponse "err.refund.bind"
+// @Failure 400 {object} response.BasicResponse "err.refund.create"
+// @Resource /refunds
+// @Router /refunds [post]
+func issueRefund(c echo.Context) error {
+	refund := &model.Refund{}
+	if err := c.Bind(refund); err != nil {
+		return response.KnownErrJSON(c, "err.refund.bind", err)
+	}
+	if refund.Note == "" {
+		return response.KnownErrJSON(c, "err.refund.bind", errors.New("Note of refund is empty"))
+	}
+	order, err := orderService.ReadOrder(refund.OrderID)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.refund.create", err)
+	}
+	refund.IssuerID, refund.IssuerRole = permission.InfoFromToken(c)
+
+	payment, refund, err := paymentService.IssueRefund(order, refund)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.refund.create", err)
+	}
+	if err := orderService.UpdateOrderPayment(order.ID, payment); err != nil {
+		return response.KnownErrJSON(c, "err.refund.create", err)
+	}
+	go notifyRefund(order, refund.Amount)
+	go payoutService.RecordRefund(order, refund)
+
+	return response.SuccessInterface(c, refund)
+}
+
+// @Title readRefunds
+// @Description Read refund ledger for review.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   orderId			form   	string  false	"Order ID."
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns refunds"
+// @Failure 400 {object} response.BasicResponse "err.refund.read"
+// @Resource /refunds
+// @Router /refunds [get]
+func readRefunds(c echo.Context) error {
+	var orderID bson.ObjectId
+	if bson.IsObjectIdHex(c.FormValue("orderId")) {
+		orderID = bson.ObjectIdHex(c.FormValue("orderId"))
+	}
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	refunds, total, err := paymentService.ReadRefunds(orderID, offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.refund.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, refunds})
+}
+
+// @Title readOrderRefunds
+// @Description Read refund ledger of order.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Order ID."
+// @Success 200 {object} model.ListForm 		"Returns refunds of order"
+// @Failure 400 {object} response.BasicResponse "err.refund.bind"
+// @Failure 400 {object} response.BasicResponse "err.refund.read"
+// @Resource /refunds
+// @Router /refunds/orders/{id} [get]
+func readOrderRefunds(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.refund.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	order, err := orderService.ReadOrder(objid)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.refund.read", err)
+	}
+	// only parties of order can see refunds
+	clientID, role := permission.InfoFromToken(c)
+	if role != config.RoleAdmin && clientID != order.UserID && clientID != order.BusinessID {
+		return response.KnownErrJSON(c, "err.refund.read", errors.New("This order is not yours"))
+	}
+
+	refunds, total, err := paymentService.ReadRefunds(objid, 0, 0)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.refund.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, refunds})
+}
+
+// notifyRefund notifies user that amount in cents is refunded
+func notifyRefund(order *model.Order, amount int64) {
+	if amount <= 0 {
+		return
+	}
+	user, err := userService.ReadUser(order.UserID)
+	if err != nil {
+		return
+	}
+	notification := &model.OneSignalNotification{}
+	notification.AppID = config.UserAppID
+	notification.PlayerIds = []string{user.OneSignalPlayerID}
+	notification.Title = M{"en": config.UserAppName}
+	notification.Message = M{"en": fmt.Sprintf("$%.2f of your order is refunded.", float64(amount)/100)}
+	notification.Data = M{
+		"type":    config.RefundSucceeded,
+		"orderId": order.ID,
+		"amount":  amount,
+	}
+	notificationService.PushOneSignalNotification(notification, config.UserAPIKey)
+}
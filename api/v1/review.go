@@ -0,0 +1,225 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/permission"
+	"../../service/notificationService"
+	"../../service/reviewService"
+	"../response"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// InitReview inits review CRUD apis
+// @Title Reviews
+// @Description Reviews's router group.
+func InitReview(parentRoute *echo.Group) {
+	// public reviews don't need token
+	parentRoute.GET("/public/businesses/:id/reviews", readBusinessReviews)
+
+	route := parentRoute.Group("/reviews")
+	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	route.GET("/moderation", permission.RoleRequired(readModerationQueue, config.RoleAdmin))
+	route.PUT("/:id", permission.RoleRequired(updateReview, config.RoleUser))
+	route.POST("/:id/reply", permission.RoleRequired(replyReview, config.RoleBusiness))
+	route.POST("/:id/flag", permission.AuthRequired(flagReview))
+	route.POST("/:id/approve", permission.RoleRequired(approveReview, config.RoleAdmin))
+	route.POST("/:id/reject", permission.RoleRequired(rejectReview, config.RoleAdmin))
+
+	reviewService.InitService()
+}
+
+// @Title readBusinessReviews
+// @Description Read published reviews of business.
+// @Accept  json
+// @Produce	json
+// @Param   id				path   	string  true	"Business ID."
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns public reviews"
+// @Failure 400 {object} response.BasicResponse "err.review.bind"
+// @Failure 400 {object} response.BasicResponse "err.review.read"
+// @Resource /public
+// @Router /public/businesses/{id}/reviews [get]
+func readBusinessReviews(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.review.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	reviews, total, err := reviewService.ReadBusinessReviews(objid, offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.review.read", err)
+	}
+	publicReviews := []*model.PublicReview{}
+	for _, review := range reviews {
+		publicReviews = append(publicReviews, &model.PublicReview{Review: review})
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, publicReviews})
+}
+
+// @Title updateReview
+// @Description Customer edits content of review in grace period.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Review ID."
+// @Param   content			form   	string  true	"Content of review."
+// @Success 200 {object} model.Review 			"Returns updated review"
+// @Failure 400 {object} response.BasicResponse "err.review.bind"
+// @Failure 400 {object} response.BasicResponse "err.review.update"
+// @Resource /reviews
+// @Router /reviews/{id} [put]
+func updateReview(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.review.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	userID, _ := permission.InfoFromToken(c)
+
+	review, err := reviewService.UpdateReviewContent(objid, userID, c.FormValue("content"))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.review.update", err)
+	}
+	return response.SuccessInterface(c, review)
+}
+
+// @Title replyReview
+// @Description Business replies to review.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Review ID."
+// @Param   content			form   	string  true	"Content of reply."
+// @Success 200 {object} model.Review 			"Returns replied review"
+// @Failure 400 {object} response.BasicResponse "err.review.bind"
+// @Failure 400 {object} response.BasicResponse "err.review.reply"
+// @Resource /reviews
+// @Router /reviews/{id}/reply [post]
+func replyReview(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.review.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	businessID, _ := permission.InfoFromToken(c)
+
+	review, err := reviewService.ReplyReview(objid, businessID, c.FormValue("content"))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.review.reply", err)
+	}
+	return response.SuccessInterface(c, review)
+}
+
+// @Title flagReview
+// @Description Report review. Review is held for moderation when it is flagged several times.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Review ID."
+// @Param   reason			form   	string  false	"Reason of report."
+// @Success 200 {object} response.BasicResponse "Review is flagged"
+// @Failure 400 {object} response.BasicResponse "err.review.bind"
+// @Failure 400 {object} response.BasicResponse "err.review.flag"
+// @Resource /reviews
+// @Router /reviews/{id}/flag [post]
+func flagReview(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.review.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	clientID, role := permission.InfoFromToken(c)
+
+	flag := &model.ReviewFlag{
+		ClientID: clientID,
+		Role:     role,
+		Reason:   c.FormValue("reason"),
+	}
+	if _, err := reviewService.FlagReview(objid, flag); err != nil {
+		return response.KnownErrJSON(c, "err.review.flag", err)
+	}
+	return response.SuccessJSON(c, "Review is flagged.")
+}
+
+// @Title readModerationQueue
+// @Description Read reviews that are waiting for moderation.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns pending reviews"
+// @Failure 400 {object} response.BasicResponse "err.review.read"
+// @Resource /reviews
+// @Router /reviews/moderation [get]
+func readModerationQueue(c echo.Context) error {
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	reviews, total, err := reviewService.ReadModerationQueue(offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.review.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, reviews})
+}
+
+// @Title approveReview
+// @Description Publish pending review.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Review ID."
+// @Param   comment			form   	string  false	"Comment of moderator."
+// @Success 200 {object} model.Review 			"Returns published review"
+// @Failure 400 {object} response.BasicResponse "err.review.bind"
+// @Failure 400 {object} response.BasicResponse "err.review.moderate"
+// @Resource /reviews
+// @Router /reviews/{id}/approve [post]
+func approveReview(c echo.Context) error {
+	return moderateReview(c, config.ReviewPublished)
+}
+
+// @Title rejectReview
+// @Description Reject pending review.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Review ID."
+// @Param   comment			form   	string  false	"Comment of moderator."
+// @Success 200 {object} model.Review 			"Returns rejected review"
+// @Failure 400 {object} response.BasicResponse "err.review.bind"
+// @Failure 400 {object} response.BasicResponse "err.review.moderate"
+// @Resource /reviews
+// @Router /reviews/{id}/reject [post]
+func rejectReview(c echo.Context) error {
+	return moderateReview(c, config.ReviewRejected)
+}
+
+func moderateReview(c echo.Context, status string) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.review.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	moderatorID, _ := permission.InfoFromToken(c)
+
+	review, err := reviewService.ModerateReview(objid, moderatorID, status, c.FormValue("comment"))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.review.moderate", err)
+	}
+	// Send result of moderation to customer via websocket
+	data := M{
+		"type":     status,
+		"reviewId": review.ID,
+	}
+	go notificationService.PushWebsocketNotification(review.UserID.Hex(), data)
+
+	return response.SuccessInterface(c, review)
+}
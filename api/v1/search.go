@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"../../config"
+	"../../model"
+	"../../service/authService/businessService"
+	"../../service/authService/permission"
+	"../../service/searchService"
+	"../response"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+// InitSearch inits search apis
+// @Title Search
+// @Description Search's router group.
+func InitSearch(parentRoute *echo.Group) {
+	route := parentRoute.Group("/search")
+	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	route.POST("", permission.AuthRequired(search))
+	route.GET("/suggest", permission.AuthRequired(suggest))
+
+	searchService.InitService()
+}
+
+// @Title search
+// @Description Search nearby restaurants and dishes with text.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   query			form   	string  true	"Will search string."
+// @Param   lat				form   	float64 true	"Latitude of customer."
+// @Param   lng				form   	float64 true	"Longitude of customer."
+// @Param   placeId			form   	string  false	"Place id of location when coordinates are out of zones, radius of location is used."
+// @Param   price			form   	[]int   false	"Price levels."
+// @Param   dietary			form   	[]int   false	"Dietary codes."
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns ranked businesses with matched foods"
+// @Failure 400 {object} response.BasicResponse "err.search.bind"
+// @Failure 400 {object} response.BasicResponse "err.search.read"
+// @Resource /search
+// @Router /search [post]
+func search(c echo.Context) error {
+	queryBusiness := &model.QueryBusiness{}
+	if err := c.Bind(queryBusiness); err != nil {
+		return response.KnownErrJSON(c, "err.search.bind", err)
+	}
+
+	businesses, total, err := searchService.SearchBusinesses(queryBusiness, discoveryRadius(queryBusiness.PlaceID))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.search.read", err)
+	}
+	for _, b := range businesses {
+		businessService.RetrieveBusinessBaseStructure(b)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, businesses})
+}
+
+// @Title suggest
+// @Description Read autocomplete suggestions of search.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   query			form   	string  true	"Typed string."
+// @Success 200 {object} model.Suggestion 		"Returns suggestions"
+// @Failure 400 {object} response.BasicResponse "err.search.suggest"
+// @Resource /search
+// @Router /search/suggest [get]
+func suggest(c echo.Context) error {
+	query := c.FormValue("query")
+	if query == "" {
+		return response.SuccessInterface(c, []*model.Suggestion{})
+	}
+	suggestions, err := searchService.ReadSuggestions(query)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.search.suggest", err)
+	}
+	return response.SuccessInterface(c, suggestions)
+}
@@ -0,0 +1,291 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/driverService"
+	"../../service/authService/permission"
+	"../../service/notificationService"
+	"../../service/shiftService"
+	"../../util/log"
+	"../../util/timeHelper"
+	"../response"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// InitShift inits shift apis of drivers and operations
+// @Title Shifts
+// @Description Shifts's router group.
+func InitShift(parentRoute *echo.Group) {
+	route := parentRoute.Group("/shifts")
+	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	route.GET("/slots", permission.AuthRequired(readShiftSlots))
+	route.POST("/slots", permission.RoleRequired(createShiftSlot, config.RoleAdmin))
+	route.PUT("/slots/:id", permission.RoleRequired(updateShiftSlot, config.RoleAdmin))
+	route.DELETE("/slots/:id", permission.RoleRequired(deleteShiftSlot, config.RoleAdmin))
+	route.POST("/slots/:id/book", permission.RoleRequired(bookShift, config.RoleDriver))
+	route.POST("/bookings/:id/cancel", permission.RoleRequired(cancelShiftBooking, config.RoleDriver))
+	route.GET("/coverage", permission.RoleRequired(readShiftCoverage, config.RoleAdmin))
+
+	driverRoute := parentRoute.Group("/drivers/:id/shifts")
+	driverRoute.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	driverRoute.GET("", permission.AuthRequired(readDriverShifts))
+
+	shiftService.InitService()
+	go runShiftNoShow()
+}
+
+// @Title readShiftSlots
+// @Description Read published shifts of city.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   placeId			form    string	false	"City of shifts."
+// @Param   from			form    int		false	"Start time of period, now by default."
+// @Param   to				form    int		false	"End time of period."
+// @Param   available		form    bool	false	"Only shifts that are not full."
+// @Success 200 {object} model.ShiftSlot 		"Returns shift slots"
+// @Failure 400 {object} response.BasicResponse "err.shift.read"
+// @Resource /shifts
+// @Router /shifts/slots [get]
+func readShiftSlots(c echo.Context) error {
+	from, _ := strconv.ParseInt(c.FormValue("from"), 10, 64)
+	to, _ := strconv.ParseInt(c.FormValue("to"), 10, 64)
+	available, _ := strconv.ParseBool(c.FormValue("available"))
+	if from == 0 {
+		from = timeHelper.GetCurrentTime()
+	}
+
+	slots, err := shiftService.ReadSlots(c.FormValue("placeId"), from, to, available)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.shift.read", err)
+	}
+	return response.SuccessInterface(c, slots)
+}
+
+// @Title createShiftSlot
+// @Description Publish shift in zone of city with capacity.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   slot			body   	model.ShiftSlot	true	"Shift slot."
+// @Success 200 {object} model.ShiftSlot 		"Returns shift slot"
+// @Failure 400 {object} response.BasicResponse "err.shift.bind"
+// @Failure 400 {object} response.BasicResponse "err.shift.create"
+// @Resource /shifts
+// @Router /shifts/slots [post]
+func createShiftSlot(c echo.Context) error {
+	slot := &model.ShiftSlot{}
+	if err := c.Bind(slot); err != nil {
+		return response.KnownErrJSON(c, "err.shift.bind", err)
+	}
+
+	slot, err := shiftService.CreateSlot(slot)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.shift.create", err)
+	}
+	return response.SuccessInterface(c, slot)
+}
+
+// @Title updateShiftSlot
+// @Description Update shift, time and zone can't be changed after drivers booked.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Shift slot ID."
+// @Param   slot			body   	model.ShiftSlot	true	"Shift slot."
+// @Success 200 {object} model.ShiftSlot 		"Returns shift slot"
+// @Failure 400 {object} response.BasicResponse "err.shift.bind"
+// @Failure 400 {object} response.BasicResponse "err.shift.update"
+// @Resource /shifts
+// @Router /shifts/slots/{id} [put]
+func updateShiftSlot(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.shift.bind", errors.New("Retreived object id is invalid"))
+	}
+	slot := &model.ShiftSlot{}
+	if err := c.Bind(slot); err != nil {
+		return response.KnownErrJSON(c, "err.shift.bind", err)
+	}
+
+	slot, err := shiftService.UpdateSlot(bson.ObjectIdHex(c.Param("id")), slot)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.shift.update", err)
+	}
+	return response.SuccessInterface(c, slot)
+}
+
+// @Title deleteShiftSlot
+// @Description Delete shift that no driver booked.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Shift slot ID."
+// @Success 200 {object} response.BasicResponse "Shift is deleted"
+// @Failure 400 {object} response.BasicResponse "err.shift.bind"
+// @Failure 400 {object} response.BasicResponse "err.shift.delete"
+// @Resource /shifts
+// @Router /shifts/slots/{id} [delete]
+func deleteShiftSlot(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.shift.bind", errors.New("Retreived object id is invalid"))
+	}
+	if err := shiftService.DeleteSlot(bson.ObjectIdHex(c.Param("id"))); err != nil {
+		return response.KnownErrJSON(c, "err.shift.delete", err)
+	}
+	return response.SuccessInterface(c, "Shift is deleted correctly.")
+}
+
+// @Title bookShift
+// @Description Book shift for driver of token.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Shift slot ID."
+// @Success 200 {object} model.ShiftBooking 	"Returns booking"
+// @Failure 400 {object} response.BasicResponse "err.shift.bind"
+// @Failure 400 {object} response.BasicResponse "err.shift.book"
+// @Resource /shifts
+// @Router /shifts/slots/{id}/book [post]
+func bookShift(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.shift.bind", errors.New("Retreived object id is invalid"))
+	}
+	driverID, _ := permission.InfoFromToken(c)
+
+	booking, err := shiftService.BookShift(bson.ObjectIdHex(c.Param("id")), driverID)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.shift.book", err)
+	}
+	return response.SuccessInterface(c, booking)
+}
+
+// @Title cancelShiftBooking
+// @Description Cancel booking of driver of token before shift starts.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Booking ID."
+// @Success 200 {object} model.ShiftBooking 	"Returns cancelled booking"
+// @Failure 400 {object} response.BasicResponse "err.shift.bind"
+// @Failure 400 {object} response.BasicResponse "err.shift.cancel"
+// @Resource /shifts
+// @Router /shifts/bookings/{id}/cancel [post]
+func cancelShiftBooking(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.shift.bind", errors.New("Retreived object id is invalid"))
+	}
+	driverID, _ := permission.InfoFromToken(c)
+
+	booking, err := shiftService.CancelBooking(bson.ObjectIdHex(c.Param("id")), driverID)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.shift.cancel", err)
+	}
+	return response.SuccessInterface(c, booking)
+}
+
+// @Title readDriverShifts
+// @Description Read booked, attended, cancelled and missed shifts of driver.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Driver ID."
+// @Param   status			form    string	false	"booked, attended, cancelled or noShow"
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns bookings"
+// @Failure 400 {object} response.BasicResponse "err.shift.bind"
+// @Failure 400 {object} response.BasicResponse "err.shift.read"
+// @Resource /drivers
+// @Router /drivers/{id}/shifts [get]
+func readDriverShifts(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.shift.bind", errors.New("Retreived object id is invalid"))
+	}
+	driverID := bson.ObjectIdHex(c.Param("id"))
+	clientID, role := permission.InfoFromToken(c)
+	if role != config.RoleAdmin && clientID != driverID {
+		return response.KnownErrJSON(c, "err.shift.bind", errors.New("This driver is not you"))
+	}
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	bookings, total, err := shiftService.ReadBookings(driverID, c.FormValue("status"), offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.shift.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, bookings})
+}
+
+// @Title readShiftCoverage
+// @Description Read booked and attended drivers of shifts against forecasted demand.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   placeId			form    string	false	"City of shifts."
+// @Param   from			form    int		false	"Start time of period, now by default."
+// @Param   to				form    int		false	"End time of period, a week after start by default."
+// @Success 200 {object} model.ShiftCoverage 	"Returns coverage of shifts"
+// @Failure 400 {object} response.BasicResponse "err.shift.read"
+// @Resource /shifts
+// @Router /shifts/coverage [get]
+func readShiftCoverage(c echo.Context) error {
+	from, _ := strconv.ParseInt(c.FormValue("from"), 10, 64)
+	to, _ := strconv.ParseInt(c.FormValue("to"), 10, 64)
+	if from == 0 {
+		from = timeHelper.GetCurrentTime()
+	}
+	if to == 0 {
+		to = from + 7*24*3600
+	}
+
+	coverages, err := shiftService.ReadCoverage(c.FormValue("placeId"), from, to)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.shift.read", err)
+	}
+	return response.SuccessInterface(c, coverages)
+}
+
+// runShiftNoShow marks missed shifts in background
+func runShiftNoShow() {
+	for {
+		bookings, err := shiftService.MarkNoShows()
+		if log.CheckErrorNoStackWithMessage(err, "Failed to mark no-shows of shifts") {
+			for _, booking := range bookings {
+				notifyShift(booking, "You missed your shift in "+booking.Zone+".")
+			}
+		}
+		time.Sleep(config.ShiftCheckInterval)
+	}
+}
+
+func notifyShift(booking *model.ShiftBooking, message string) {
+	driver, err := driverService.ReadDriver(booking.DriverID)
+	if err != nil || driver.OneSignalPlayerID == "" {
+		return
+	}
+
+	notification := &model.OneSignalNotification{}
+	notification.AppID = config.DriverAppID
+	notification.PlayerIds = []string{driver.OneSignalPlayerID}
+	notification.Title = M{"en": config.DriverAppName}
+
+	data := M{}
+	data["type"] = config.ShiftUpdated
+	data["bookingId"] = booking.ID
+	data["status"] = booking.Status
+	notification.Data = data
+
+	notification.Message = M{"en": message}
+
+	notificationService.PushOneSignalNotification(notification, config.DriverAPIKey)
+}
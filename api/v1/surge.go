@@ -0,0 +1,205 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/permission"
+	"../../service/driverLocationService"
+	"../../service/orderService"
+	"../../service/surgeService"
+	"../../service/zoneService"
+	"../../util/log"
+	"../../util/timeHelper"
+	"../response"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// InitSurge inits surge apis of operations
+// @Title Surge
+// @Description Surge's router group.
+func InitSurge(parentRoute *echo.Group) {
+	route := parentRoute.Group("/surge")
+	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	route.GET("/zones", permission.RoleRequired(readSurges, config.RoleAdmin))
+	route.PUT("/zones/:id/override", permission.RoleRequired(overrideSurge, config.RoleAdmin))
+	route.DELETE("/zones/:id/override", permission.RoleRequired(clearSurgeOverride, config.RoleAdmin))
+	route.GET("/zones/:id/history", permission.RoleRequired(readSurgeHistory, config.RoleAdmin))
+	route.GET("/settings", permission.RoleRequired(readSurgeSetting, config.RoleAdmin))
+	route.PUT("/settings", permission.RoleRequired(updateSurgeSetting, config.RoleAdmin))
+
+	surgeService.InitService()
+	go runSurge()
+}
+
+// @Title readSurges
+// @Description Read current demand, supply and surge of zones.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   placeId			form    string	false	"City of zones, every city by default."
+// @Success 200 {object} model.Surge 			"Returns surges of zones"
+// @Failure 400 {object} response.BasicResponse "err.surge.read"
+// @Resource /surge
+// @Router /surge/zones [get]
+func readSurges(c echo.Context) error {
+	surges, err := surgeService.ReadSurges(c.FormValue("placeId"))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.surge.read", err)
+	}
+	return response.SuccessInterface(c, surges)
+}
+
+// @Title overrideSurge
+// @Description Set multiplier of zone manually until time.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Zone ID."
+// @Param   override		body   	model.SurgeOverride	true	"Multiplier and end of override."
+// @Success 200 {object} model.Surge 			"Returns surge of zone"
+// @Failure 400 {object} response.BasicResponse "err.surge.bind"
+// @Failure 400 {object} response.BasicResponse "err.surge.update"
+// @Resource /surge
+// @Router /surge/zones/{id}/override [put]
+func overrideSurge(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.surge.bind", errors.New("Retreived object id is invalid"))
+	}
+	override := &model.SurgeOverride{}
+	if err := c.Bind(override); err != nil {
+		return response.KnownErrJSON(c, "err.surge.bind", err)
+	}
+	if override.Multiplier < 1 || override.Multiplier > config.SurgeCap {
+		return response.KnownErrJSON(c, "err.surge.bind", errors.New("Multiplier must be between 1 and cap of surge"))
+	}
+	if override.Until != 0 && override.Until <= timeHelper.GetCurrentTime() {
+		return response.KnownErrJSON(c, "err.surge.bind", errors.New("End of override is passed"))
+	}
+	zone, err := zoneService.ReadZone(bson.ObjectIdHex(c.Param("id")))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.surge.bind", err)
+	}
+
+	surge, err := surgeService.SetOverride(zone.ID, override.Multiplier, override.Until)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.surge.update", err)
+	}
+	return response.SuccessInterface(c, surge)
+}
+
+// @Title clearSurgeOverride
+// @Description Return zone to computed surge.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Zone ID."
+// @Success 200 {object} response.BasicResponse "Override is cleared"
+// @Failure 400 {object} response.BasicResponse "err.surge.bind"
+// @Failure 400 {object} response.BasicResponse "err.surge.update"
+// @Resource /surge
+// @Router /surge/zones/{id}/override [delete]
+func clearSurgeOverride(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.surge.bind", errors.New("Retreived object id is invalid"))
+	}
+	if err := surgeService.ClearOverride(bson.ObjectIdHex(c.Param("id"))); err != nil {
+		return response.KnownErrJSON(c, "err.surge.update", err)
+	}
+	return response.SuccessInterface(c, "Override is cleared correctly.")
+}
+
+// @Title readSurgeHistory
+// @Description Read surge levels of zone in period for analysis.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Zone ID."
+// @Param   from			form    int		false	"Start time of history, last day by default."
+// @Param   to				form    int		false	"End time of history."
+// @Success 200 {object} model.SurgeHistory 	"Returns history of zone"
+// @Failure 400 {object} response.BasicResponse "err.surge.bind"
+// @Failure 400 {object} response.BasicResponse "err.surge.read"
+// @Resource /surge
+// @Router /surge/zones/{id}/history [get]
+func readSurgeHistory(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.surge.bind", errors.New("Retreived object id is invalid"))
+	}
+	from, _ := strconv.ParseInt(c.FormValue("from"), 10, 64)
+	to, _ := strconv.ParseInt(c.FormValue("to"), 10, 64)
+	if from == 0 {
+		from = timeHelper.FewDurationLater(-24 * time.Hour).Unix()
+	}
+
+	histories, err := surgeService.ReadHistory(bson.ObjectIdHex(c.Param("id")), from, to)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.surge.read", err)
+	}
+	return response.SuccessInterface(c, histories)
+}
+
+// @Title readSurgeSetting
+// @Description Read whether surge is charged.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Success 200 {object} model.SurgeSetting 	"Returns setting of surge"
+// @Resource /surge
+// @Router /surge/settings [get]
+func readSurgeSetting(c echo.Context) error {
+	return response.SuccessInterface(c, surgeService.ReadSetting())
+}
+
+// @Title updateSurgeSetting
+// @Description Turn surge of every zone on or off, quotes that are locked are still charged.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   setting			body   	model.SurgeSetting	true	"Setting of surge."
+// @Success 200 {object} model.SurgeSetting 	"Returns setting of surge"
+// @Failure 400 {object} response.BasicResponse "err.surge.bind"
+// @Failure 400 {object} response.BasicResponse "err.surge.update"
+// @Resource /surge
+// @Router /surge/settings [put]
+func updateSurgeSetting(c echo.Context) error {
+	setting := &model.SurgeSetting{}
+	if err := c.Bind(setting); err != nil {
+		return response.KnownErrJSON(c, "err.surge.bind", err)
+	}
+	adminID, _ := permission.InfoFromToken(c)
+
+	setting, err := surgeService.UpdateSetting(setting.Enabled, adminID)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.surge.update", err)
+	}
+	return response.SuccessInterface(c, setting)
+}
+
+// runSurge computes surge of service zones with open orders and online drivers in them
+func runSurge() {
+	for {
+		zones, err := zoneService.ReadActiveZones(config.ZoneService)
+		demands, demandErr := orderService.CountOpenOrdersByZone()
+		if log.CheckErrorNoStackWithMessage(err, "Failed to read zones of surge") &&
+			log.CheckErrorNoStackWithMessage(demandErr, "Failed to count open orders of zones") {
+			enabled := surgeService.ReadSetting().Enabled
+			for _, zone := range zones {
+				supply, err := driverLocationService.CountOnlineInArea(&zone.Area, timeHelper.GetCurrentTime()-config.SurgeLocationMaxAge)
+				if !log.CheckErrorNoStackWithMessage(err, "Failed to count drivers in zone %s", zone.ID.Hex()) {
+					continue
+				}
+				_, err = surgeService.UpdateSurge(zone, demands[zone.ID], supply, enabled)
+				log.CheckErrorNoStackWithMessage(err, "Failed to update surge of zone %s", zone.ID.Hex())
+			}
+		}
+		time.Sleep(config.SurgeInterval)
+	}
+}
@@ -0,0 +1,213 @@
+package v1
+
+import (
+	"errors"
+	"time"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/permission"
+	"../../service/orderService"
+	"../../service/trackingService"
+	"../../util/timeHelper"
+	"../../util/websocket"
+	"../response"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// InitTracking inits live tracking apis of orders
+// @Title Tracking
+// @Description Tracking's router group.
+func InitTracking(parentRoute *echo.Group) {
+	route := parentRoute.Group("/orders/:id/tracking")
+	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	route.GET("", permission.AuthRequired(readOrderTracking))
+	route.POST("/share", permission.RoleRequired(shareOrderTracking, config.RoleUser))
+
+	// browsers can't set header of websocket, so token is given in query
+	streamRoute := parentRoute.Group("/orders/:id/tracking/ws")
+	streamRoute.Use(middleware.JWTWithConfig(middleware.JWTConfig{
+		SigningKey:  []byte(config.AuthTokenKey),
+		TokenLookup: "query:token",
+	}))
+
+	streamRoute.GET("", permission.AuthRequired(streamOrderTracking))
+
+	// tracking link of recipient works without account
+	publicRoute := parentRoute.Group("/tracking")
+
+	publicRoute.GET("/:token", readSharedTracking)
+	publicRoute.GET("/:token/ws", streamSharedTracking)
+}
+
+// @Title readOrderTracking
+// @Description Read status, driver location and eta of order, location is shown only while driver is on trip.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Order ID."
+// @Success 200 {object} model.Tracking 		"Returns tracking of order"
+// @Failure 400 {object} response.BasicResponse "err.tracking.bind"
+// @Failure 400 {object} response.BasicResponse "err.tracking.read"
+// @Resource /orders
+// @Router /orders/{id}/tracking [get]
+func readOrderTracking(c echo.Context) error {
+	orderID, err := trackedOrderID(c)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.tracking.bind", err)
+	}
+
+	tracking, err := trackingService.ReadTracking(orderID)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.tracking.read", err)
+	}
+	return response.SuccessInterface(c, tracking)
+}
+
+// @Title streamOrderTracking
+// @Description Stream tracking of order by websocket until trip is completed.
+// @Accept  json
+// @Produce	json
+// @Param   token			query 	string	true	"Auth token."
+// @Param   id				path   	string  true	"Order ID."
+// @Success 101 {object} model.Tracking 		"Sends tracking of order when it changes"
+// @Failure 400 {object} response.BasicResponse "err.tracking.bind"
+// @Failure 400 {object} response.BasicResponse "err.tracking.stream"
+// @Resource /orders
+// @Router /orders/{id}/tracking/ws [get]
+func streamOrderTracking(c echo.Context) error {
+	orderID, err := trackedOrderID(c)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.tracking.bind", err)
+	}
+	return streamTracking(c, orderID, 0)
+}
+
+// @Title shareOrderTracking
+// @Description Create time-limited tracking link for recipient of order.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Order ID."
+// @Success 200 {object} model.TrackingShare 	"Returns tracking link"
+// @Failure 400 {object} response.BasicResponse "err.tracking.bind"
+// @Failure 400 {object} response.BasicResponse "err.tracking.share"
+// @Resource /orders
+// @Router /orders/{id}/tracking/share [post]
+func shareOrderTracking(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.tracking.bind", errors.New("Retreived object id is invalid"))
+	}
+	order, err := orderService.ReadOrder(bson.ObjectIdHex(c.Param("id")))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.tracking.share", err)
+	}
+	userID, _ := permission.InfoFromToken(c)
+	if order.UserID != userID {
+		return response.KnownErrJSON(c, "err.tracking.share", errors.New("This order is not yours"))
+	}
+	if order.Recipient == "" {
+		return response.KnownErrJSON(c, "err.tracking.share", errors.New("Order has no recipient"))
+	}
+
+	expiresAt := timeHelper.GetCurrentTime() + int64(config.TrackingShareTTL/time.Second)
+	token := trackingService.ShareToken(order.ID, expiresAt)
+	return response.SuccessInterface(c, &model.TrackingShare{
+		Token:     token,
+		URL:       config.TrackingURL + token,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// @Title readSharedTracking
+// @Description Read tracking of order with tracking link of recipient.
+// @Accept  json
+// @Produce	json
+// @Param   token			path   	string  true	"Token of tracking link."
+// @Success 200 {object} model.Tracking 		"Returns tracking of order"
+// @Failure 400 {object} response.BasicResponse "err.tracking.bind"
+// @Failure 400 {object} response.BasicResponse "err.tracking.read"
+// @Resource /tracking
+// @Router /tracking/{token} [get]
+func readSharedTracking(c echo.Context) error {
+	orderID, _, err := trackingService.ParseShareToken(c.Param("token"), timeHelper.GetCurrentTime())
+	if err != nil {
+		return response.KnownErrJSON(c, "err.tracking.bind", err)
+	}
+
+	tracking, err := trackingService.ReadTracking(orderID)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.tracking.read", err)
+	}
+	return response.SuccessInterface(c, tracking)
+}
+
+// @Title streamSharedTracking
+// @Description Stream tracking of order by websocket with tracking link of recipient until link expires.
+// @Accept  json
+// @Produce	json
+// @Param   token			path   	string  true	"Token of tracking link."
+// @Success 101 {object} model.Tracking 		"Sends tracking of order when it changes"
+// @Failure 400 {object} response.BasicResponse "err.tracking.bind"
+// @Failure 400 {object} response.BasicResponse "err.tracking.stream"
+// @Resource /tracking
+// @Router /tracking/{token}/ws [get]
+func streamSharedTracking(c echo.Context) error {
+	orderID, expiresAt, err := trackingService.ParseShareToken(c.Param("token"), timeHelper.GetCurrentTime())
+	if err != nil {
+		return response.KnownErrJSON(c, "err.tracking.bind", err)
+	}
+	return streamTracking(c, orderID, expiresAt)
+}
+
+// trackedOrderID returns order of path that client of token is party of
+func trackedOrderID(c echo.Context) (bson.ObjectId, error) {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return "", errors.New("Retreived object id is invalid")
+	}
+	order, err := orderService.ReadOrder(bson.ObjectIdHex(c.Param("id")))
+	if err != nil {
+		return "", err
+	}
+	clientID, role := permission.InfoFromToken(c)
+	if role != config.RoleAdmin && clientID != order.UserID && clientID != order.BusinessID && clientID != order.DriverID {
+		return "", errors.New("This order is not yours")
+	}
+	return order.ID, nil
+}
+
+// streamTracking sends tracking of order when it changes until order is finished, client leaves or link expires
+func streamTracking(c echo.Context, orderID bson.ObjectId, expiresAt int64) error {
+	conn, err := websocket.Upgrade(c.Response().Writer, c.Request())
+	if err != nil {
+		return response.KnownErrJSON(c, "err.tracking.stream", err)
+	}
+	defer conn.Close()
+
+	var sent *model.Tracking
+	for {
+		tracking, err := trackingService.ReadTracking(orderID)
+		if err != nil {
+			return nil
+		}
+		if trackingService.Changed(sent, tracking) {
+			if err := conn.WriteJSON(tracking); err != nil {
+				return nil
+			}
+			sent = tracking
+		}
+		if trackingService.IsFinished(tracking) || (expiresAt > 0 && tracking.UpdatedAt > expiresAt) {
+			return nil
+		}
+
+		select {
+		case <-conn.Closed():
+			return nil
+		case <-time.After(config.TrackingInterval):
+		}
+	}
+}
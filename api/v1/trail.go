@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+
+	"../../config"
+	"../../service/authService/permission"
+	"../../service/driverLocationService"
+	"../../service/orderService"
+	"../../util/log"
+	"../response"
+
+	"github.com/labstack/echo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// @Title readOrderTrail
+// @Description Read route that driver went on trip of order as geojson line string.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Order ID."
+// @Param   tolerance		form   	number  false	"Tolerance in meters of simplifying route, 0 returns every point."
+// @Success 200 {object} model.TripTrail 		"Returns geojson feature of trail"
+// @Failure 400 {object} response.BasicResponse "err.trail.bind"
+// @Failure 400 {object} response.BasicResponse "err.trail.read"
+// @Resource /orders
+// @Router /orders/{id}/trail [get]
+func readOrderTrail(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.trail.bind", errors.New("Retreived object id is invalid"))
+	}
+	order, err := orderService.ReadOrder(bson.ObjectIdHex(c.Param("id")))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.trail.read", err)
+	}
+	// parties of order can see trail for disputes
+	clientID, role := permission.InfoFromToken(c)
+	if role != config.RoleAdmin && clientID != order.UserID && clientID != order.BusinessID && clientID != order.DriverID {
+		return response.KnownErrJSON(c, "err.trail.read", errors.New("This order is not yours"))
+	}
+	tolerance := config.TrailTolerance
+	if value := c.FormValue("tolerance"); value != "" {
+		tolerance, _ = strconv.ParseFloat(value, 64)
+	}
+
+	points, err := driverLocationService.ReadTripPoints(order.ID)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.trail.read", err)
+	}
+	return response.SuccessInterface(c, driverLocationService.BuildTrail(order.ID, points, 0, 0, tolerance))
+}
+
+// recordTripActuals saves distance and duration of completed trip from location history
+func recordTripActuals(orderID bson.ObjectId) {
+	order, err := orderService.ReadOrder(orderID)
+	if err != nil {
+		return
+	}
+	distance, duration, err := driverLocationService.TripActuals(order)
+	if !log.CheckErrorNoStackWithMessage(err, "Failed to read location history of order %s", orderID.Hex()) || distance == 0 {
+		return
+	}
+	err = orderService.UpdateTripActuals(orderID, distance, duration)
+	log.CheckErrorNoStackWithMessage(err, "Failed to save trip actuals of order %s", orderID.Hex())
+}
@@ -0,0 +1,165 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/permission"
+	"../../service/walletService"
+	"../response"
+
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// InitWallet inits wallet apis
+// @Title Wallets
+// @Description Wallets's router group.
+func InitWallet(parentRoute *echo.Group) {
+	route := parentRoute.Group("/wallets")
+	route.Use(middleware.JWT([]byte(config.AuthTokenKey)))
+
+	route.GET("/me", permission.RoleRequired(readMyWallet, config.RoleUser))
+	route.GET("/me/transactions", permission.RoleRequired(readMyWalletTransactions, config.RoleUser))
+	route.GET("/:id", permission.RoleRequired(readWallet, config.RoleAdmin))
+	route.GET("/:id/transactions", permission.RoleRequired(readWalletTransactions, config.RoleAdmin))
+	route.POST("/:id/credit", permission.RoleRequired(grantWalletCredit, config.RoleAdmin))
+
+	walletService.InitService()
+}
+
+// @Title readMyWallet
+// @Description Read wallet balance of user.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Success 200 {object} model.Wallet 			"Returns wallet"
+// @Failure 400 {object} response.BasicResponse "err.wallet.read"
+// @Resource /wallets
+// @Router /wallets/me [get]
+func readMyWallet(c echo.Context) error {
+	userID, _ := permission.InfoFromToken(c)
+	return responseWallet(c, userID)
+}
+
+// @Title readMyWalletTransactions
+// @Description Read wallet ledger of user.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns wallet transactions"
+// @Failure 400 {object} response.BasicResponse "err.wallet.read"
+// @Resource /wallets
+// @Router /wallets/me/transactions [get]
+func readMyWalletTransactions(c echo.Context) error {
+	userID, _ := permission.InfoFromToken(c)
+	return responseWalletTransactions(c, userID)
+}
+
+// @Title readWallet
+// @Description Read wallet balance of user by admin.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"User ID."
+// @Success 200 {object} model.Wallet 			"Returns wallet"
+// @Failure 400 {object} response.BasicResponse "err.wallet.bind"
+// @Failure 400 {object} response.BasicResponse "err.wallet.read"
+// @Resource /wallets
+// @Router /wallets/{id} [get]
+func readWallet(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.wallet.bind", errors.New("Retreived object id is invalid"))
+	}
+	return responseWallet(c, bson.ObjectIdHex(c.Param("id")))
+}
+
+// @Title readWalletTransactions
+// @Description Read wallet ledger of user by admin.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"User ID."
+// @Param   offset			form    int		false	"Offset for pagination."
+// @Param   count 			form    int		false	"Count that will show per page."
+// @Success 200 {object} model.ListForm 		"Returns wallet transactions"
+// @Failure 400 {object} response.BasicResponse "err.wallet.bind"
+// @Failure 400 {object} response.BasicResponse "err.wallet.read"
+// @Resource /wallets
+// @Router /wallets/{id}/transactions [get]
+func readWalletTransactions(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.wallet.bind", errors.New("Retreived object id is invalid"))
+	}
+	return responseWalletTransactions(c, bson.ObjectIdHex(c.Param("id")))
+}
+
+// @Title grantWalletCredit
+// @Description Grant credit to wallet of user.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"User ID."
+// @Param   amount			form   	int  	true	"Amount in cents."
+// @Param   source			form   	string  false	"grant(default), refund, promotion, referral"
+// @Param   expiresAt		form   	int  	false	"Expiration time of credit."
+// @Param   orderId			form   	string  false	"Order that is compensated."
+// @Param   note			form   	string  true	"Note of credit."
+// @Success 200 {object} model.WalletTransaction "Returns credit transaction"
+// @Failure 400 {object} response.BasicResponse "err.wallet.bind"
+// @Failure 400 {object} response.BasicResponse "err.wallet.credit"
+// @Resource /wallets
+// @Router /wallets/{id}/credit [post]
+func grantWalletCredit(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.wallet.bind", errors.New("Retreived object id is invalid"))
+	}
+	transaction := &model.WalletTransaction{}
+	if err := c.Bind(transaction); err != nil {
+		return response.KnownErrJSON(c, "err.wallet.bind", err)
+	}
+	switch transaction.Source {
+	case "":
+		transaction.Source = config.WalletGrant
+	case config.WalletGrant, config.WalletRefund, config.WalletPromotion, config.WalletReferral:
+	default:
+		return response.KnownErrJSON(c, "err.wallet.bind", errors.New("Source of credit is invalid"))
+	}
+	if transaction.Note == "" {
+		return response.KnownErrJSON(c, "err.wallet.bind", errors.New("Note of credit is empty"))
+	}
+	transaction.UserID = bson.ObjectIdHex(c.Param("id"))
+	transaction.IssuerID, _ = permission.InfoFromToken(c)
+	transaction.CreditID = ""
+	transaction.Key = ""
+
+	transaction, err := walletService.Credit(transaction)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.wallet.credit", err)
+	}
+	return response.SuccessInterface(c, transaction)
+}
+
+func responseWallet(c echo.Context, userID bson.ObjectId) error {
+	wallet, err := walletService.ReadWallet(userID)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.wallet.read", err)
+	}
+	return response.SuccessInterface(c, wallet)
+}
+
+func responseWalletTransactions(c echo.Context, userID bson.ObjectId) error {
+	offset, _ := strconv.Atoi(c.FormValue("offset"))
+	count, _ := strconv.Atoi(c.FormValue("count"))
+
+	transactions, total, err := walletService.ReadTransactions(userID, offset, count)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.wallet.read", err)
+	}
+	return response.SuccessInterface(c, &model.ListForm{total, transactions})
+}
@@ -0,0 +1,177 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/businessService"
+	"../../service/authService/permission"
+	"../../service/zoneService"
+	"../response"
+
+	"github.com/labstack/echo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// @Title readLocationZones
+// @Description Read service areas and exclusion zones of location.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Location ID."
+// @Success 200 {object} model.DeliveryZone 	"Returns zones of location"
+// @Failure 400 {object} response.BasicResponse "err.zone.bind"
+// @Failure 400 {object} response.BasicResponse "err.zone.read"
+// @Resource /locations
+// @Router /locations/{id}/zones [get]
+func readLocationZones(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.zone.bind", errors.New("Retreived object id is invalid"))
+	}
+	zones, err := zoneService.ReadZones(bson.ObjectIdHex(c.Param("id")))
+	if err != nil {
+		return response.KnownErrJSON(c, "err.zone.read", err)
+	}
+	return response.SuccessInterface(c, zones)
+}
+
+// @Title createLocationZone
+// @Description Draw service area or exclusion zone of location as geojson polygon.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Location ID."
+// @Param   zone			body   	model.DeliveryZone	true	"Zone."
+// @Success 200 {object} model.DeliveryZone 	"Returns created zone"
+// @Failure 400 {object} response.BasicResponse "err.zone.bind"
+// @Failure 400 {object} response.BasicResponse "err.zone.create"
+// @Resource /locations
+// @Router /locations/{id}/zones [post]
+func createLocationZone(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.zone.bind", errors.New("Retreived object id is invalid"))
+	}
+	zone := &model.DeliveryZone{}
+	if err := c.Bind(zone); err != nil {
+		return response.KnownErrJSON(c, "err.zone.bind", err)
+	}
+
+	zone, err := zoneService.CreateZone(bson.ObjectIdHex(c.Param("id")), zone)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.zone.create", err)
+	}
+	return response.SuccessInterface(c, zone)
+}
+
+// @Title updateLocationZone
+// @Description Update name, kind, polygon and activation of zone.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Zone ID."
+// @Param   zone			body   	model.DeliveryZone	true	"Zone."
+// @Success 200 {object} model.DeliveryZone 	"Returns updated zone"
+// @Failure 400 {object} response.BasicResponse "err.zone.bind"
+// @Failure 400 {object} response.BasicResponse "err.zone.update"
+// @Resource /locations
+// @Router /locations/zones/{id} [put]
+func updateLocationZone(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.zone.bind", errors.New("Retreived object id is invalid"))
+	}
+	zone := &model.DeliveryZone{}
+	if err := c.Bind(zone); err != nil {
+		return response.KnownErrJSON(c, "err.zone.bind", err)
+	}
+
+	zone, err := zoneService.UpdateZone(bson.ObjectIdHex(c.Param("id")), zone)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.zone.update", err)
+	}
+	return response.SuccessInterface(c, zone)
+}
+
+// @Title deleteLocationZone
+// @Description Delete zone.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Zone ID."
+// @Success 200 {object} response.BasicResponse "Zone is deleted"
+// @Failure 400 {object} response.BasicResponse "err.zone.bind"
+// @Failure 400 {object} response.BasicResponse "err.zone.delete"
+// @Resource /locations
+// @Router /locations/zones/{id} [delete]
+func deleteLocationZone(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.zone.bind", errors.New("Retreived object id is invalid"))
+	}
+	if err := zoneService.DeleteZone(bson.ObjectIdHex(c.Param("id"))); err != nil {
+		return response.KnownErrJSON(c, "err.zone.delete", err)
+	}
+	return response.SuccessInterface(c, "Zone is deleted correctly.")
+}
+
+// @Title resolveZone
+// @Description Resolve service zone and city of coordinates.
+// @Accept  json
+// @Produce	json
+// @Param   lat				form   	float64 true	"Latitude."
+// @Param   lng				form   	float64 true	"Longitude."
+// @Success 200 {object} model.DeliveryZone 	"Returns service zone"
+// @Failure 400 {object} response.BasicResponse "err.zone.resolve"
+// @Resource /locations
+// @Router /public/zones/resolve [get]
+func resolveZone(c echo.Context) error {
+	lat, _ := strconv.ParseFloat(c.FormValue("lat"), 64)
+	lng, _ := strconv.ParseFloat(c.FormValue("lng"), 64)
+
+	zone, err := zoneService.ResolveZone([]float64{lng, lat})
+	if err != nil {
+		return response.KnownErrJSON(c, "err.zone.resolve", err)
+	}
+	return response.SuccessInterface(c, zone)
+}
+
+// @Title updateBusinessDelivery
+// @Description Update area that business delivers to as geojson polygon or radius around business.
+// @Accept  json
+// @Produce	json
+// @Param   Authorization	header 	string	true	"Bearer {token}"
+// @Param   id				path   	string  true	"Business ID."
+// @Param   area			body   	model.DeliveryArea	true	"Delivery area, default radius is used when empty."
+// @Success 200 {object} model.Business 		"Returns business"
+// @Failure 400 {object} response.BasicResponse "err.business.bind"
+// @Failure 400 {object} response.BasicResponse "err.business.update"
+// @Resource /businesses
+// @Router /businesses/{id}/delivery [put]
+func updateBusinessDelivery(c echo.Context) error {
+	if !bson.IsObjectIdHex(c.Param("id")) {
+		return response.KnownErrJSON(c, "err.business.bind", errors.New("Retreived object id is invalid"))
+	}
+	objid := bson.ObjectIdHex(c.Param("id"))
+	clientID, role := permission.InfoFromToken(c)
+	if role != config.RoleAdmin && clientID != objid {
+		return response.KnownErrJSON(c, "err.business.bind", errors.New("This business is not you"))
+	}
+	area := &model.DeliveryArea{}
+	if err := c.Bind(area); err != nil {
+		return response.KnownErrJSON(c, "err.business.bind", err)
+	}
+	if area.Area != nil {
+		if err := zoneService.ValidArea(area.Area); err != nil {
+			return response.KnownErrJSON(c, "err.business.bind", err)
+		}
+	}
+	if area.Radius < 0 {
+		return response.KnownErrJSON(c, "err.business.bind", errors.New("Radius can't be negative"))
+	}
+
+	business, err := businessService.UpdateDeliveryArea(objid, area)
+	if err != nil {
+		return response.KnownErrJSON(c, "err.business.update", err)
+	}
+	return response.SuccessInterface(c, business)
+}
@@ -21,6 +21,8 @@ const (
 	DocumentNone     = 0
 	DocumentPending  = 1
 	DocumentAccepted = 2
+	DocumentRejected = 3
+	DocumentExpired  = 4
 
 	ScheduleFromTime = 631180800
 	ScheduleToTime   = 631224000
@@ -40,6 +42,7 @@ const (
 	SortRecommend    = 1
 	SortPopular      = 2
 	SortDeliveryTime = 3
+	SortRating       = 4
 
 	QueryAll       = 0
 	QueryPopular   = 1
@@ -47,6 +50,19 @@ const (
 	QueryUnder     = 3
 )
 
+// business discovery constant
+const (
+	DiscoverDistance   = "distance"
+	DiscoverRating     = "rating"
+	DiscoverPopularity = "popularity"
+	DiscoverETA        = "eta"
+
+	DefaultDiscoverRadius = 10000 // meters
+	DeliverySpeed         = 400   // meters per minute
+	DiscoverLimit         = 1000
+	DiscoverCount         = 20
+)
+
 // order status constant
 const (
 	None           = "None"
@@ -84,3 +100,98 @@ const (
 	NoRated = 1
 	Rated   = 2
 )
+
+// food revision constant
+const (
+	RevisionCreate   = "Create"
+	RevisionUpdate   = "Update"
+	RevisionFoodType = "FoodType" // food type and its options that business changes
+
+	RevisionPending  = "RevisionPending"
+	RevisionApproved = "RevisionApproved"
+	RevisionRejected = "RevisionRejected"
+)
+
+// search suggestion type constant
+const (
+	SuggestBusiness = "business"
+	SuggestFood     = "food"
+	SuggestDietary  = "dietary"
+	SuggestMealKind = "mealKind"
+)
+
+// rating aggregation constant, subject type is same with collection name
+const (
+	RatingBusiness = "business"
+	RatingDriver   = "driver"
+	RatingUser     = "user"
+
+	RatingRecentCount = 100 // orders in recent rating window
+	RatingWindowDays  = 7   // order can be rated in these days after completion
+	RatingMin         = 1   // stars of rate
+	RatingMax         = 5
+)
+
+// review constant
+const (
+	ReviewPending   = "ReviewPending"
+	ReviewPublished = "ReviewPublished"
+	ReviewRejected  = "ReviewRejected"
+
+	ReviewEditHours = 24 // customer can edit review in these hours
+	ReviewFlagLimit = 3  // review is held for moderation with these flags
+)
+
+// payment status constant
+const (
+	PaymentAuthorized = "PaymentAuthorized"
+	PaymentCaptured   = "PaymentCaptured"
+	PaymentVoided     = "PaymentVoided"
+	PaymentRefunded   = "PaymentRefunded"
+	PaymentFailed     = "PaymentFailed"
+)
+
+// refund constant
+const (
+	RefundFull    = "RefundFull"
+	RefundFeeOnly = "RefundFeeOnly" // booking fee is refunded only
+	RefundPercent = "RefundPercent"
+	RefundNone    = "RefundNone"
+
+	RefundCancellation = "RefundCancellation"
+	RefundSupport      = "RefundSupport"
+
+	RefundRelease = "RefundRelease" // authorized amount is not captured
+	RefundGateway = "RefundGateway" // captured amount is refunded by gateway
+	RefundWallet  = "RefundWallet"  // amount is credited to wallet
+
+	RefundSucceeded = "RefundSucceeded"
+	RefundFailed    = "RefundFailed"
+)
+
+// wallet transaction source constant
+const (
+	WalletRefund    = "refund"
+	WalletPromotion = "promotion"
+	WalletReferral  = "referral"
+	WalletGrant     = "grant"
+	WalletOrder     = "order"
+	WalletReversal  = "reversal"
+	WalletExpiry    = "expiry"
+
+	WalletRetry = 5 // retry of appending transaction on concurrent write
+)
+
+// promotion constant
+const (
+	PromotionPercent      = "PromotionPercent"
+	PromotionFixed        = "PromotionFixed"
+	PromotionFreeDelivery = "PromotionFreeDelivery"
+)
+
+// referral status constant
+const (
+	ReferralPending  = "ReferralPending"
+	ReferralRewarded = "ReferralRewarded"
+	ReferralRejected = "ReferralRejected"
+)
@@ -0,0 +1,13 @@
+package config
+
+// DriverCommissionPercent is platform commission of trip fare, tips are not commissioned
+var DriverCommissionPercent = 20.0
+
+const (
+	EarningTrip       = "trip"
+	EarningTip        = "tip"
+	EarningAdjustment = "adjustment"
+
+	EarningDaily  = "day"
+	EarningWeekly = "week"
+)
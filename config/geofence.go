@@ -0,0 +1,34 @@
+package config
+
+// GeofenceMode is how trip status is changed when driver dwells in geofence
+var GeofenceMode = GeofenceSuggest
+
+// GeofencePickupRadius is radius in meters of geofence around business
+var GeofencePickupRadius = 100.0
+
+// GeofenceDropoffRadius is radius in meters of geofence around delivery location
+var GeofenceDropoffRadius = 75.0
+
+// GeofenceHysteresis is meters out of geofence that driver must go to leave it, so gps jitter on edge doesn't reset dwell
+var GeofenceHysteresis = 30.0
+
+// GeofenceDwell is seconds that driver must stay in geofence before transition
+var GeofenceDwell int64 = 45
+
+// GeofenceAnomalyDistance is meters from expected point that transition marked by driver is flagged
+var GeofenceAnomalyDistance = 300.0
+
+// GeofenceLocationMaxAge is seconds that location of driver is fresh enough to flag anomaly
+var GeofenceLocationMaxAge int64 = 120
+
+const (
+	GeofenceOff     = "off"
+	GeofenceSuggest = "suggest"
+	GeofenceAuto    = "auto"
+
+	GeofencePickup  = "pickup"
+	GeofenceDropoff = "dropoff"
+
+	GeofenceSuggested = "GeofenceSuggested"
+	GeofenceApplied   = "GeofenceApplied"
+)
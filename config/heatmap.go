@@ -0,0 +1,28 @@
+package config
+
+import "time"
+
+// HeatmapPrecision is default length of geohash of cells, 6 is about 1.2km by 0.6km
+var HeatmapPrecision = 6
+
+// HeatmapMinPrecision and HeatmapMaxPrecision are bounds of precision that ops can select
+var HeatmapMinPrecision = 4
+var HeatmapMaxPrecision = 7
+
+// HeatmapWindows are time windows of orders that ops can select
+var HeatmapWindows = map[string]time.Duration{
+	"15m": 15 * time.Minute,
+	"1h":  time.Hour,
+	"6h":  6 * time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+// HeatmapDefaultWindow is window of heatmap when it is not selected
+var HeatmapDefaultWindow = "1h"
+
+// HeatmapLocationMaxAge is seconds that location of online driver is fresh enough for heatmap
+var HeatmapLocationMaxAge int64 = 300
+
+// HeatmapMaxOrders is count of latest orders in window that heatmap aggregates at most
+var HeatmapMaxOrders = 20000
@@ -0,0 +1,20 @@
+package config
+
+import "time"
+
+// driver onboarding status, driver can go online only when onboarding is approved
+const (
+	OnboardingIncomplete = "incomplete"
+	OnboardingPending    = "pending"
+	OnboardingRejected   = "rejected"
+	OnboardingApproved   = "approved"
+
+	OnboardingUpdated = "OnboardingUpdated"
+)
+
+// DocumentReminderDays are days before expiry that driver is reminded to upload new document
+var DocumentReminderDays = []int{30, 7, 1}
+
+// DocumentExpiryInterval is interval of scanning documents, reminder is sent once
+// so scanning more often than daily only expires documents closer to the date
+var DocumentExpiryInterval = time.Hour
@@ -0,0 +1,27 @@
+package config
+
+// PaymentGateway is gateway that processes payments, PaymentGatewayStripe or PaymentGatewayFake that approves every card for tests in development
+var PaymentGateway = PaymentGatewayStripe
+
+// StripeURL is Stripe API URL
+var StripeURL = "https://api.stripe.com/v1"
+
+// StripeSecretKey is Stripe SECRET KEY
+var StripeSecretKey = ""
+
+const (
+	PaymentGatewayFake   = "fake"
+	PaymentGatewayStripe = "stripe"
+	PaymentCurrency      = "usd"
+
+	TipReceived = "TipReceived" // notification type of tip after trip
+)
+
+// TipPercents are preset percentages of tip
+var TipPercents = []int{10, 15, 20}
+
+// TipMax is largest custom tip, larger amount is taken as typo
+var TipMax = 100.0
+
+// TipWindowHours is hours that user can tip after trip is completed
+var TipWindowHours = 24
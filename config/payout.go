@@ -0,0 +1,35 @@
+package config
+
+// CommissionPercent is default platform commission of food price when business and city have no rule
+var CommissionPercent = 15.0
+
+// ProcessingFeePercent is card processing fee of order that business pays
+var ProcessingFeePercent = 2.9
+
+// ProcessingFeeFixed is fixed card processing fee of order in cents
+var ProcessingFeeFixed int64 = 30
+
+// PayoutBankRouting is routing number of bank that sends payouts
+var PayoutBankRouting = "000000000"
+
+// PayoutBankName is name of bank that sends payouts
+var PayoutBankName = "GOGO BANK"
+
+// PayoutCompanyName is name of company in payout batch file
+var PayoutCompanyName = "GOGO"
+
+// PayoutCompanyID is tax identification of company in payout batch file
+var PayoutCompanyID = "0000000000"
+
+const (
+	SettlementOrder  = "order"
+	SettlementRefund = "refund"
+
+	PayoutPending  = "pending"
+	PayoutHeld     = "held" // bank info of business is invalid
+	PayoutExported = "exported"
+	PayoutPaid     = "paid"
+
+	PayoutFormatCSV   = "csv"
+	PayoutFormatNACHA = "nacha"
+)
@@ -0,0 +1,21 @@
+package config
+
+import "time"
+
+// InvoiceCountries are country codes of businesses that require sequential invoice numbers
+var InvoiceCountries = []string{"DE", "FR", "ES", "IT", "MX", "BR"}
+
+// InvoicePrefix is prefix of invoice number that is followed by sequence of business
+var InvoicePrefix = "INV"
+
+// ReceiptEmailAttempts is times that receipt email is sent when sending fails
+var ReceiptEmailAttempts = 3
+
+// ReceiptEmailRetryDelay is time between attempts of receipt email
+var ReceiptEmailRetryDelay = time.Minute
+
+const (
+	ReceiptFormatJSON = "json"
+	ReceiptFormatHTML = "html"
+	ReceiptFormatPDF  = "pdf"
+)
@@ -0,0 +1,21 @@
+package config
+
+// ReferralRewardType is reward of referral, ReferralRewardWallet or ReferralRewardPromotion
+var ReferralRewardType = ReferralRewardWallet
+
+// ReferrerReward is reward of user that invites in cents
+var ReferrerReward int64 = 500
+
+// RefereeReward is reward of invited user in cents
+var RefereeReward int64 = 500
+
+// ReferralRewardDays is days that reward is valid
+var ReferralRewardDays = 90
+
+// ReferralLimit is count of rewarded referrals of referrer
+var ReferralLimit = 20
+
+const (
+	ReferralRewardWallet    = "wallet"
+	ReferralRewardPromotion = "promotion"
+)
@@ -0,0 +1,34 @@
+package config
+
+import "time"
+
+// ShiftCheckInGrace is seconds after start of shift that driver must go online, otherwise driver is no-show
+var ShiftCheckInGrace int64 = 15 * 60
+
+// ShiftNoShowLimit is count of no-shows in ShiftNoShowPeriod that blocks driver from booking shifts
+var ShiftNoShowLimit = 3
+
+// ShiftNoShowPeriod is seconds of recent shifts that no-shows are counted in
+var ShiftNoShowPeriod int64 = 30 * 24 * 3600
+
+// ShiftForecastWeeks is count of past weeks that demand of shift is forecasted from
+var ShiftForecastWeeks = 4
+
+// ShiftOrdersPerDriverHour is count of orders that driver delivers in an hour
+var ShiftOrdersPerDriverHour = 2.0
+
+// ShiftDispatchPriority shows drivers on booked shift first to dispatch
+var ShiftDispatchPriority = false
+
+// ShiftCheckInterval is interval of marking no-shows of booked shifts
+var ShiftCheckInterval = 5 * time.Minute
+
+const (
+	ShiftBooked    = "booked"
+	ShiftCancelled = "cancelled"
+	ShiftAttended  = "attended"
+	ShiftNoShow    = "noShow"
+)
+
+// ShiftUpdated is notification type of shift of driver
+const ShiftUpdated = "ShiftUpdated"
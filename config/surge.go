@@ -0,0 +1,33 @@
+package config
+
+import "time"
+
+// SurgeInterval is interval of computing surge of zones
+var SurgeInterval = time.Minute
+
+// SurgeThreshold is ratio of open orders to online drivers that surge starts over
+var SurgeThreshold = 1.0
+
+// SurgeSensitivity is multiplier that is added for each ratio over threshold
+var SurgeSensitivity = 0.5
+
+// SurgeCap is max multiplier of surge
+var SurgeCap = 2.5
+
+// SurgeSmoothing is weight of new demand in smoothed multiplier, lower is smoother
+var SurgeSmoothing = 0.3
+
+// SurgeStep is step that multiplier is rounded to
+var SurgeStep = 0.1
+
+// SurgeLocationMaxAge is seconds that location of online driver is fresh enough to count as supply
+var SurgeLocationMaxAge int64 = 120
+
+// SurgeQuoteTTL is time that multiplier of quote is locked for order
+var SurgeQuoteTTL = 10 * time.Minute
+
+// SurgeHistoryRetention is time that history of surge is kept
+var SurgeHistoryRetention = 180 * 24 * time.Hour
+
+// OpenOrderStatuses are order status that order waits for driver
+var OpenOrderStatuses = []string{OrderRequest, OrderAccepted, OrderPrepared}
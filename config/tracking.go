@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+// TrackingShareTTL is time that shared tracking link of recipient is valid
+var TrackingShareTTL = 24 * time.Hour
+
+// TrackingInterval is interval of pushing tracking of order to websocket
+var TrackingInterval = 5 * time.Second
+
+// TrackingRouteFactor is ratio of road distance to straight distance for eta of tracking
+var TrackingRouteFactor = 1.3
+
+// TrackingURL is url of tracking page that is shared to recipient with token
+var TrackingURL = HostURL + "/tracking/"
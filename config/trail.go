@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+// LocationHistoryRetention is time that location history of trips is kept
+var LocationHistoryRetention = 90 * 24 * time.Hour
+
+// TrailTolerance is default tolerance in meters of simplifying trail of trip
+var TrailTolerance = 5.0
+
+// ActiveTripStatuses are trip status that driver is on trip of order
+var ActiveTripStatuses = []string{TripAccepted, TripConfirmed, TripStarted, TripArrived, TripDropped}
+
+// TrailMaxSpeed is speed in meters per second that is possible for driver, faster points are gps jumps
+var TrailMaxSpeed = 55.0
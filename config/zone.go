@@ -0,0 +1,9 @@
+package config
+
+// DefaultDeliveryRadius is meters that business delivers without own delivery area
+var DefaultDeliveryRadius = 10000.0
+
+const (
+	ZoneService   = "service"
+	ZoneExclusion = "exclusion"
+)
@@ -7,6 +7,7 @@ type BankInfo struct {
 	Name    string `json:"name"`
 	Account string `json:"account"`
 	Number  string `json:"number"`
+	Routing string `json:"routing"`
 }
 
 // Schedule is for open, close of restaurant
@@ -43,6 +44,10 @@ type Business struct {
 	MealKinds         []string      `json:"mealKinds,omitempty" bson:"mealKinds,omitempty"`
 	Schedules         []*Schedule   `json:"schedules"`
 	PreparationTime   int           `json:"preparationTime" bson:"preparationTime"`
+	FreeDelivery      bool          `json:"freeDelivery" bson:"freeDelivery"`
+	Rating            float32       `json:"rating"`
+	RatingCount       int           `json:"ratingCount" bson:"ratingCount"`
+	OrderCount        int           `json:"orderCount" bson:"orderCount"`
 	Closed            bool          `json:"closed"`
 	Recommend         bool          `json:"recommend"`
 	MostPopular       bool          `json:"mostPopular" bson:"mostPopular"`
@@ -52,6 +57,9 @@ type Business struct {
 	OneSignalPlayerID string        `json:"onesignalPlayerId,omitempty" bson:"onesignalPlayerId,omitempty"`
 	CreatedAt         int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
 	UpdatedAt         int64         `json:"updatedAt" bson:"updatedAt" description:"Updated date."`
+
+	// area that business delivers to, default radius is used without it
+	DeliveryArea *DeliveryArea `json:"deliveryArea,omitempty" bson:"deliveryArea,omitempty"`
 }
 
 // PublicBusiness struct.
@@ -73,19 +81,32 @@ type PublicBusiness struct {
 	MealKinds       []string      `json:"mealKinds,omitempty" bson:"mealKinds,omitempty"`
 	Schedules       []*Schedule   `json:"schedules"`
 	PreparationTime int           `json:"preparationTime" bson:"preparationTime"`
+	FreeDelivery    bool          `json:"freeDelivery" bson:"freeDelivery"`
+	Rating          float32       `json:"rating"`
+	RatingCount     int           `json:"ratingCount" bson:"ratingCount"`
+	OrderCount      int           `json:"orderCount" bson:"orderCount"`
 	Verify          *Verify       `json:"verify,omitempty" bson:"verify,omitempty"`
 	Closed          bool          `json:"closed"`
 	Recommend       bool          `json:"recommend"`
 	MostPopular     bool          `json:"mostPopular" bson:"mostPopular"`
+	DeliveryArea    *DeliveryArea `json:"deliveryArea,omitempty" bson:"deliveryArea,omitempty"`
+	Distance        float64       `json:"distance,omitempty" bson:"distance,omitempty"`
+	ETA             float64       `json:"eta,omitempty" bson:"eta,omitempty"` // minutes
+	OpenNow         bool          `json:"openNow" bson:"openNow,omitempty"`
 	CreatedAt       int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
 	UpdatedAt       int64         `json:"updatedAt" bson:"updatedAt" description:"Updated date."`
+	// search result
+	Score        float64 `json:"score,omitempty" bson:"-"`
+	MatchedFoods []*Food `json:"matchedFoods,omitempty" bson:"-"`
 }
 
 // ListBusiness is strucut for genear user search
 type ListBusiness struct {
-	QueryCode int               `json:"queryCode"`
-	IsSlide   bool              `json:"isSlide"`
-	Items     []*PublicBusiness `json:"items"`
+	QueryCode  int               `json:"queryCode"`
+	Title      string            `json:"title,omitempty"`
+	IsSlide    bool              `json:"isSlide"`
+	Items      []*PublicBusiness `json:"items"`
+	NextCursor string            `json:"nextCursor,omitempty"`
 }
 
 // QueryBusiness is struct for query business
@@ -93,7 +114,10 @@ type QueryBusiness struct {
 	Lat     float64 `json:"lat"`
 	Lng     float64 `json:"lng"`
 	PlaceID string  `json:"placeId"`
-	Sort    int     `json:"sort"` // 0:all, 1:recommend, 2:most popular, 3:delivery time
+	Sort    int     `json:"sort"` // 0:all, 1:recommend, 2:most popular, 3:delivery time, 4:rating
 	Price   []int   `json:"price"`
 	Dietary []int   `json:"dietary"`
+	Query   string  `json:"query"` // full text search of restaurants and dishes
+	Offset  int     `json:"offset"`
+	Count   int     `json:"count"`
 }
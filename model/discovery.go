@@ -0,0 +1,62 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// DiscoveryQuery is filter and sort of business discovery
+type DiscoveryQuery struct {
+	Lat          float64 `json:"lat,omitempty" bson:"-"`
+	Lng          float64 `json:"lng,omitempty" bson:"-"`
+	PlaceID      string  `json:"placeId,omitempty" bson:"-"`
+	Price        []int   `json:"price,omitempty" bson:"price,omitempty"`
+	Dietary      []int   `json:"dietary,omitempty" bson:"dietary,omitempty"`
+	MealKind     []int   `json:"mealKind,omitempty" bson:"mealKind,omitempty"`
+	OpenNow      bool    `json:"openNow,omitempty" bson:"openNow,omitempty"`
+	FreeDelivery bool    `json:"freeDelivery,omitempty" bson:"freeDelivery,omitempty"`
+	MinRating    float32 `json:"minRating,omitempty" bson:"minRating,omitempty"`
+	MaxETA       int     `json:"maxEta,omitempty" bson:"maxEta,omitempty"` // minutes
+	MostPopular  bool    `json:"mostPopular,omitempty" bson:"mostPopular,omitempty"`
+	Recommend    bool    `json:"recommend,omitempty" bson:"recommend,omitempty"`
+	Sort         string  `json:"sort,omitempty" bson:"sort,omitempty"` // distance, rating, popularity, eta
+	Cursor       string  `json:"cursor,omitempty" bson:"-"`
+	Count        int     `json:"count,omitempty" bson:"count,omitempty"`
+	// businesses that are shown already in other rails
+	Exclude []bson.ObjectId `json:"-" bson:"-"`
+}
+
+// FacetCount is count of businesses with value of filter
+type FacetCount struct {
+	Value float64 `json:"value" bson:"_id"`
+	Count int     `json:"count"`
+}
+
+// DiscoveryFacets is counts for each filter
+type DiscoveryFacets struct {
+	Price        []*FacetCount `json:"price"`
+	Dietary      []*FacetCount `json:"dietary"`
+	MealKind     []*FacetCount `json:"mealKind"`
+	OpenNow      int           `json:"openNow"`
+	FreeDelivery int           `json:"freeDelivery"`
+	MinRating    []*FacetCount `json:"minRating"`
+	MaxETA       []*FacetCount `json:"maxEta"`
+}
+
+// DiscoveryResult is a page of business discovery
+type DiscoveryResult struct {
+	Total      int               `json:"total"`
+	Items      []*PublicBusiness `json:"items"`
+	NextCursor string            `json:"nextCursor,omitempty"`
+	Facets     *DiscoveryFacets  `json:"facets"`
+}
+
+// Rail is a list of businesses on home screen that admin configures
+type Rail struct {
+	ID        bson.ObjectId   `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	Code      int             `json:"code"`
+	Title     string          `json:"title"`
+	IsSlide   bool            `json:"isSlide" bson:"isSlide"`
+	Query     *DiscoveryQuery `json:"query"`
+	Position  int             `json:"position"`
+	Enabled   bool            `json:"enabled"`
+	CreatedAt int64           `json:"createdAt" bson:"createdAt" description:"Created date."`
+	UpdatedAt int64           `json:"updatedAt" bson:"updatedAt" description:"Updated date."`
+}
@@ -12,4 +12,7 @@ type Document struct {
 	Valid      bool          `json:"valid"`
 	CreatedAt  int64         `json:"createdAt" bson:"createdAt"`
 	UpdatedAt  int64         `json:"updatedAt" bson:"updatedAt"`
+
+	// vehicles that document is required for, empty means every vehicle
+	VehicleIDs []bson.ObjectId `json:"vehicleIds" bson:"vehicleIds"`
 }
@@ -20,9 +20,15 @@ type DriverLocation struct {
 type VerifyDocument struct {
 	ID         bson.ObjectId `json:"id" bson:"_id"`
 	Document   *Document     `json:"document,omitempty" bson:"document,omitempty"`
-	Status     Status        `json:"status"` //0:not uploaded 1:pending 2:verifed
+	Status     Status        `json:"status"` //0:not uploaded 1:pending 2:verifed 3:rejected 4:expired
 	ExpireDate int64         `json:"expireDate,omitempty" bson:"expireDate,omitempty"`
 	Image      string        `json:"image"`
+
+	// review of admin, reason is given when document is rejected
+	Reason     string `json:"reason,omitempty" bson:"reason,omitempty"`
+	UploadedAt int64  `json:"uploadedAt,omitempty" bson:"uploadedAt,omitempty"`
+	ReviewedAt int64  `json:"reviewedAt,omitempty" bson:"reviewedAt,omitempty"`
+	Reminded   int    `json:"reminded,omitempty" bson:"reminded,omitempty"` // days before expiry of last reminder
 }
 
 // DriverVehicle struct
@@ -51,6 +57,7 @@ type Driver struct {
 	PhoneCode         string           `json:"phoneCode" bson:"phoneCode"`
 	Phone             string           `json:"phone"`
 	Rating            float32          `json:"rating"`
+	RatingCount       int              `json:"ratingCount" bson:"ratingCount"`
 	DriverVehicles    []*DriverVehicle `json:"driverVehicles" bson:"driverVehicles"`
 	LocationPlaceID   string           `json:"locationPlaceId,omitempty" bson:"locationPlaceId,omitempty"`
 	OneSignalPlayerID string           `json:"onesignalPlayerId,omitempty" bson:"onesignalPlayerId,omitempty"`
@@ -58,6 +65,13 @@ type Driver struct {
 	Platform          string           `json:"platform"`
 	CreatedAt         int64            `json:"createdAt" bson:"createdAt" description:"Driver created date"`
 	UpdatedAt         int64            `json:"updatedAt" bson:"updatedAt" description:"Driver updated date. This field will be updated when any update operation will be occured"`
+
+	// bank account that earnings are paid to
+	BankInfo BankInfo `json:"bankInfo" bson:"bankInfo"`
+	// onboarding status, driver can go online only when approved and with approved vehicle
+	Onboarding         string          `json:"onboarding" bson:"onboarding,omitempty"`
+	ApprovedVehicleIDs []bson.ObjectId `json:"approvedVehicleIds,omitempty" bson:"approvedVehicleIds,omitempty"`
+	OfflineReason      string          `json:"offlineReason,omitempty" bson:"offlineReason,omitempty"`
 }
 
 // PublicDriver struct.
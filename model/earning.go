@@ -0,0 +1,67 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// Earning is entry of earnings of driver, amounts are in cents
+type Earning struct {
+	ID         bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	DriverID   bson.ObjectId `json:"driverId" bson:"driverId"`
+	OrderID    bson.ObjectId `json:"orderId,omitempty" bson:"orderId,omitempty"`
+	Number     string        `json:"number,omitempty" bson:"number,omitempty"` // number of order
+	Kind       string        `json:"kind"`                                     // EarningTrip, EarningTip, EarningAdjustment
+	Key        string        `json:"-" bson:"key,omitempty"`
+	Kilometers float64       `json:"kilometers"`
+	Minutes    float64       `json:"minutes"`
+	Base       int64         `json:"base"`
+	Distance   int64         `json:"distance"`
+	Time       int64         `json:"time"`
+	Surge      int64         `json:"surge"`
+	Commission int64         `json:"commission"` // platform commission of fare
+	Tip        int64         `json:"tip"`
+	Adjustment int64         `json:"adjustment"`
+	Total      int64         `json:"total"`
+	Note       string        `json:"note,omitempty" bson:"note,omitempty"`
+	IssuerID   bson.ObjectId `json:"issuerId,omitempty" bson:"issuerId,omitempty"`
+	PayoutID   bson.ObjectId `json:"payoutId,omitempty" bson:"payoutId,omitempty"`
+	CreatedAt  int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
+}
+
+// EarningStatement is earnings of driver in day or week, amounts are in cents
+type EarningStatement struct {
+	Start      int64   `json:"start" bson:"_id"`
+	End        int64   `json:"end" bson:"-"`
+	Trips      int     `json:"trips"`
+	Kilometers float64 `json:"kilometers"`
+	Minutes    float64 `json:"minutes"`
+	Base       int64   `json:"base"`
+	Distance   int64   `json:"distance"`
+	Time       int64   `json:"time"`
+	Surge      int64   `json:"surge"`
+	Commission int64   `json:"commission"`
+	Tip        int64   `json:"tip"`
+	Adjustment int64   `json:"adjustment"`
+	Total      int64   `json:"total"`
+}
+
+// DriverPayout is payout of earnings of driver in batch, amount is in cents
+type DriverPayout struct {
+	DriverID bson.ObjectId `json:"driverId" bson:"_id"`
+	Name     string        `json:"name" bson:"name"`
+	BankInfo BankInfo      `json:"bankInfo" bson:"bankInfo"`
+	Count    int           `json:"count"`
+	Amount   int64         `json:"amount"`
+	Status   string        `json:"status,omitempty" bson:"status,omitempty"` // PayoutHeld when bank info is invalid
+	Note     string        `json:"note,omitempty" bson:"note,omitempty"`
+}
+
+// DriverPayoutBatch is payout of unpaid earnings of drivers until time
+type DriverPayoutBatch struct {
+	ID        bson.ObjectId   `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	Until     int64           `json:"until"`
+	Payouts   []*DriverPayout `json:"payouts"`
+	Count     int             `json:"count"`
+	Amount    int64           `json:"amount"`
+	Status    string          `json:"status"` // PayoutPending, PayoutExported, PayoutPaid
+	PaidAt    int64           `json:"paidAt,omitempty" bson:"paidAt,omitempty"`
+	CreatedAt int64           `json:"createdAt" bson:"createdAt" description:"Created date."`
+}
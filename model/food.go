@@ -16,6 +16,7 @@ type Food struct {
 	Name          string        `json:"name" description:"Restaurant name"`
 	Description   string        `json:"description" description:"Restaurant description"`
 	Price         float64       `json:"price"`
+	Version       int           `json:"version"`
 	// extra variable
 	SoldOut      bool  `json:"soldOut" bson:"soldOut"`
 	Enabled      bool  `json:"enabled"`
@@ -25,6 +26,8 @@ type Food struct {
 	FreeDelivery bool  `json:"freeDelivery" bson:"freeDelivery"`
 	CreatedAt    int64 `json:"createdAt" bson:"createdAt" description:"Created date."`
 	UpdatedAt    int64 `json:"updatedAt" bson:"updatedAt" description:"Updated date."`
+
+	PendingRevision *FoodRevision `json:"pendingRevision,omitempty" bson:"-"`
 }
 
 // BusinessFood is for business query
@@ -35,7 +38,9 @@ type BusinessFood struct {
 
 // OrderFood is ordered food struct
 type OrderFood struct {
-	Food        *Food   `json:"food"`
-	Price       float64 `json:"price"`
-	Instruction string  `json:"instruction"`
+	Food            *Food   `json:"food"`
+	Price           float64 `json:"price"`
+	Instruction     string  `json:"instruction"`
+	FoodVersion     int     `json:"foodVersion" bson:"foodVersion"`         // menu version that order was priced against
+	FoodTypeVersion int     `json:"foodTypeVersion" bson:"foodTypeVersion"` // version of food options
 }
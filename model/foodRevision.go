@@ -0,0 +1,22 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// FoodRevision is a menu change of business that is waiting for approval of admin
+type FoodRevision struct {
+	ID               bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	FoodID           bson.ObjectId `json:"foodId,omitempty" bson:"foodId,omitempty"`
+	Food             *Food         `json:"food,omitempty" bson:"food,omitempty"` // last approved food
+	BusinessID       bson.ObjectId `json:"businessId" bson:"businessId"`
+	Business         *Business     `json:"business,omitempty" bson:"business,omitempty"`
+	Type             string        `json:"type"` // Create, Update, FoodType
+	Proposed         *Food         `json:"proposed,omitempty" bson:"proposed,omitempty"`
+	FoodTypeID       bson.ObjectId `json:"foodTypeId,omitempty" bson:"foodTypeId,omitempty"` // revision of food type has no food
+	ProposedFoodType *FoodType     `json:"proposedFoodType,omitempty" bson:"proposedFoodType,omitempty"`
+	Status           string        `json:"status"` // RevisionPending, RevisionApproved, RevisionRejected
+	Comment          string        `json:"comment"`
+	ReviewerID       bson.ObjectId `json:"reviewerId,omitempty" bson:"reviewerId,omitempty"`
+	ReviewedAt       int64         `json:"reviewedAt,omitempty" bson:"reviewedAt,omitempty"`
+	CreatedAt        int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
+	UpdatedAt        int64         `json:"updatedAt" bson:"updatedAt" description:"Updated date."`
+}
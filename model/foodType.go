@@ -14,6 +14,7 @@ type FoodType struct {
 	BusinessID  bson.ObjectId `json:"businessId" bson:"businessId"`
 	Business    *Business     `json:"business,omitempty" bson:"business,omitempty"`
 	FoodOption  []*FoodOption `json:"foodOptions" bson:"foodOptions"`
+	Version     int           `json:"version"`
 	CreatedAt   int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
 	UpdatedAt   int64         `json:"updatedAt" bson:"updatedAt" description:"Updated date."`
 }
@@ -0,0 +1,28 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// FoodVersion is an immutable snapshot of food that is saved whenever food is changed
+type FoodVersion struct {
+	ID         bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	FoodID     bson.ObjectId `json:"foodId" bson:"foodId"`
+	Version    int           `json:"version"`
+	Food       *Food         `json:"food"`
+	AuthorID   bson.ObjectId `json:"authorId,omitempty" bson:"authorId,omitempty"`
+	AuthorRole string        `json:"authorRole,omitempty" bson:"authorRole,omitempty"`
+	RevisionID bson.ObjectId `json:"revisionId,omitempty" bson:"revisionId,omitempty"`
+	Comment    string        `json:"comment,omitempty" bson:"comment,omitempty"`
+	CreatedAt  int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
+}
+
+// FoodTypeVersion is an immutable snapshot of food type and its options
+type FoodTypeVersion struct {
+	ID         bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	FoodTypeID bson.ObjectId `json:"foodTypeId" bson:"foodTypeId"`
+	Version    int           `json:"version"`
+	FoodType   *FoodType     `json:"foodType" bson:"foodType"`
+	AuthorID   bson.ObjectId `json:"authorId,omitempty" bson:"authorId,omitempty"`
+	AuthorRole string        `json:"authorRole,omitempty" bson:"authorRole,omitempty"`
+	Comment    string        `json:"comment,omitempty" bson:"comment,omitempty"`
+	CreatedAt  int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
+}
@@ -0,0 +1,17 @@
+package model
+
+// TripGeofence is state of driver in geofence that trip goes to next
+type TripGeofence struct {
+	Fence     string `json:"fence"`                                          // pickup or dropoff
+	EnteredAt int64  `json:"enteredAt,omitempty" bson:"enteredAt,omitempty"` // zero while driver is out of fence
+	LastAt    int64  `json:"lastAt,omitempty" bson:"lastAt,omitempty"`       // last location in fence
+	Triggered string `json:"triggered,omitempty" bson:"triggered,omitempty"` // trip status that is suggested or applied
+}
+
+// TripAnomaly is transition that driver marked far from expected point
+type TripAnomaly struct {
+	TripStatus string   `json:"tripStatus" bson:"tripStatus"`
+	Distance   float64  `json:"distance"` // meters from expected point
+	Location   *GeoJSON `json:"location"`
+	At         int64    `json:"at"`
+}
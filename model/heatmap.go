@@ -0,0 +1,28 @@
+package model
+
+// HeatCell is demand and supply in geohash cell
+type HeatCell struct {
+	Geohash  string  `json:"geohash"`
+	Pickups  int     `json:"pickups"`  // orders of businesses in cell
+	Dropoffs int     `json:"dropoffs"` // orders delivered to cell
+	Drivers  int     `json:"drivers"`  // online drivers in cell now
+	Unmet    int     `json:"unmet"`    // orders of businesses in cell that no driver took
+	Ratio    float64 `json:"ratio"`    // pickups per online driver
+}
+
+// HeatFeature is geojson feature of cell
+type HeatFeature struct {
+	Type       string    `json:"type"`
+	Geometry   Polygon   `json:"geometry"`
+	Properties *HeatCell `json:"properties"`
+}
+
+// Heatmap is geojson feature collection of cells in window
+type Heatmap struct {
+	Type      string         `json:"type"`
+	Features  []*HeatFeature `json:"features"`
+	Window    string         `json:"window"`
+	Precision int            `json:"precision"`
+	From      int64          `json:"from"`
+	To        int64          `json:"to"`
+}
@@ -44,6 +44,7 @@ type Location struct {
 	DayTime      *Period        `json:"dayTime,omitempty" bson:"dayTime,omitempty"`
 	IsNightTime  bool           `json:"isNightTime" bson:"isNightTime"`
 	NightTime    *Period        `json:"nightTime,omitempty" bson:"nightTime,omitempty"`
+	SearchRadius float64        `json:"searchRadius" bson:"searchRadius"` // meters for business discovery
 	CreatedAt    int64          `json:"createdAt" bson:"createdAt"`
 	UpdatedAt    int64          `json:"updatedAt" bson:"updatedAt"`
 }
@@ -0,0 +1,51 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// OnboardingVehicle is check of required documents of driver's vehicle
+type OnboardingVehicle struct {
+	Number    string            `json:"number"`
+	VehicleID bson.ObjectId     `json:"vehicleId"`
+	Status    string            `json:"status"`
+	Reason    string            `json:"reason,omitempty"` // why vehicle is not approved
+	Missing   []*Document       `json:"missing"`
+	Expired   []*Document       `json:"expired"`
+	Pending   []*Document       `json:"pending"`
+	Rejected  []*VerifyDocument `json:"rejected"`
+}
+
+// Onboarding is onboarding state of driver, it is approved when one of vehicles is approved
+type Onboarding struct {
+	DriverID bson.ObjectId        `json:"driverId"`
+	Status   string               `json:"status"`
+	Reason   string               `json:"reason,omitempty"`
+	Vehicles []*OnboardingVehicle `json:"vehicles"`
+}
+
+// DriverDocument is uploaded document of driver's vehicle with driver
+type DriverDocument struct {
+	DriverID  bson.ObjectId   `json:"driverId" bson:"driverId"`
+	Firstname string          `json:"firstname"`
+	Lastname  string          `json:"lastname"`
+	Email     string          `json:"email"`
+	Number    string          `json:"number"`
+	VehicleID bson.ObjectId   `json:"vehicleId" bson:"vehicleId"`
+	Document  *VerifyDocument `json:"document"`
+}
+
+// DocumentExpiry is reminder or expiry of document that driver is notified
+type DocumentExpiry struct {
+	Number     string        `json:"number"`
+	DocumentID bson.ObjectId `json:"documentId"`
+	Name       string        `json:"name"`
+	ExpireDate int64         `json:"expireDate"`
+	Days       int           `json:"days"` // 0 when document is expired
+}
+
+// ExpirationReport is upcoming expirations of documents of drivers in city
+type ExpirationReport struct {
+	PlaceID   string            `json:"placeId" bson:"_id"`
+	City      string            `json:"city"`
+	Count     int               `json:"count"`
+	Documents []*DriverDocument `json:"documents"`
+}
@@ -38,4 +38,57 @@ type Order struct {
 	UserRate         float32          `json:"userRate" bson:"userRate"`
 	CreatedAt        int64            `json:"createdAt" bson:"createdAt" description:"Created date."`
 	UpdatedAt        int64            `json:"updatedAt" bson:"updatedAt" description:"Updated date."`
+
+	// tagged feedback and rating of driver and business
+	UserTags           []string `json:"userTags,omitempty" bson:"userTags,omitempty"` // tags of user about business and driver
+	DriverRated        bool     `json:"driverRated" bson:"driverRated"`
+	UserFeedback       string   `json:"userFeedback" bson:"userFeedback"` // feedback of driver about user
+	DriverTags         []string `json:"driverTags,omitempty" bson:"driverTags,omitempty"`
+	BusinessRated      bool     `json:"businessRated" bson:"businessRated"`
+	BusinessUserRate   float32  `json:"businessUserRate" bson:"businessUserRate"`
+	BusinessDriverRate float32  `json:"businessDriverRate" bson:"businessDriverRate"`
+	BusinessComment    string   `json:"businessComment" bson:"businessComment"` // feedback of business about user and driver
+	BusinessTags       []string `json:"businessTags,omitempty" bson:"businessTags,omitempty"`
+
+	// payment of order that is authorized at creation
+	Payment *OrderPayment `json:"payment,omitempty" bson:"payment,omitempty"`
+
+	// promotion that discounts order, place is city of order
+	PromoCode string  `json:"promoCode,omitempty" bson:"promoCode,omitempty"`
+	Discount  float64 `json:"discount"`
+	PlaceID   string  `json:"placeId,omitempty" bson:"placeId,omitempty"`
+
+	// tip of driver at checkout or after trip, tip after trip is charged separately
+	Tip        float64       `json:"tip"`
+	TipPercent int           `json:"tipPercent,omitempty" bson:"tipPercent,omitempty"`
+	TipPayment *OrderPayment `json:"tipPayment,omitempty" bson:"tipPayment,omitempty"`
+	TippedAt   int64         `json:"tippedAt,omitempty" bson:"tippedAt,omitempty"`
+
+	// distance in meters and duration in seconds of trip from location history
+	ActualDistance float64 `json:"actualDistance,omitempty" bson:"actualDistance,omitempty"`
+	ActualDuration int64   `json:"actualDuration,omitempty" bson:"actualDuration,omitempty"`
+
+	// geofence that driver goes to and transitions that driver marked far from expected point
+	Geofence  *TripGeofence  `json:"geofence,omitempty" bson:"geofence,omitempty"`
+	Anomalies []*TripAnomaly `json:"anomalies,omitempty" bson:"anomalies,omitempty"`
+
+	// delivery zone that delivery location is resolved to, place of order is city of zone
+	ZoneID bson.ObjectId `json:"zoneId,omitempty" bson:"zoneId,omitempty"`
+
+	// surge of zone that is locked at quote, surge fee is included in booking fee
+	SurgeQuoteID bson.ObjectId `json:"surgeQuoteId,omitempty" bson:"surgeQuoteId,omitempty"`
+	Surge        float64       `json:"surge,omitempty" bson:"surge,omitempty"`
+	SurgeFee     float64       `json:"surgeFee,omitempty" bson:"surgeFee,omitempty"`
+}
+
+// Total returns amount that user pays for order at checkout
+func (p *Order) Total() float64 {
+	total := p.Price + p.Tax + p.BookingFee - p.Discount
+	if total < 0 {
+		total = 0
+	}
+	if p.TipPayment == nil {
+		total += p.Tip
+	}
+	return total
 }
@@ -0,0 +1,84 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// PaymentMethod is tokenized card of user, card number is kept by gateway only
+type PaymentMethod struct {
+	ID        bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	UserID    bson.ObjectId `json:"userId" bson:"userId"`
+	Gateway   string        `json:"gateway"`
+	Token     string        `json:"token"`
+	Brand     string        `json:"brand"`
+	Last4     string        `json:"last4"`
+	ExpMonth  int           `json:"expMonth" bson:"expMonth"`
+	ExpYear   int           `json:"expYear" bson:"expYear"`
+	Default   bool          `json:"default"`
+	CreatedAt int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
+	UpdatedAt int64         `json:"updatedAt" bson:"updatedAt" description:"Updated date."`
+}
+
+// PublicPaymentMethod is payment method that is shown to user
+type PublicPaymentMethod struct {
+	*PaymentMethod
+	Token omit `json:"token,omitempty"`
+}
+
+// PaymentIntent is payment of order on gateway, amounts are in cents
+type PaymentIntent struct {
+	ID             bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	OrderID        bson.ObjectId `json:"orderId" bson:"orderId"`
+	UserID         bson.ObjectId `json:"userId" bson:"userId"`
+	MethodID       bson.ObjectId `json:"methodId" bson:"methodId"`
+	Gateway        string        `json:"gateway"`
+	Reference      string        `json:"reference"` // id of payment on gateway
+	Amount         int64         `json:"amount"`
+	AmountCaptured int64         `json:"amountCaptured" bson:"amountCaptured"`
+	AmountRefunded int64         `json:"amountRefunded" bson:"amountRefunded"`
+	AmountReleased int64         `json:"amountReleased" bson:"amountReleased"` // authorized amount that is not captured
+	Currency       string        `json:"currency"`
+	Status         string        `json:"status"`
+	Error          string        `json:"error,omitempty" bson:"error,omitempty"`
+	CreatedAt      int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
+	UpdatedAt      int64         `json:"updatedAt" bson:"updatedAt" description:"Updated date."`
+}
+
+// OrderPayment is payment state of order, amount is paid with card
+type OrderPayment struct {
+	MethodID bson.ObjectId `json:"methodId,omitempty" bson:"methodId,omitempty"`
+	IntentID bson.ObjectId `json:"intentId,omitempty" bson:"intentId,omitempty"`
+	Amount   int64         `json:"amount"`
+	Refunded int64         `json:"refunded"`
+	Currency string        `json:"currency"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty" bson:"error,omitempty"`
+
+	// amount paid with wallet before card
+	Wallet         int64 `json:"wallet"`
+	WalletRefunded int64 `json:"walletRefunded" bson:"walletRefunded"`
+	Settled        bool  `json:"settled"` // cancellation is refunded
+}
+
+// RefundItem is ordered food that is refunded, index is position in foods of order
+type RefundItem struct {
+	Index int `json:"index"`
+	Count int `json:"count"`
+}
+
+// Refund is entry of refund ledger of order, amount is in cents
+type Refund struct {
+	ID         bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	OrderID    bson.ObjectId `json:"orderId" bson:"orderId"`
+	UserID     bson.ObjectId `json:"userId" bson:"userId"`
+	IntentID   bson.ObjectId `json:"intentId" bson:"intentId"`
+	Kind       string        `json:"kind"`   // RefundCancellation, RefundSupport
+	Method     string        `json:"method"` // RefundRelease, RefundGateway
+	Amount     int64         `json:"amount"`
+	Items      []*RefundItem `json:"items,omitempty" bson:"items,omitempty"`
+	ReasonCode int           `json:"reasonCode,omitempty" bson:"reasonCode,omitempty"`
+	Note       string        `json:"note"`
+	IssuerID   bson.ObjectId `json:"issuerId,omitempty" bson:"issuerId,omitempty"`
+	IssuerRole string        `json:"issuerRole" bson:"issuerRole"`
+	Status     string        `json:"status"` // RefundSucceeded, RefundFailed
+	Error      string        `json:"error,omitempty" bson:"error,omitempty"`
+	CreatedAt  int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
+}
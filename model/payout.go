@@ -0,0 +1,65 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// Commission is platform commission rule of business or city, rule of business precedes rule of city
+type Commission struct {
+	ID         bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	BusinessID bson.ObjectId `json:"businessId,omitempty" bson:"businessId,omitempty"`
+	PlaceID    string        `json:"placeId,omitempty" bson:"placeId,omitempty"`
+	Percent    float64       `json:"percent"`
+	CreatedAt  int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
+	UpdatedAt  int64         `json:"updatedAt" bson:"updatedAt" description:"Updated date."`
+}
+
+// SettlementLine is entry of settlement ledger of business, amounts are in cents
+type SettlementLine struct {
+	ID          bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	BusinessID  bson.ObjectId `json:"businessId" bson:"businessId"`
+	OrderID     bson.ObjectId `json:"orderId" bson:"orderId"`
+	Number      string        `json:"number"` // number of order
+	Kind        string        `json:"kind"`   // SettlementOrder, SettlementRefund
+	Key         string        `json:"-"`
+	Gross       int64         `json:"gross"`
+	Percent     float64       `json:"percent"` // commission percent
+	Commission  int64         `json:"commission"`
+	Fees        int64         `json:"fees"`
+	Refunds     int64         `json:"refunds"`
+	Net         int64         `json:"net"`
+	StatementID bson.ObjectId `json:"statementId,omitempty" bson:"statementId,omitempty"`
+	CreatedAt   int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
+}
+
+// PayoutStatement is weekly payout of business, amounts are in cents
+type PayoutStatement struct {
+	ID          bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	BusinessID  bson.ObjectId `json:"businessId" bson:"businessId"`
+	Name        string        `json:"name"` // name of business
+	BankInfo    BankInfo      `json:"bankInfo" bson:"bankInfo"`
+	BatchID     bson.ObjectId `json:"batchId,omitempty" bson:"batchId,omitempty"`
+	PeriodStart int64         `json:"periodStart" bson:"periodStart"`
+	PeriodEnd   int64         `json:"periodEnd" bson:"periodEnd"`
+	Gross       int64         `json:"gross"`
+	Commission  int64         `json:"commission"`
+	Fees        int64         `json:"fees"`
+	Refunds     int64         `json:"refunds"`
+	Net         int64         `json:"net"`
+	LineCount   int           `json:"lineCount" bson:"lineCount"`
+	Status      string        `json:"status"` // PayoutPending, PayoutHeld, PayoutExported, PayoutPaid
+	Note        string        `json:"note,omitempty" bson:"note,omitempty"`
+	CreatedAt   int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
+	UpdatedAt   int64         `json:"updatedAt" bson:"updatedAt" description:"Updated date."`
+}
+
+// PayoutBatch is bank transfer of statements of week, amount is in cents
+type PayoutBatch struct {
+	ID          bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	PeriodStart int64         `json:"periodStart" bson:"periodStart"`
+	PeriodEnd   int64         `json:"periodEnd" bson:"periodEnd"`
+	Count       int           `json:"count"`
+	Amount      int64         `json:"amount"`
+	Status      string        `json:"status"` // PayoutPending, PayoutExported, PayoutPaid
+	ExportedAt  int64         `json:"exportedAt,omitempty" bson:"exportedAt,omitempty"`
+	PaidAt      int64         `json:"paidAt,omitempty" bson:"paidAt,omitempty"`
+	CreatedAt   int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
+}
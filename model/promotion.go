@@ -0,0 +1,81 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// Promotion is promo code that discounts order
+type Promotion struct {
+	ID             bson.ObjectId   `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	Code           string          `json:"code"`
+	Name           string          `json:"name"`
+	Description    string          `json:"description"`
+	Type           string          `json:"type"`  // PromotionPercent, PromotionFixed, PromotionFreeDelivery
+	Value          float64         `json:"value"` // percent or amount of discount
+	MaxDiscount    float64         `json:"maxDiscount" bson:"maxDiscount"`
+	MinBasket      float64         `json:"minBasket" bson:"minBasket"`
+	BusinessIDs    []bson.ObjectId `json:"businessIds" bson:"businessIds"`
+	DietaryCodes   []int           `json:"dietaryCodes" bson:"dietaryCodes"`
+	PlaceIDs       []string        `json:"placeIds" bson:"placeIds"` // cities of location
+	FirstOrderOnly bool            `json:"firstOrderOnly" bson:"firstOrderOnly"`
+	PerUserLimit   int             `json:"perUserLimit" bson:"perUserLimit"` // 0 is unlimited
+	TotalLimit     int             `json:"totalLimit" bson:"totalLimit"`     // 0 is unlimited
+	RedeemedCount  int             `json:"redeemedCount" bson:"redeemedCount"`
+	StartAt        int64           `json:"startAt" bson:"startAt"`
+	EndAt          int64           `json:"endAt" bson:"endAt"`
+	Status         bool            `json:"status"`
+	CreatedAt      int64           `json:"createdAt" bson:"createdAt" description:"Created date."`
+	UpdatedAt      int64           `json:"updatedAt" bson:"updatedAt" description:"Updated date."`
+
+	// personal promotion is redeemed by user only
+	UserID bson.ObjectId `json:"userId,omitempty" bson:"userId,omitempty"`
+}
+
+// PromotionRedemption is usage of promotion by order
+type PromotionRedemption struct {
+	ID          bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	PromotionID bson.ObjectId `json:"promotionId" bson:"promotionId"`
+	Code        string        `json:"code"`
+	UserID      bson.ObjectId `json:"userId" bson:"userId"`
+	OrderID     bson.ObjectId `json:"orderId" bson:"orderId"`
+	Discount    float64       `json:"discount"`
+	Released    bool          `json:"released"`
+	CreatedAt   int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
+}
+
+// PromotionCart is cart that promotion is applied to
+type PromotionCart struct {
+	UserID       bson.ObjectId
+	BusinessID   bson.ObjectId
+	DietaryCodes []int
+	PlaceID      string
+	Basket       float64
+	BookingFee   float64
+	UserRedeemed int
+	UserOrders   int
+}
+
+// PriceLine is line of price
+type PriceLine struct {
+	Label  string  `json:"label"`
+	Amount float64 `json:"amount"`
+}
+
+// PriceQuote is price of order with discount
+type PriceQuote struct {
+	Subtotal   float64      `json:"subtotal"`
+	Tax        float64      `json:"tax"`
+	BookingFee float64      `json:"bookingFee"`
+	Discount   float64      `json:"discount"`
+	Tip        float64      `json:"tip"`
+	Total      float64      `json:"total"`
+	PromoCode  string       `json:"promoCode,omitempty"`
+	Promotion  *Promotion   `json:"-"`
+	Lines      []*PriceLine `json:"lines"`
+	Error      string       `json:"error,omitempty"` // reason that promo code is not applied
+
+	TipPercents []int `json:"tipPercents"`
+
+	// surge of zone that is locked for order with quote id
+	Surge        float64       `json:"surge,omitempty"`
+	SurgeFee     float64       `json:"surgeFee,omitempty"`
+	SurgeQuoteID bson.ObjectId `json:"surgeQuoteId,omitempty"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// RecentRating is a rating of order that is kept in recent window
+type RecentRating struct {
+	OrderID   bson.ObjectId `json:"orderId" bson:"orderId"`
+	Rate      float32       `json:"rate"`
+	CreatedAt int64         `json:"createdAt" bson:"createdAt"`
+}
+
+// RatingSummary is aggregated rating of business, driver or user
+type RatingSummary struct {
+	ID            bson.ObjectId   `json:"id" bson:"_id" description:"Business, driver or user ID"`
+	SubjectType   string          `json:"subjectType" bson:"subjectType"` // business, driver, user
+	Count         int             `json:"count"`
+	Total         float64         `json:"total"`
+	Average       float32         `json:"average"`
+	Distribution  map[string]int  `json:"distribution"` // count of each star
+	Recent        []*RecentRating `json:"recent"`
+	RecentCount   int             `json:"recentCount" bson:"recentCount"`
+	RecentAverage float32         `json:"recentAverage" bson:"recentAverage"`
+	UpdatedAt     int64           `json:"updatedAt" bson:"updatedAt"`
+}
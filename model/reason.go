@@ -11,4 +11,15 @@ type Reason struct {
 	Status    bool          `json:"status"`
 	CreatedAt int64         `json:"createdAt" bson:"createdAt"`
 	UpdatedAt int64         `json:"updatedAt" bson:"updatedAt"`
+
+	// refund rule of order that is declined or cancelled with reason
+	RefundPolicy *RefundPolicy `json:"refundPolicy,omitempty" bson:"refundPolicy,omitempty"`
+}
+
+// RefundPolicy decides refund of cancelled order, late policy is used after order is accepted
+type RefundPolicy struct {
+	Type        string `json:"type"` // RefundFull, RefundFeeOnly, RefundPercent, RefundNone
+	Percent     int    `json:"percent"`
+	LateType    string `json:"lateType" bson:"lateType"`
+	LatePercent int    `json:"latePercent" bson:"latePercent"`
 }
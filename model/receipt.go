@@ -0,0 +1,61 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// ReceiptRecord is issued receipt of completed order, invoice number is sequential per business
+type ReceiptRecord struct {
+	ID            bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	OrderID       bson.ObjectId `json:"orderId" bson:"orderId"`
+	BusinessID    bson.ObjectId `json:"businessId" bson:"businessId"`
+	InvoiceNumber string        `json:"invoiceNumber,omitempty" bson:"invoiceNumber,omitempty"`
+	Sequence      int64         `json:"sequence,omitempty" bson:"sequence,omitempty"`
+	IssuedAt      int64         `json:"issuedAt" bson:"issuedAt"`
+	EmailedAt     int64         `json:"emailedAt,omitempty" bson:"emailedAt,omitempty"`
+}
+
+// ReceiptItem is ordered food of receipt, price is line price in cents
+type ReceiptItem struct {
+	Name    string   `json:"name"`
+	Options []string `json:"options,omitempty"`
+	Note    string   `json:"note,omitempty"`
+	Count   int      `json:"count"`
+	Price   int64    `json:"price"`
+}
+
+// ReceiptRefund is refund of receipt, amount is in cents
+type ReceiptRefund struct {
+	Amount    int64  `json:"amount"`
+	Method    string `json:"method"`
+	Note      string `json:"note,omitempty"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+// Receipt is receipt of order, amounts are in cents
+type Receipt struct {
+	OrderID        bson.ObjectId    `json:"orderId"`
+	UserID         bson.ObjectId    `json:"userId"`
+	BusinessID     bson.ObjectId    `json:"businessId"`
+	Number         string           `json:"number"` // number of order
+	InvoiceNumber  string           `json:"invoiceNumber,omitempty"`
+	IssuedAt       int64            `json:"issuedAt"`
+	BusinessName   string           `json:"businessName"`
+	Identification string           `json:"identification"` // legal identity of business
+	BusinessAddr   string           `json:"businessAddress"`
+	CustomerName   string           `json:"customerName"`
+	CustomerEmail  string           `json:"customerEmail"`
+	DeliveryAddr   string           `json:"deliveryAddress"`
+	Items          []*ReceiptItem   `json:"items"`
+	Subtotal       int64            `json:"subtotal"`
+	Tax            int64            `json:"tax"`
+	BookingFee     int64            `json:"bookingFee"`
+	Discount       int64            `json:"discount"`
+	PromoCode      string           `json:"promoCode,omitempty"`
+	Tip            int64            `json:"tip"`
+	Total          int64            `json:"total"`
+	Wallet         int64            `json:"wallet"`
+	Card           int64            `json:"card"`
+	PaymentMethod  string           `json:"paymentMethod"`
+	Refunds        []*ReceiptRefund `json:"refunds"`
+	Refunded       int64            `json:"refunded"`
+	Currency       string           `json:"currency"`
+}
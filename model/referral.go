@@ -0,0 +1,29 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// Referral is invitation of user by referral code, rewards are in cents
+type Referral struct {
+	ID             bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	Code           string        `json:"code"`
+	ReferrerID     bson.ObjectId `json:"referrerId" bson:"referrerId"`
+	RefereeID      bson.ObjectId `json:"refereeId" bson:"refereeId"`
+	Status         string        `json:"status"` // ReferralPending, ReferralRewarded, ReferralRejected
+	RejectReason   string        `json:"rejectReason,omitempty" bson:"rejectReason,omitempty"`
+	RewardType     string        `json:"rewardType,omitempty" bson:"rewardType,omitempty"`
+	ReferrerReward int64         `json:"referrerReward" bson:"referrerReward"`
+	RefereeReward  int64         `json:"refereeReward" bson:"refereeReward"`
+	OrderID        bson.ObjectId `json:"orderId,omitempty" bson:"orderId,omitempty"`
+	RewardedAt     int64         `json:"rewardedAt,omitempty" bson:"rewardedAt,omitempty"`
+	CreatedAt      int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
+}
+
+// ReferralReport is referral result of referrer
+type ReferralReport struct {
+	ReferrerID bson.ObjectId `json:"referrerId" bson:"_id"`
+	Referrer   *PublicUser   `json:"referrer,omitempty" bson:"-"`
+	Pending    int           `json:"pending"`
+	Rewarded   int           `json:"rewarded"`
+	Rejected   int           `json:"rejected"`
+	Reward     int64         `json:"reward"`
+}
@@ -0,0 +1,50 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// ReviewReply is reply of business to review
+type ReviewReply struct {
+	Content   string `json:"content"`
+	CreatedAt int64  `json:"createdAt" bson:"createdAt"`
+	UpdatedAt int64  `json:"updatedAt" bson:"updatedAt"`
+}
+
+// ReviewFlag is report of review
+type ReviewFlag struct {
+	ClientID  bson.ObjectId `json:"clientId" bson:"clientId"`
+	Role      string        `json:"role"`
+	Reason    string        `json:"reason"`
+	CreatedAt int64         `json:"createdAt" bson:"createdAt"`
+}
+
+// Review is written review of customer about business
+type Review struct {
+	ID                bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	OrderID           bson.ObjectId `json:"orderId" bson:"orderId"`
+	BusinessID        bson.ObjectId `json:"businessId" bson:"businessId"`
+	UserID            bson.ObjectId `json:"userId" bson:"userId"`
+	UserName          string        `json:"userName" bson:"userName"`
+	UserAvatar        string        `json:"userAvatar" bson:"userAvatar"`
+	Rate              float32       `json:"rate"`
+	Content           string        `json:"content"`
+	Reply             *ReviewReply  `json:"reply,omitempty" bson:"reply,omitempty"`
+	Status            string        `json:"status"` // ReviewPending, ReviewPublished, ReviewRejected
+	FilterReasons     []string      `json:"filterReasons,omitempty" bson:"filterReasons,omitempty"`
+	Flags             []*ReviewFlag `json:"flags,omitempty" bson:"flags,omitempty"`
+	FlagCount         int           `json:"flagCount" bson:"flagCount"`
+	ModeratorID       bson.ObjectId `json:"moderatorId,omitempty" bson:"moderatorId,omitempty"`
+	ModerationComment string        `json:"moderationComment,omitempty" bson:"moderationComment,omitempty"`
+	ModeratedAt       int64         `json:"moderatedAt,omitempty" bson:"moderatedAt,omitempty"`
+	EditableUntil     int64         `json:"editableUntil" bson:"editableUntil"`
+	CreatedAt         int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
+	UpdatedAt         int64         `json:"updatedAt" bson:"updatedAt" description:"Updated date."`
+}
+
+// PublicReview is review that is shown to customers
+type PublicReview struct {
+	*Review
+	Flags             omit `json:"flags,omitempty"`
+	FilterReasons     omit `json:"filterReasons,omitempty"`
+	ModeratorID       omit `json:"moderatorId,omitempty"`
+	ModerationComment omit `json:"moderationComment,omitempty"`
+}
@@ -0,0 +1,11 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// Suggestion is autocomplete item of search
+type Suggestion struct {
+	Type string        `json:"type"` // business, food, dietary, mealKind
+	ID   bson.ObjectId `json:"id"`
+	Code int           `json:"code,omitempty"`
+	Text string        `json:"text"`
+}
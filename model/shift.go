@@ -0,0 +1,45 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// ShiftSlot is shift in zone of city that admin publishes for drivers to book
+type ShiftSlot struct {
+	ID        bson.ObjectId `json:"id" bson:"_id,omitempty"`
+	PlaceID   string        `json:"placeId" bson:"placeId"` // city of shift
+	Zone      string        `json:"zone"`
+	Center    *GeoJSON      `json:"center,omitempty" bson:"center,omitempty"` // center of zone, whole city when empty
+	Radius    float64       `json:"radius,omitempty" bson:"radius,omitempty"` // meters
+	Start     int64         `json:"start"`
+	End       int64         `json:"end"`
+	Capacity  int           `json:"capacity"`
+	Booked    int           `json:"booked"`
+	Forecast  int           `json:"forecast,omitempty" bson:"forecast,omitempty"` // orders that admin expects, past weeks are used when empty
+	CreatedAt int64         `json:"createdAt" bson:"createdAt"`
+	UpdatedAt int64         `json:"updatedAt" bson:"updatedAt"`
+}
+
+// ShiftBooking is shift that driver booked
+type ShiftBooking struct {
+	ID          bson.ObjectId `json:"id" bson:"_id,omitempty"`
+	SlotID      bson.ObjectId `json:"slotId" bson:"slotId"`
+	DriverID    bson.ObjectId `json:"driverId" bson:"driverId"`
+	PlaceID     string        `json:"placeId" bson:"placeId"`
+	Zone        string        `json:"zone"`
+	Start       int64         `json:"start"`
+	End         int64         `json:"end"`
+	Status      string        `json:"status"`
+	CheckedInAt int64         `json:"checkedInAt,omitempty" bson:"checkedInAt,omitempty"`
+	CreatedAt   int64         `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   int64         `json:"updatedAt" bson:"updatedAt"`
+}
+
+// ShiftCoverage is supply of drivers against forecasted demand of shift
+type ShiftCoverage struct {
+	Slot     *ShiftSlot `json:"slot"`
+	Booked   int        `json:"booked"`
+	Attended int        `json:"attended"`
+	NoShow   int        `json:"noShow"`
+	Demand   int        `json:"demand"` // forecasted orders
+	Needed   int        `json:"needed"` // drivers that demand needs
+	Gap      int        `json:"gap"`    // needed drivers that are not booked, negative when oversupplied
+}
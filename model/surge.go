@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Surge is current surge of delivery zone
+type Surge struct {
+	ZoneID        bson.ObjectId `json:"zoneId" bson:"_id"`
+	Zone          string        `json:"zone"`
+	PlaceID       string        `json:"placeId" bson:"placeId"`
+	Demand        int           `json:"demand"` // open orders
+	Supply        int           `json:"supply"` // online drivers
+	Raw           float64       `json:"raw"`    // multiplier before smoothing
+	Multiplier    float64       `json:"multiplier"`
+	Override      float64       `json:"override,omitempty" bson:"override,omitempty"` // multiplier of admin
+	OverrideUntil int64         `json:"overrideUntil,omitempty" bson:"overrideUntil,omitempty"`
+	UpdatedAt     int64         `json:"updatedAt" bson:"updatedAt"`
+}
+
+// SurgeHistory is surge of zone at time
+type SurgeHistory struct {
+	ID         bson.ObjectId `json:"id" bson:"_id,omitempty"`
+	ZoneID     bson.ObjectId `json:"zoneId" bson:"zoneId"`
+	Demand     int           `json:"demand"`
+	Supply     int           `json:"supply"`
+	Raw        float64       `json:"raw"`
+	Multiplier float64       `json:"multiplier"` // multiplier that was charged
+	At         int64         `json:"at"`
+	Date       time.Time     `json:"-"` // for expiry of history
+}
+
+// SurgeSetting is global switch of surge
+type SurgeSetting struct {
+	ID        string        `json:"-" bson:"_id"`
+	Enabled   bool          `json:"enabled"`
+	AdminID   bson.ObjectId `json:"adminId,omitempty" bson:"adminId,omitempty"`
+	UpdatedAt int64         `json:"updatedAt" bson:"updatedAt"`
+}
+
+// SurgeQuote is multiplier that is locked for order of quote
+type SurgeQuote struct {
+	ID         bson.ObjectId `json:"id" bson:"_id"`
+	ZoneID     bson.ObjectId `json:"zoneId" bson:"zoneId"`
+	Multiplier float64       `json:"multiplier"`
+	ExpiresAt  int64         `json:"expiresAt" bson:"expiresAt"`
+	Used       bool          `json:"used"`
+	Date       time.Time     `json:"-"` // for expiry of quote
+}
+
+// SurgeOverride is multiplier that admin sets for zone
+type SurgeOverride struct {
+	Multiplier float64 `json:"multiplier"`
+	Until      int64   `json:"until"` // zero keeps override until it is cleared
+}
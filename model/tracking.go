@@ -0,0 +1,35 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// TrackingDriver is driver that customer sees on tracking of order
+type TrackingDriver struct {
+	Firstname string  `json:"firstname"`
+	Avatar    string  `json:"avatar"`
+	Rating    float32 `json:"rating"`
+	Vehicle   string  `json:"vehicle,omitempty"` // brand, model and color
+	Number    string  `json:"number,omitempty"`  // plate of vehicle
+}
+
+// Tracking is live state of order, location is shown only while driver is on trip
+type Tracking struct {
+	OrderID     bson.ObjectId   `json:"orderId"`
+	Number      string          `json:"number"`
+	OrderStatus string          `json:"orderStatus"`
+	TripStatus  string          `json:"tripStatus"`
+	Driver      *TrackingDriver `json:"driver,omitempty"`
+	Location    *GeoJSON        `json:"location,omitempty"`
+	LocationAt  int64           `json:"locationAt,omitempty"`
+	Destination *GeoJSON        `json:"destination,omitempty"`
+	Distance    float64         `json:"distance,omitempty"` // meters left to destination
+	ETA         int64           `json:"eta,omitempty"`      // seconds left to destination
+	Active      bool            `json:"active"`
+	UpdatedAt   int64           `json:"updatedAt"`
+}
+
+// TrackingShare is tracking link that user shares to recipient of order
+type TrackingShare struct {
+	Token     string `json:"token"`
+	URL       string `json:"url"`
+	ExpiresAt int64  `json:"expiresAt"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// LocationPoint is location of driver on trip of order
+type LocationPoint struct {
+	ID         bson.ObjectId `json:"id" bson:"_id,omitempty"`
+	DriverID   bson.ObjectId `json:"driverId" bson:"driverId"`
+	OrderID    bson.ObjectId `json:"orderId" bson:"orderId"`
+	TripStatus string        `json:"tripStatus" bson:"tripStatus"`
+	Location   GeoJSON       `json:"location"`
+	At         int64         `json:"at"`
+	Date       time.Time     `json:"-"` // for expiry of history
+}
+
+// LineString is geojson line string
+type LineString struct {
+	Type        string      `json:"type"`
+	Coordinates [][]float64 `json:"coordinates"`
+}
+
+// TrailProperties is properties of trail of trip, distance is in meters and duration in seconds
+type TrailProperties struct {
+	OrderID  bson.ObjectId `json:"orderId"`
+	Distance float64       `json:"distance"`
+	Duration int64         `json:"duration"`
+	Points   int           `json:"points"` // recorded points before simplification
+}
+
+// TripTrail is geojson feature of route that driver went on trip
+type TripTrail struct {
+	Type       string           `json:"type"`
+	Geometry   *LineString      `json:"geometry"`
+	Properties *TrailProperties `json:"properties"`
+}
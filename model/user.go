@@ -22,6 +22,7 @@ type User struct {
 	Phone             string          `json:"phone"`
 	PromoCode         string          `json:"promoCode" bson:"promoCode"`
 	Rating            float32         `json:"rating"`
+	RatingCount       int             `json:"ratingCount" bson:"ratingCount"`
 	Status            bool            `json:"status"`
 	Verify            *Verify         `json:"verify,omitempty" bson:"verify,omitempty"`
 	OneSignalPlayerID string          `json:"onesignalPlayerId,omitempty" bson:"onesignalPlayerId,omitempty"`
@@ -33,6 +34,11 @@ type User struct {
 	WorkLocation      GeoLocation     `json:"workLocation" bson:"workLocation"`
 	CreatedAt         int64           `json:"createdAt" bson:"createdAt" description:"User created date"`
 	UpdatedAt         int64           `json:"updatedAt" bson:"updatedAt" description:"User updated date. This field will be updated when any update operation will be occured"`
+
+	// referral code that is entered at signup and device of signup
+	ReferralCode string        `json:"referralCode,omitempty" bson:"referralCode,omitempty"`
+	ReferredBy   bson.ObjectId `json:"referredBy,omitempty" bson:"referredBy,omitempty"`
+	DeviceID     string        `json:"deviceId,omitempty" bson:"deviceId,omitempty"`
 }
 
 // PublicUser struct.
@@ -0,0 +1,35 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// WalletTransaction is entry of append-only wallet ledger of user, amount is in cents
+type WalletTransaction struct {
+	ID        bson.ObjectId `json:"id,omitempty" bson:"_id,omitempty" description:"Object ID"`
+	UserID    bson.ObjectId `json:"userId" bson:"userId"`
+	Seq       int64         `json:"seq"`
+	Source    string        `json:"source"` // WalletRefund, WalletPromotion, WalletReferral, WalletGrant, WalletOrder, WalletReversal, WalletExpiry
+	Amount    int64         `json:"amount"` // positive for credit, negative for debit
+	Balance   int64         `json:"balance"`
+	ExpiresAt int64         `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+	CreditID  bson.ObjectId `json:"creditId,omitempty" bson:"creditId,omitempty"` // credit that is expired
+	OrderID   bson.ObjectId `json:"orderId,omitempty" bson:"orderId,omitempty"`
+	Key       string        `json:"-" bson:"key,omitempty"` // idempotency key
+	Note      string        `json:"note"`
+	IssuerID  bson.ObjectId `json:"issuerId,omitempty" bson:"issuerId,omitempty"`
+	CreatedAt int64         `json:"createdAt" bson:"createdAt" description:"Created date."`
+}
+
+// Wallet is balance of user that is derived from ledger
+type Wallet struct {
+	UserID   bson.ObjectId `json:"userId"`
+	Balance  int64         `json:"balance"`
+	Currency string        `json:"currency"`
+	Expiring []*WalletLot  `json:"expiring"`
+}
+
+// WalletLot is remaining amount of credit
+type WalletLot struct {
+	CreditID  bson.ObjectId `json:"creditId"`
+	Amount    int64         `json:"amount"`
+	ExpiresAt int64         `json:"expiresAt,omitempty"`
+}
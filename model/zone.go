@@ -0,0 +1,28 @@
+package model
+
+import "gopkg.in/mgo.v2/bson"
+
+// Polygon is geojson polygon, first ring is boundary and others are holes
+type Polygon struct {
+	Type        string        `json:"type"`
+	Coordinates [][][]float64 `json:"coordinates"`
+}
+
+// DeliveryZone is service area or exclusion zone that admin draws in city
+type DeliveryZone struct {
+	ID         bson.ObjectId `json:"id" bson:"_id,omitempty"`
+	LocationID bson.ObjectId `json:"locationId" bson:"locationId"`
+	PlaceID    string        `json:"placeId" bson:"placeId"` // place id of location
+	Name       string        `json:"name"`
+	Kind       string        `json:"kind"` // service or exclusion
+	Area       Polygon       `json:"area"`
+	Active     bool          `json:"active"`
+	CreatedAt  int64         `json:"createdAt" bson:"createdAt"`
+	UpdatedAt  int64         `json:"updatedAt" bson:"updatedAt"`
+}
+
+// DeliveryArea is area that business delivers to, radius around business is used without polygon
+type DeliveryArea struct {
+	Area   *Polygon `json:"area,omitempty" bson:"area,omitempty"`
+	Radius float64  `json:"radius,omitempty" bson:"radius,omitempty"` // meters
+}
@@ -104,6 +104,7 @@ func UpdateBusiness(objid bson.ObjectId, business *model.Business) (*model.Busin
 		"description":       business.Description,
 		"priceLevel":        business.PriceLevel,
 		"preparationTime":   business.PreparationTime,
+		"freeDelivery":      business.FreeDelivery,
 		"geoLocation":       business.GeoLocation,
 		"bankInfo":          business.BankInfo,
 		"dietaryCodes":      business.DietaryCodes,
@@ -125,6 +126,23 @@ func UpdateBusiness(objid bson.ObjectId, business *model.Business) (*model.Busin
 	return business, err
 }
 
+// UpdateDeliveryArea updates area that business delivers to
+func UpdateDeliveryArea(objid bson.ObjectId, area *model.DeliveryArea) (*model.Business, error) {
+	businessCollection, session := businessCollection()
+	defer session.Close()
+
+	business := &model.Business{}
+	change := mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"deliveryArea": area,
+			"updatedAt":    timeHelper.GetCurrentTime(),
+		}},
+		ReturnNew: true,
+	}
+	_, err := businessCollection.FindId(objid).Apply(change, business)
+	return business, err
+}
+
 // DeleteBusiness deletes business with object id
 func DeleteBusiness(objid bson.ObjectId) error {
 	businessCollection, session := businessCollection()
@@ -175,88 +193,8 @@ func RetrieveBusinessBaseStructure(b *model.PublicBusiness) {
 	b.MealKinds = mealKindService.ReadMealKindsWithCodes(b.MealKindCodes)
 }
 
-// ReadNearbyPopularBusiness returns nearby popular business
-func ReadNearbyPopularBusiness(lat, lng float64) ([]*model.PublicBusiness, error) {
-	businessCollection, session := businessCollection()
-	defer session.Close()
-
-	businesses := []*model.PublicBusiness{}
-	err := businessCollection.Pipe([]bson.M{
-		{"$geoNear": bson.M{
-			"near":          bson.M{"type": "Point", "coordinates": []float64{lng, lat}},
-			"distanceField": "distance",
-			"maxDistance":   10000,
-			"query":         bson.M{"closed": false, "mostPopular": true},
-			"includeLocs":   "geoLocation.geoJson",
-			"num":           10,
-			"spherical":     true,
-		}},
-	}).All(&businesses)
-	return businesses, err
-}
-
-// ReadRecommendBusiness read recommend business
-func ReadRecommendBusiness(lat, lng float64) ([]*model.PublicBusiness, error) {
-	businessCollection, session := businessCollection()
-	defer session.Close()
-
-	businesses := []*model.PublicBusiness{}
-	err := businessCollection.Pipe([]bson.M{
-		{"$geoNear": bson.M{
-			"near":          bson.M{"type": "Point", "coordinates": []float64{lng, lat}},
-			"distanceField": "distance",
-			"maxDistance":   10000,
-			"query":         bson.M{"closed": false, "recommend": true},
-			"includeLocs":   "geoLocation.geoJson",
-			"num":           10,
-			"spherical":     true,
-		}},
-	}).All(&businesses)
-	return businesses, err
-}
-
-// ReadUnder30Business returns businesses under 30mins
-func ReadUnder30Business(lat, lng float64) ([]*model.PublicBusiness, error) {
-	businessCollection, session := businessCollection()
-	defer session.Close()
-
-	businesses := []*model.PublicBusiness{}
-	err := businessCollection.Pipe([]bson.M{
-		{"$geoNear": bson.M{
-			"near":          bson.M{"type": "Point", "coordinates": []float64{lng, lat}},
-			"distanceField": "distance",
-			"maxDistance":   10000,
-			"query":         bson.M{"closed": false, "mostPopular": false, "recommend": false},
-			"includeLocs":   "geoLocation.geoJson",
-			"num":           10,
-			"spherical":     true,
-		}},
-	}).All(&businesses)
-	return businesses, err
-}
-
-// ReadMoreBusiness returns other
-func ReadMoreBusiness(lat, lng float64, excepts []bson.ObjectId) ([]*model.PublicBusiness, error) {
-	businessCollection, session := businessCollection()
-	defer session.Close()
-
-	businesses := []*model.PublicBusiness{}
-	err := businessCollection.Pipe([]bson.M{
-		{"$geoNear": bson.M{
-			"near":          bson.M{"type": "Point", "coordinates": []float64{lng, lat}},
-			"distanceField": "distance",
-			"maxDistance":   10000,
-			"query":         bson.M{"_id": bson.M{"$nin": excepts}},
-			"includeLocs":   "geoLocation.geoJson",
-			"num":           100,
-			"spherical":     true,
-		}},
-	}).All(&businesses)
-	return businesses, err
-}
-
-// ReadQueryBusiness returns other
-func ReadQueryBusiness(queryBusiness *model.QueryBusiness) ([]*model.PublicBusiness, error) {
+// ReadQueryBusiness returns businesses in radius
+func ReadQueryBusiness(queryBusiness *model.QueryBusiness, radius float64) ([]*model.PublicBusiness, error) {
 	businessCollection, session := businessCollection()
 	defer session.Close()
 
@@ -278,7 +216,7 @@ func ReadQueryBusiness(queryBusiness *model.QueryBusiness) ([]*model.PublicBusin
 		{"$geoNear": bson.M{
 			"near":          bson.M{"type": "Point", "coordinates": []float64{queryBusiness.Lng, queryBusiness.Lat}},
 			"distanceField": "distance",
-			"maxDistance":   10000,
+			"maxDistance":   radius,
 			"query":         query,
 			"includeLocs":   "geoLocation.geoJson",
 			"num":           100,
@@ -289,6 +227,10 @@ func ReadQueryBusiness(queryBusiness *model.QueryBusiness) ([]*model.PublicBusin
 		pipe = append(pipe, bson.M{
 			"$sort": bson.M{"distance": 1},
 		})
+	} else if queryBusiness.Sort == config.SortRating {
+		pipe = append(pipe, bson.M{
+			"$sort": bson.M{"rating": -1},
+		})
 	}
 
 	err := businessCollection.Pipe(pipe).All(&businesses)
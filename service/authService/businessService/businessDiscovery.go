@@ -0,0 +1,277 @@
+package businessService
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"../../../config"
+	"../../../model"
+	"../../../util/timeHelper"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	ratingFacets = []float64{3, 4, 4.5}
+	etaFacets    = []float64{15, 30, 45, 60}
+)
+
+// discoveryCursor is last item of page, next page starts after it
+type discoveryCursor struct {
+	Value float64 `json:"v"`
+	ID    string  `json:"id"`
+}
+
+// discoveryFacetResult is result of $facet stage
+type discoveryFacetResult struct {
+	Items    []*model.PublicBusiness `bson:"items"`
+	Total    []struct{ Count int }   `bson:"total"`
+	Price    []*model.FacetCount     `bson:"price"`
+	Dietary  []*model.FacetCount     `bson:"dietary"`
+	MealKind []*model.FacetCount     `bson:"mealKind"`
+	Summary  []map[string]int        `bson:"summary"`
+}
+
+// DiscoverBusinesses returns a page of nearby businesses with facet counts
+func DiscoverBusinesses(query *model.DiscoveryQuery, radius float64) (*model.DiscoveryResult, error) {
+	businessCollection, session := businessCollection()
+	defer session.Close()
+
+	field, direction := discoverySortField(query.Sort)
+	count := query.Count
+	if count <= 0 {
+		count = config.DiscoverCount
+	}
+	items := []bson.M{}
+	if query.Cursor != "" {
+		cursor, err := decodeDiscoveryCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, bson.M{"$match": cursorMatch(field, direction, cursor)})
+	}
+	items = append(items,
+		bson.M{"$sort": bson.D{{Name: field, Value: direction}, {Name: "_id", Value: 1}}},
+		bson.M{"$limit": count + 1},
+	)
+
+	pipe := []bson.M{
+		{"$geoNear": bson.M{
+			"near":          bson.M{"type": "Point", "coordinates": []float64{query.Lng, query.Lat}},
+			"distanceField": "distance",
+			"maxDistance":   radius,
+			"query":         discoveryMatch(query),
+			"includeLocs":   "geoLocation.geoJson",
+			"num":           config.DiscoverLimit,
+			"spherical":     true,
+		}},
+		{"$addFields": bson.M{
+			"rating":     bson.M{"$ifNull": []interface{}{"$rating", 0}},
+			"orderCount": bson.M{"$ifNull": []interface{}{"$orderCount", 0}},
+			"eta": bson.M{"$add": []interface{}{
+				bson.M{"$ifNull": []interface{}{"$preparationTime", 0}},
+				bson.M{"$divide": []interface{}{"$distance", config.DeliverySpeed}},
+			}},
+			"openNow": openNowExpression(time.Now()),
+		}},
+	}
+	if query.OpenNow {
+		pipe = append(pipe, bson.M{"$match": bson.M{"openNow": true}})
+	}
+	if query.MaxETA > 0 {
+		pipe = append(pipe, bson.M{"$match": bson.M{"eta": bson.M{"$lte": query.MaxETA}}})
+	}
+	pipe = append(pipe, bson.M{"$facet": bson.M{
+		"items":    items,
+		"total":    []bson.M{{"$count": "count"}},
+		"price":    facetPipe("$priceLevel", false),
+		"dietary":  facetPipe("$dietaryCodes", true),
+		"mealKind": facetPipe("$mealKindCodes", true),
+		"summary":  summaryPipe(),
+	}})
+
+	facet := &discoveryFacetResult{}
+	if err := businessCollection.Pipe(pipe).One(facet); err != nil {
+		return nil, err
+	}
+
+	result := &model.DiscoveryResult{
+		Items: facet.Items,
+		Facets: &model.DiscoveryFacets{
+			Price:     facet.Price,
+			Dietary:   facet.Dietary,
+			MealKind:  facet.MealKind,
+			MinRating: []*model.FacetCount{},
+			MaxETA:    []*model.FacetCount{},
+		},
+	}
+	if len(facet.Total) > 0 {
+		result.Total = facet.Total[0].Count
+	}
+	if len(result.Items) > count {
+		result.Items = result.Items[:count]
+		last := result.Items[count-1]
+		result.NextCursor = encodeDiscoveryCursor(&discoveryCursor{
+			Value: discoverySortValue(field, last),
+			ID:    last.ID.Hex(),
+		})
+	}
+	summary := map[string]int{}
+	if len(facet.Summary) > 0 {
+		summary = facet.Summary[0]
+	}
+	result.Facets.OpenNow = summary["openNow"]
+	result.Facets.FreeDelivery = summary["freeDelivery"]
+	for i, value := range ratingFacets {
+		result.Facets.MinRating = append(result.Facets.MinRating, &model.FacetCount{Value: value, Count: summary["rating"+strconv.Itoa(i)]})
+	}
+	for i, value := range etaFacets {
+		result.Facets.MaxETA = append(result.Facets.MaxETA, &model.FacetCount{Value: value, Count: summary["eta"+strconv.Itoa(i)]})
+	}
+	return result, nil
+}
+
+// IncreaseOrderCount increases completed order count that is used for popularity
+func IncreaseOrderCount(objid bson.ObjectId) error {
+	businessCollection, session := businessCollection()
+	defer session.Close()
+
+	return businessCollection.UpdateId(objid, bson.M{"$inc": bson.M{"orderCount": 1}})
+}
+
+func discoveryMatch(query *model.DiscoveryQuery) bson.M {
+	// closed business is not discovered
+	match := bson.M{"closed": bson.M{"$ne": true}}
+	if len(query.Price) > 0 {
+		match["priceLevel"] = bson.M{"$in": query.Price}
+	}
+	if len(query.Dietary) > 0 {
+		match["dietaryCodes"] = bson.M{"$in": query.Dietary}
+	}
+	if len(query.MealKind) > 0 {
+		match["mealKindCodes"] = bson.M{"$in": query.MealKind}
+	}
+	if query.FreeDelivery {
+		match["freeDelivery"] = true
+	}
+	if query.MinRating > 0 {
+		match["rating"] = bson.M{"$gte": query.MinRating}
+	}
+	if query.MostPopular {
+		match["mostPopular"] = true
+	}
+	if query.Recommend {
+		match["recommend"] = true
+	}
+	if len(query.Exclude) > 0 {
+		match["_id"] = bson.M{"$nin": query.Exclude}
+	}
+	return match
+}
+
+// openNowExpression is true when business is not closed and today's schedule contains now
+func openNowExpression(now time.Time) bson.M {
+	scheduleTime := timeHelper.ScheduleTime(now)
+	return bson.M{"$and": []interface{}{
+		bson.M{"$ne": []interface{}{"$closed", true}},
+		bson.M{"$anyElementTrue": []interface{}{bson.M{"$map": bson.M{
+			"input": bson.M{"$ifNull": []interface{}{"$schedules", []interface{}{}}},
+			"as":    "s",
+			"in": bson.M{"$and": []interface{}{
+				bson.M{"$eq": []interface{}{"$$s.weekday", int(now.Weekday())}},
+				"$$s.enabled",
+				bson.M{"$lte": []interface{}{"$$s.opentime", scheduleTime}},
+				bson.M{"$gte": []interface{}{"$$s.closetime", scheduleTime}},
+			}},
+		}}}},
+	}}
+}
+
+func facetPipe(field string, array bool) []bson.M {
+	pipe := []bson.M{}
+	if array {
+		pipe = append(pipe, bson.M{"$unwind": field})
+	}
+	return append(pipe,
+		bson.M{"$group": bson.M{"_id": field, "count": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.M{"_id": 1}},
+	)
+}
+
+func summaryPipe() []bson.M {
+	countIf := func(condition bson.M) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": []interface{}{condition, 1, 0}}}
+	}
+	group := bson.M{
+		"_id":          nil,
+		"openNow":      countIf(bson.M{"$eq": []interface{}{"$openNow", true}}),
+		"freeDelivery": countIf(bson.M{"$eq": []interface{}{"$freeDelivery", true}}),
+	}
+	for i, value := range ratingFacets {
+		group["rating"+strconv.Itoa(i)] = countIf(bson.M{"$gte": []interface{}{"$rating", value}})
+	}
+	for i, value := range etaFacets {
+		group["eta"+strconv.Itoa(i)] = countIf(bson.M{"$lte": []interface{}{"$eta", value}})
+	}
+	return []bson.M{
+		{"$group": group},
+		{"$project": bson.M{"_id": 0}},
+	}
+}
+
+func discoverySortField(sort string) (string, int) {
+	switch sort {
+	case config.DiscoverRating:
+		return "rating", -1
+	case config.DiscoverPopularity:
+		return "orderCount", -1
+	case config.DiscoverETA:
+		return "eta", 1
+	}
+	return "distance", 1
+}
+
+func discoverySortValue(field string, b *model.PublicBusiness) float64 {
+	switch field {
+	case "rating":
+		return float64(b.Rating)
+	case "orderCount":
+		return float64(b.OrderCount)
+	case "eta":
+		return b.ETA
+	}
+	return b.Distance
+}
+
+// cursorMatch returns items that come after cursor in sort order of field and id
+func cursorMatch(field string, direction int, cursor *discoveryCursor) bson.M {
+	operator := "$gt"
+	if direction < 0 {
+		operator = "$lt"
+	}
+	id := bson.ObjectIdHex(cursor.ID)
+	return bson.M{"$or": []bson.M{
+		{field: bson.M{operator: cursor.Value}},
+		{field: cursor.Value, "_id": bson.M{"$gt": id}},
+	}}
+}
+
+func encodeDiscoveryCursor(cursor *discoveryCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeDiscoveryCursor(value string) (*discoveryCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.New("Cursor is invalid")
+	}
+	cursor := &discoveryCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil || !bson.IsObjectIdHex(cursor.ID) {
+		return nil, errors.New("Cursor is invalid")
+	}
+	return cursor, nil
+}
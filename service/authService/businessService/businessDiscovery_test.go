@@ -0,0 +1,34 @@
+package businessService
+
+import (
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestDiscoveryCursor(t *testing.T) {
+	cursor := &discoveryCursor{Value: 1234.5, ID: bson.NewObjectId().Hex()}
+	decoded, err := decodeDiscoveryCursor(encodeDiscoveryCursor(cursor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *decoded != *cursor {
+		t.Errorf("expected %v, got %v", cursor, decoded)
+	}
+
+	if _, err := decodeDiscoveryCursor("invalid"); err == nil {
+		t.Error("expected error for invalid cursor")
+	}
+}
+
+func TestCursorMatch(t *testing.T) {
+	cursor := &discoveryCursor{Value: 4.5, ID: bson.NewObjectId().Hex()}
+	match := cursorMatch("rating", -1, cursor)
+	or := match["$or"].([]bson.M)
+	if _, ok := or[0]["rating"].(bson.M)["$lt"]; !ok {
+		t.Error("descending sort should continue with lower values")
+	}
+	if or[1]["rating"] != 4.5 {
+		t.Error("same value should continue with next id")
+	}
+}
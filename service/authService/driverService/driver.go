@@ -3,6 +3,7 @@ package driverService
 import (
 	"errors"
 
+	"../../../config"
 	"../../../db"
 	"../../../model"
 	"../../../util/crypto"
@@ -36,6 +37,11 @@ func CreateDriver(driver *model.Driver) (*model.Driver, error) {
 	driver.Password = crypto.GenerateHash(driver.Password)
 	driver.CreatedAt = timeHelper.GetCurrentTime()
 	driver.UpdatedAt = timeHelper.GetCurrentTime()
+	// driver is reviewed in onboarding, vehicles are added with documents that wait for review
+	driver.Onboarding = config.OnboardingIncomplete
+	driver.ApprovedVehicleIDs = nil
+	driver.OfflineReason = ""
+	driver.DriverVehicles = []*model.DriverVehicle{}
 
 	// Insert Data
 	err := driverCollection.Insert(driver)
@@ -73,6 +79,7 @@ func UpdateDriver(objid bson.ObjectId, driver *model.Driver) (*model.Driver, err
 			"phone":             driver.Phone,
 			"verify.isVerified": driver.Verify.IsVerified,
 			"status":            driver.Status,
+			"bankInfo":          driver.BankInfo,
 			"updatedAt":         timeHelper.GetCurrentTime(),
 		}},
 		ReturnNew: true,
@@ -0,0 +1,208 @@
+package driverService
+
+import (
+	"errors"
+
+	"../../../config"
+	"../../../db"
+	"../../../model"
+	"../../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// UpdateOnboarding updates onboarding status, approved vehicles and offline reason of driver
+func UpdateOnboarding(objid bson.ObjectId, status string, approvedVehicleIDs []bson.ObjectId, reason string) error {
+	driverCollection, session := driverCollection()
+	defer session.Close()
+
+	return driverCollection.UpdateId(objid, bson.M{"$set": bson.M{
+		"onboarding":         status,
+		"approvedVehicleIds": approvedVehicleIDs,
+		"offlineReason":      reason,
+		"updatedAt":          timeHelper.GetCurrentTime(),
+	}})
+}
+
+// UpdateVehicleDocument sets fields of one document of driver's vehicle when document still has fields of guard,
+// returns mgo.ErrNotFound when document is changed since it was read
+func UpdateVehicleDocument(objid bson.ObjectId, number string, documentID bson.ObjectId, guard bson.M, set bson.M) error {
+	mgoDB, session := db.MongoDB()
+	defer session.Close()
+
+	match := bson.M{"_id": documentID}
+	filter := bson.M{"document._id": documentID}
+	for key, value := range guard {
+		match[key] = value
+		filter["document."+key] = value
+	}
+	update := bson.M{}
+	for key, value := range set {
+		update["driverVehicles.$[vehicle].documents.$[document]."+key] = value
+	}
+	// mgo doesn't support array filters, so update command is run
+	result := struct {
+		N           int `bson:"n"`
+		WriteErrors []struct {
+			Errmsg string `bson:"errmsg"`
+		} `bson:"writeErrors"`
+	}{}
+	if err := mgoDB.Run(bson.D{
+		{Name: "update", Value: "driver"},
+		{Name: "updates", Value: []bson.M{{
+			"q": bson.M{"_id": objid, "driverVehicles": bson.M{"$elemMatch": bson.M{
+				"number":    number,
+				"documents": bson.M{"$elemMatch": match},
+			}}},
+			"u":            bson.M{"$set": update},
+			"arrayFilters": []bson.M{{"vehicle.number": number}, filter},
+		}}},
+	}, &result); err != nil {
+		return err
+	}
+	if len(result.WriteErrors) > 0 {
+		return errors.New(result.WriteErrors[0].Errmsg)
+	}
+	if result.N == 0 {
+		return mgo.ErrNotFound
+	}
+	return nil
+}
+
+// ReadDriverIDsToCheckOnboarding returns drivers that onboarding status is not checked yet or is approved,
+// approved status is checked again against review of documents
+func ReadDriverIDsToCheckOnboarding() ([]bson.ObjectId, error) {
+	driverCollection, session := driverCollection()
+	defer session.Close()
+
+	drivers := []*model.Driver{}
+	err := driverCollection.Find(bson.M{"$or": []bson.M{
+		{"onboarding": bson.M{"$exists": false}},
+		{"approvedVehicleIds": bson.M{"$exists": false}},
+		{"onboarding": config.OnboardingApproved},
+	}}).Select(bson.M{"_id": 1}).All(&drivers)
+	ids := []bson.ObjectId{}
+	for _, driver := range drivers {
+		ids = append(ids, driver.ID)
+	}
+	return ids, err
+}
+
+// ReadPendingDocuments returns review queue of uploaded documents, oldest upload first
+func ReadPendingDocuments(offset int, count int) ([]*model.DriverDocument, int, error) {
+	driverCollection, session := driverCollection()
+	defer session.Close()
+
+	pipe := []bson.M{
+		{"$unwind": "$driverVehicles"},
+		{"$unwind": "$driverVehicles.documents"},
+		{"$match": bson.M{"driverVehicles.documents.status": config.DocumentPending}},
+		{"$project": bson.M{
+			"_id":       0,
+			"driverId":  "$_id",
+			"firstname": 1,
+			"lastname":  1,
+			"email":     1,
+			"number":    "$driverVehicles.number",
+			"vehicleId": "$driverVehicles.vehicleId",
+			"document":  "$driverVehicles.documents",
+		}},
+	}
+
+	total := &struct {
+		Count int `bson:"count"`
+	}{}
+	countPipe := append(append([]bson.M{}, pipe...), bson.M{"$group": bson.M{"_id": nil, "count": bson.M{"$sum": 1}}})
+	driverCollection.Pipe(countPipe).One(total)
+
+	pipe = append(pipe,
+		bson.M{"$sort": bson.M{"document.uploadedAt": 1}},
+		bson.M{"$skip": offset},
+	)
+	if count > 0 {
+		pipe = append(pipe, bson.M{"$limit": count})
+	}
+	pipe = append(pipe,
+		bson.M{"$lookup": bson.M{
+			"from":         "document",
+			"localField":   "document._id",
+			"foreignField": "_id",
+			"as":           "document.document",
+		}},
+		bson.M{"$unwind": bson.M{
+			"path":                       "$document.document",
+			"preserveNullAndEmptyArrays": true,
+		}},
+	)
+
+	documents := []*model.DriverDocument{}
+	err := driverCollection.Pipe(pipe).All(&documents)
+	return documents, total.Count, err
+}
+
+// ReadDriversWithExpiringDocuments returns drivers that have document expiring until time
+func ReadDriversWithExpiringDocuments(until int64) ([]*model.Driver, error) {
+	driverCollection, session := driverCollection()
+	defer session.Close()
+
+	drivers := []*model.Driver{}
+	err := driverCollection.Find(bson.M{"driverVehicles.documents": bson.M{"$elemMatch": bson.M{
+		"expireDate": bson.M{"$gt": 0, "$lte": until},
+		"status":     bson.M{"$in": []int{config.DocumentPending, config.DocumentAccepted}},
+	}}}).All(&drivers)
+	return drivers, err
+}
+
+// ReadExpirationReport returns documents expiring in period grouped by city of drivers
+func ReadExpirationReport(from int64, until int64) ([]*model.ExpirationReport, error) {
+	driverCollection, session := driverCollection()
+	defer session.Close()
+
+	reports := []*model.ExpirationReport{}
+	err := driverCollection.Pipe([]bson.M{
+		{"$unwind": "$driverVehicles"},
+		{"$unwind": "$driverVehicles.documents"},
+		{"$match": bson.M{
+			"driverVehicles.documents.expireDate": bson.M{"$gt": from, "$lte": until},
+			"driverVehicles.documents.status":     bson.M{"$in": []int{config.DocumentPending, config.DocumentAccepted}},
+		}},
+		{"$lookup": bson.M{
+			"from":         "document",
+			"localField":   "driverVehicles.documents._id",
+			"foreignField": "_id",
+			"as":           "driverVehicles.documents.document",
+		}},
+		{"$unwind": bson.M{
+			"path":                       "$driverVehicles.documents.document",
+			"preserveNullAndEmptyArrays": true,
+		}},
+		{"$sort": bson.M{"driverVehicles.documents.expireDate": 1}},
+		{"$group": bson.M{
+			"_id":   bson.M{"$ifNull": []interface{}{"$locationPlaceId", ""}},
+			"count": bson.M{"$sum": 1},
+			"documents": bson.M{"$push": bson.M{
+				"driverId":  "$_id",
+				"firstname": "$firstname",
+				"lastname":  "$lastname",
+				"email":     "$email",
+				"number":    "$driverVehicles.number",
+				"vehicleId": "$driverVehicles.vehicleId",
+				"document":  "$driverVehicles.documents",
+			}},
+		}},
+		{"$lookup": bson.M{
+			"from":         "location",
+			"localField":   "_id",
+			"foreignField": "placeId",
+			"as":           "location",
+		}},
+		{"$project": bson.M{
+			"count":     1,
+			"documents": 1,
+			"city":      bson.M{"$arrayElemAt": []interface{}{"$location.city", 0}},
+		}},
+		{"$sort": bson.M{"count": -1}},
+	}).All(&reports)
+	return reports, err
+}
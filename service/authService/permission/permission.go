@@ -104,3 +104,17 @@ func AuthRequired(f func(c echo.Context) error) echo.HandlerFunc {
 		return nil
 	}
 }
+
+// RoleRequired run function when client logged in with one of roles.
+func RoleRequired(f func(c echo.Context) error, roles ...string) echo.HandlerFunc {
+	return AuthRequired(func(c echo.Context) error {
+		_, role := InfoFromToken(c)
+		for _, r := range roles {
+			if r == role {
+				return f(c)
+			}
+		}
+		log.Error("Role is not allowed.")
+		return response.KnownErrorJSON(c, http.StatusForbidden, "error.auth.role", errors.New("You don't have permission"))
+	})
+}
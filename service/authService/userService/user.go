@@ -34,6 +34,10 @@ func CreateUser(user *model.User) (*model.User, error) {
 	}
 	user.ID = bson.NewObjectId()
 	user.Password = crypto.GenerateHash(user.Password)
+	// referrer is set when referral is applied
+	user.ReferredBy = ""
+	// shareable referral code of user
+	user.PromoCode = generatePromoCode(userCollection)
 	user.CreatedAt = timeHelper.GetCurrentTime()
 	user.UpdatedAt = timeHelper.GetCurrentTime()
 
@@ -74,7 +78,6 @@ func UpdateUser(objid bson.ObjectId, user *model.User) (*model.User, error) {
 			"phone":             user.Phone,
 			"verify.isVerified": user.Verify.IsVerified,
 			"status":            user.Status,
-			"promoCode":         user.PromoCode,
 			"homeLocation":      user.HomeLocation,
 			"workLocation":      user.WorkLocation,
 			"updatedAt":         timeHelper.GetCurrentTime(),
@@ -5,6 +5,7 @@ import (
 
 	"../../../model"
 	"../../../util/crypto"
+	"../../../util/random"
 	"../../../util/timeHelper"
 
 	mgo "gopkg.in/mgo.v2"
@@ -107,3 +108,56 @@ func ResetPassword(objid bson.ObjectId, pwd *model.Password) (*model.User, error
 
 	return user, nil
 }
+
+// ReadUserByPromoCode returns user with referral code
+func ReadUserByPromoCode(code string) (*model.User, error) {
+	userCollection, session := userCollection()
+	defer session.Close()
+
+	user := &model.User{}
+	err := userCollection.Find(bson.M{"promoCode": code}).One(&user)
+	return user, err
+}
+
+// CountUsers returns count of users with query
+func CountUsers(query bson.M) int {
+	userCollection, session := userCollection()
+	defer session.Close()
+
+	count, _ := userCollection.Find(query).Count()
+	return count
+}
+
+// GeneratePromoCodes generates referral code of users that don't have it
+func GeneratePromoCodes() {
+	userCollection, session := userCollection()
+	defer session.Close()
+
+	user := &model.User{}
+	iter := userCollection.Find(bson.M{"$or": []bson.M{
+		{"promoCode": bson.M{"$exists": false}},
+		{"promoCode": ""},
+	}}).Select(bson.M{"_id": 1}).Iter()
+	for iter.Next(user) {
+		userCollection.UpdateId(user.ID, bson.M{"$set": bson.M{"promoCode": generatePromoCode(userCollection)}})
+	}
+	iter.Close()
+}
+
+// generatePromoCode returns referral code that is not used
+func generatePromoCode(userCollection *mgo.Collection) string {
+	for {
+		code := random.GenerateRandomString(8)
+		if count, _ := userCollection.Find(bson.M{"promoCode": code}).Count(); count == 0 {
+			return code
+		}
+	}
+}
+
+// UpdateUserReferral saves referrer of user
+func UpdateUserReferral(objid bson.ObjectId, referrerID bson.ObjectId) error {
+	userCollection, session := userCollection()
+	defer session.Close()
+
+	return userCollection.UpdateId(objid, bson.M{"$set": bson.M{"referredBy": referrerID}})
+}
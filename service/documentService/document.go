@@ -58,6 +58,7 @@ func UpdateDocument(objid bson.ObjectId, document *model.Document) (*model.Docum
 			"isExpired":  document.IsExpired,
 			"isRequired": document.IsRequired,
 			"valid":      document.Valid,
+			"vehicleIds": document.VehicleIDs,
 			"updatedAt":  document.UpdatedAt,
 		}},
 		ReturnNew: true,
@@ -119,3 +120,23 @@ func ReadDocuments(query string, field string, sort int, filter bool) (map[strin
 
 	return result, err
 }
+
+// ReadRequiredDocuments returns valid documents that drivers must upload
+func ReadRequiredDocuments() ([]*model.Document, error) {
+	documentCollection, session := documentCollection()
+	defer session.Close()
+
+	documents := []*model.Document{}
+	err := documentCollection.Find(bson.M{"isRequired": true, "valid": true}).All(&documents)
+	return documents, err
+}
+
+// ReadExpiringDocuments returns documents that have expire date
+func ReadExpiringDocuments() ([]*model.Document, error) {
+	documentCollection, session := documentCollection()
+	defer session.Close()
+
+	documents := []*model.Document{}
+	err := documentCollection.Find(bson.M{"isExpired": true}).All(&documents)
+	return documents, err
+}
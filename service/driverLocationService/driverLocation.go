@@ -1,11 +1,16 @@
 package driverLocationService
 
 import (
+	"errors"
 	"log"
+	"sort"
 
 	"../../config"
 	"../../db"
 	"../../model"
+	"../../service/authService/driverService"
+	"../../service/orderService"
+	"../../service/shiftService"
 	"../../util/timeHelper"
 
 	mgo "gopkg.in/mgo.v2"
@@ -31,6 +36,7 @@ func InitService() {
 	if err != nil {
 		log.Println(err)
 	}
+	initHistory()
 	// db.shops.ensureIndex({location:"2dsphere"})
 	// db.getCollection('driver_location').aggregate([
 	// {$geoNear: {
@@ -45,8 +51,36 @@ func InitService() {
 	// ])
 }
 
+// checkOnline checks that driver and vehicle of driver are approved to go online
+func checkOnline(driverID bson.ObjectId, vehicleID bson.ObjectId) error {
+	driver, err := driverService.ReadDriver(driverID)
+	if err != nil {
+		return err
+	}
+	if driver.Onboarding != config.OnboardingApproved {
+		if driver.OfflineReason != "" {
+			return errors.New("Driver is not approved to go online: " + driver.OfflineReason)
+		}
+		return errors.New("Driver is not approved to go online")
+	}
+	if vehicleID == "" {
+		return nil
+	}
+	for _, approvedID := range driver.ApprovedVehicleIDs {
+		if approvedID == vehicleID {
+			return nil
+		}
+	}
+	return errors.New("Vehicle is not approved to go online")
+}
+
 // UpdateDriverLocation updates driverLocation
 func UpdateDriverLocation(driverLocation *model.DriverLocation) (*model.DriverLocation, error) {
+	if driverLocation.Status == config.Online {
+		if err := checkOnline(driverLocation.DriverID, driverLocation.VehicleID); err != nil {
+			return nil, err
+		}
+	}
 	driverLocationCollection, session := driverLocationCollection()
 	defer session.Close()
 
@@ -67,6 +101,10 @@ func UpdateDriverLocation(driverLocation *model.DriverLocation) (*model.DriverLo
 
 	// Update driverLocation
 	_, err := driverLocationCollection.Find(bson.M{"driverId": driverLocation.DriverID}).Apply(change, driverLocation)
+	if err == nil && driverLocation.Status != config.Offline {
+		point := *driverLocation
+		go recordHistory(&point)
+	}
 	return driverLocation, err
 }
 
@@ -99,6 +137,7 @@ func GetNearbyDrivers(lat, lng float64, limit int) ([]*model.DriverLocation, err
 			{"$unwind": bson.M{
 				"path": "$driver",
 				"preserveNullAndEmptyArrays": true}},
+			{"$match": bson.M{"driver.onboarding": config.OnboardingApproved}},
 		}).All(&driverLocations)
 
 		if err == nil && len(driverLocations) > 0 {
@@ -110,11 +149,30 @@ func GetNearbyDrivers(lat, lng float64, limit int) ([]*model.DriverLocation, err
 		searchRadius += config.DefaultSearchRadius
 		i++
 	}
+	if config.ShiftDispatchPriority && len(driverLocations) > 0 {
+		PrioritizeShift(driverLocations, shiftService.OnShiftDriverIDs(timeHelper.GetCurrentTime()))
+	}
 	return driverLocations, err
 }
 
+// PrioritizeShift puts drivers on booked shift first, drivers keep order of distance in each group
+func PrioritizeShift(driverLocations []*model.DriverLocation, onShift map[bson.ObjectId]bool) {
+	sort.SliceStable(driverLocations, func(i, j int) bool {
+		return onShift[driverLocations[i].DriverID] && !onShift[driverLocations[j].DriverID]
+	})
+}
+
 // UpdateDriverStatus update driver's status
 func UpdateDriverStatus(objid bson.ObjectId, status int) error {
+	if status == config.Online {
+		location, err := ReadDriverLocation(objid)
+		if err != nil {
+			return err
+		}
+		if err := checkOnline(objid, location.VehicleID); err != nil {
+			return err
+		}
+	}
 	driverLocationCollection, session := driverLocationCollection()
 	defer session.Close()
 	// Create change info
@@ -139,3 +197,34 @@ func ReadDriverLocation(driverID bson.ObjectId) (*model.DriverLocation, error) {
 	err := driverLocationCollection.Find(bson.M{"driverId": driverID}).One(&driverLocation)
 	return driverLocation, err
 }
+
+// CountOnlineInArea returns count of online drivers that are not on trip in area, location of driver is updated since time
+func CountOnlineInArea(area *model.Polygon, since int64) (int, error) {
+	onTrip, err := orderService.ReadDriverIDsOnTrip()
+	if err != nil {
+		return 0, err
+	}
+	driverLocationCollection, session := driverLocationCollection()
+	defer session.Close()
+
+	return driverLocationCollection.Find(bson.M{
+		"status":    config.Online,
+		"driverId":  bson.M{"$nin": onTrip},
+		"updatedAt": bson.M{"$gte": since},
+		"location":  bson.M{"$geoWithin": bson.M{"$geometry": area}},
+	}).Count()
+}
+
+// ReadOnlineLocations reads locations of online drivers that are updated since time, every city when place is empty
+func ReadOnlineLocations(placeID string, since int64) ([]*model.DriverLocation, error) {
+	driverLocationCollection, session := driverLocationCollection()
+	defer session.Close()
+
+	query := bson.M{"status": config.Online, "updatedAt": bson.M{"$gte": since}}
+	if placeID != "" {
+		query["placeId"] = placeID
+	}
+	locations := []*model.DriverLocation{}
+	err := driverLocationCollection.Find(query).Select(bson.M{"driverId": 1, "location": 1, "placeId": 1}).All(&locations)
+	return locations, err
+}
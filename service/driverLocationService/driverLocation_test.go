@@ -0,0 +1,48 @@
+package driverLocationService
+
+import (
+	"testing"
+
+	"../../model"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestPrioritizeShift(t *testing.T) {
+	a, b, c := bson.NewObjectId(), bson.NewObjectId(), bson.NewObjectId()
+	locations := []*model.DriverLocation{{DriverID: a}, {DriverID: b}, {DriverID: c}}
+	PrioritizeShift(locations, map[bson.ObjectId]bool{c: true})
+	if locations[0].DriverID != c || locations[1].DriverID != a || locations[2].DriverID != b {
+		t.Errorf("drivers are not prioritized by shift")
+	}
+}
+
+func TestBuildTrail(t *testing.T) {
+	point := func(lng, lat float64, at int64) *model.LocationPoint {
+		return &model.LocationPoint{Location: model.GeoJSON{Type: "Point", Coordinates: []float64{lng, lat}}, At: at}
+	}
+	// about 111 meters per 0.001 degree, jump of 1 degree is gps error
+	points := []*model.LocationPoint{
+		point(0, 0, 100),
+		point(0.001, 0, 110),
+		point(1, 1, 115),
+		point(0.002, 0, 120),
+		point(0.003, 0.001, 130),
+		point(0.004, 0.002, 200),
+	}
+	orderID := bson.NewObjectId()
+
+	trail := BuildTrail(orderID, points, 0, 0, 5)
+	if trail.Properties.Points != 5 || trail.Properties.Duration != 100 || len(trail.Geometry.Coordinates) != 3 {
+		t.Errorf("trail = %+v, %v", trail.Properties, trail.Geometry.Coordinates)
+	}
+	if d := trail.Properties.Distance; d < 222 || d > 540 {
+		t.Errorf("distance of trail = %v", d)
+	}
+
+	// actual trip is between start and completion
+	trail = BuildTrail(orderID, points, 105, 125, 0)
+	if trail.Properties.Points != 2 || trail.Properties.Duration != 20 || trail.Geometry.Type != "LineString" {
+		t.Errorf("trail of period = %+v", trail.Properties)
+	}
+}
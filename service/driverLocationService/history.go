@@ -0,0 +1,125 @@
+package driverLocationService
+
+import (
+	"time"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../service/orderService"
+	"../../util/geo"
+	"../../util/log"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func historyCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("driver_location_history"), session
+}
+
+// initHistory ensures indexes of location history, history is removed after retention
+func initHistory() {
+	historyCollection, session := historyCollection()
+	defer session.Close()
+
+	historyCollection.EnsureIndex(mgo.Index{
+		Key:        []string{"orderId", "at"},
+		Background: true,
+	})
+	err := historyCollection.EnsureIndex(mgo.Index{
+		Key:         []string{"date"},
+		ExpireAfter: config.LocationHistoryRetention,
+		Background:  true,
+	})
+	log.CheckErrorNoStackWithMessage(err, "Failed to ensure retention of location history")
+}
+
+// recordHistory writes location of driver when driver is on trip
+func recordHistory(driverLocation *model.DriverLocation) {
+	order, err := orderService.ReadActiveTrip(driverLocation.DriverID)
+	if err != nil {
+		return
+	}
+	historyCollection, session := historyCollection()
+	defer session.Close()
+
+	historyCollection.Insert(&model.LocationPoint{
+		ID:         bson.NewObjectId(),
+		DriverID:   driverLocation.DriverID,
+		OrderID:    order.ID,
+		TripStatus: order.TripStatus,
+		Location:   driverLocation.Location,
+		At:         driverLocation.UpdatedAt,
+		Date:       time.Unix(driverLocation.UpdatedAt, 0),
+	})
+}
+
+// ReadTripPoints returns locations of driver on trip of order in order of time
+func ReadTripPoints(orderID bson.ObjectId) ([]*model.LocationPoint, error) {
+	historyCollection, session := historyCollection()
+	defer session.Close()
+
+	points := []*model.LocationPoint{}
+	err := historyCollection.Find(bson.M{"orderId": orderID}).Sort("at").All(&points)
+	return points, err
+}
+
+// BuildTrail returns trail of points in period, whole points when period is 0,
+// distance is measured before path is simplified with tolerance in meters
+func BuildTrail(orderID bson.ObjectId, points []*model.LocationPoint, from int64, to int64, tolerance float64) *model.TripTrail {
+	path := [][]float64{}
+	var first, last *model.LocationPoint
+	for _, point := range points {
+		if len(point.Location.Coordinates) != 2 || (from > 0 && point.At < from) || (to > 0 && point.At > to) {
+			continue
+		}
+		// gps jump is faster than driver can go
+		if last != nil {
+			seconds := float64(point.At - last.At)
+			if geo.DistanceOfCoordinates(last.Location.Coordinates, point.Location.Coordinates) > config.TrailMaxSpeed*seconds+10 {
+				continue
+			}
+		}
+		if first == nil {
+			first = point
+		}
+		last = point
+		path = append(path, point.Location.Coordinates)
+	}
+
+	properties := &model.TrailProperties{
+		OrderID:  orderID,
+		Distance: geo.PathLength(path),
+		Points:   len(path),
+	}
+	switch {
+	case from > 0 && to > from:
+		properties.Duration = to - from
+	case first != nil:
+		properties.Duration = last.At - first.At
+	}
+	return &model.TripTrail{
+		Type:       "Feature",
+		Geometry:   &model.LineString{Type: "LineString", Coordinates: geo.Simplify(path, tolerance)},
+		Properties: properties,
+	}
+}
+
+// TripActuals returns distance in meters and duration in seconds that driver went from start to completion of trip
+func TripActuals(order *model.Order) (float64, int64, error) {
+	points, err := ReadTripPoints(order.ID)
+	if err != nil {
+		return 0, 0, err
+	}
+	startedAt, ok := order.StatusAt[config.TripStarted]
+	if !ok {
+		startedAt = order.StatusAt[config.TripAccepted]
+	}
+	trail := BuildTrail(order.ID, points, startedAt, order.StatusAt[config.TripCompleted], 0)
+	if trail.Properties.Points < 2 {
+		return 0, 0, nil
+	}
+	return trail.Properties.Distance, trail.Properties.Duration, nil
+}
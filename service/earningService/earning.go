@@ -0,0 +1,292 @@
+package earningService
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../service/driverLocationService"
+	"../../service/locationService"
+	"../../service/orderService"
+	"../../service/paymentService"
+	"../../util/geo"
+	"../../util/log"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// weekOrigin is first monday after epoch, weeks of statements start at monday
+const weekOrigin = 4 * 24 * 3600
+
+func earningCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("driver_earning"), session
+}
+
+// InitService inits service
+func InitService() {
+	earningCollection, session := earningCollection()
+	defer session.Close()
+
+	// key makes earning of trip and tip idempotent
+	earningCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"key"},
+		Unique: true,
+		Sparse: true,
+	})
+	earningCollection.EnsureIndex(mgo.Index{
+		Key: []string{"driverId", "createdAt"},
+	})
+	earningCollection.EnsureIndex(mgo.Index{
+		Key: []string{"payoutId", "createdAt"},
+	})
+}
+
+// FareEarning returns earning of trip with fare of vehicle and surge multiplier, commission is not taken from surge
+func FareEarning(fare *model.Fare, kilometers float64, minutes float64, surge float64, percent float64) *model.Earning {
+	earning := &model.Earning{
+		Kind:       config.EarningTrip,
+		Kilometers: kilometers,
+		Minutes:    minutes,
+	}
+	if fare == nil {
+		return earning
+	}
+	earning.Base = cents(float64(fare.BaseFare))
+	earning.Distance = cents(float64(fare.PerKm) * kilometers)
+	earning.Time = cents(float64(fare.PerMinute) * minutes)
+	// short trip is paid with minimum fare
+	if minFare := cents(float64(fare.MinFare)); earning.Base+earning.Distance+earning.Time < minFare {
+		earning.Base = minFare - earning.Distance - earning.Time
+	}
+	fareAmount := earning.Base + earning.Distance + earning.Time
+	if surge > 1 {
+		earning.Surge = cents(float64(fareAmount) / 100 * (surge - 1))
+	}
+	earning.Commission = cents(float64(fareAmount) / 100 * percent / 100)
+	earning.Total = fareAmount + earning.Surge - earning.Commission
+	return earning
+}
+
+func cents(amount float64) int64 {
+	return int64(math.Floor(amount*100 + 0.5))
+}
+
+// RecordTrip records earning of completed trip of order
+func RecordTrip(objid bson.ObjectId) (*model.Earning, error) {
+	order, err := orderService.ReadOrder(objid)
+	if err != nil {
+		return nil, err
+	}
+	if order.DriverID == "" {
+		return nil, errors.New("Order has no driver")
+	}
+	var kilometers, minutes float64
+	if order.Business != nil && order.DeliveryLocation != nil {
+		kilometers = geo.DistanceOfCoordinates(order.Business.GeoLocation.GeoJSON.Coordinates, order.DeliveryLocation.GeoJSON.Coordinates) / 1000
+	}
+	startedAt, ok := order.StatusAt[config.TripStarted]
+	if !ok {
+		startedAt = order.StatusAt[config.TripAccepted]
+	}
+	if completedAt := order.StatusAt[config.TripCompleted]; startedAt > 0 && completedAt > startedAt {
+		minutes = float64(completedAt-startedAt) / 60
+	}
+	// trip that driver went actually is used when location history is recorded
+	if order.ActualDistance > 0 {
+		kilometers = order.ActualDistance / 1000
+	}
+	if order.ActualDuration > 0 {
+		minutes = float64(order.ActualDuration) / 60
+	}
+	fare, night := tripFare(order, time.Unix(startedAt, 0))
+	// surge of zone that order locked is paid unless night surge is higher
+	surge := order.Surge
+	if night && fare != nil && float64(fare.NightSurge) > surge {
+		surge = float64(fare.NightSurge)
+	}
+
+	earning := FareEarning(fare, kilometers, minutes, surge, config.DriverCommissionPercent)
+	// tip at checkout is paid with order
+	if order.TipPayment == nil {
+		earning.Tip = paymentService.ToCents(order.Tip)
+		earning.Total += earning.Tip
+	}
+	earning.DriverID = order.DriverID
+	earning.OrderID = order.ID
+	earning.Number = order.Number
+	earning.Key = config.EarningTrip + ":" + order.ID.Hex()
+	earning, err = insertEarning(earning)
+	log.CheckErrorNoStackWithMessage(err, "Failed to record earning of order %s", order.ID.Hex())
+	return earning, err
+}
+
+// RecordTip records tip after trip of order
+func RecordTip(order *model.Order) (*model.Earning, error) {
+	if order.TipPayment == nil || order.DriverID == "" {
+		return nil, errors.New("Order has no tip after trip")
+	}
+	tip := paymentService.ToCents(order.Tip)
+	return insertEarning(&model.Earning{
+		DriverID: order.DriverID,
+		OrderID:  order.ID,
+		Number:   order.Number,
+		Kind:     config.EarningTip,
+		Key:      config.EarningTip + ":" + order.ID.Hex(),
+		Tip:      tip,
+		Total:    tip,
+	})
+}
+
+// AddAdjustment adds positive or negative adjustment to earnings of driver
+func AddAdjustment(earning *model.Earning) (*model.Earning, error) {
+	if earning.Adjustment == 0 {
+		return nil, errors.New("Amount of adjustment is invalid")
+	}
+	if earning.Note == "" {
+		return nil, errors.New("Note of adjustment is empty")
+	}
+	return insertEarning(&model.Earning{
+		DriverID:   earning.DriverID,
+		OrderID:    earning.OrderID,
+		Kind:       config.EarningAdjustment,
+		Adjustment: earning.Adjustment,
+		Total:      earning.Adjustment,
+		Note:       earning.Note,
+		IssuerID:   earning.IssuerID,
+	})
+}
+
+// tripFare returns fare of vehicle of driver in city of order and whether trip is at night
+func tripFare(order *model.Order, startedAt time.Time) (*model.Fare, bool) {
+	placeID := order.PlaceID
+	if placeID == "" && order.Driver != nil {
+		placeID = order.Driver.LocationPlaceID
+	}
+	location, err := locationService.ReadLocationWithPlaceID(placeID)
+	if !log.CheckErrorNoStackWithMessage(err, "Failed to read fare of place %s", placeID) || len(location.VehicleInfos) == 0 {
+		return nil, false
+	}
+	info := location.VehicleInfos[0]
+	if driverLocation, err := driverLocationService.ReadDriverLocation(order.DriverID); err == nil {
+		for _, vehicleInfo := range location.VehicleInfos {
+			if vehicleInfo.VehicleID == driverLocation.VehicleID {
+				info = vehicleInfo
+			}
+		}
+	}
+	night := location.IsNightTime && InPeriod(location.NightTime, startedAt)
+	return info.Fare, night
+}
+
+// InPeriod checks that time of day is in period, period can pass midnight
+func InPeriod(period *model.Period, t time.Time) bool {
+	if period == nil {
+		return false
+	}
+	minute := func(t time.Time) int {
+		t = t.UTC()
+		return t.Hour()*60 + t.Minute()
+	}
+	from, to, now := minute(period.From), minute(period.To), minute(t)
+	if from <= to {
+		return from <= now && now < to
+	}
+	return now >= from || now < to
+}
+
+func insertEarning(earning *model.Earning) (*model.Earning, error) {
+	earningCollection, session := earningCollection()
+	defer session.Close()
+
+	earning.ID = bson.NewObjectId()
+	earning.CreatedAt = timeHelper.GetCurrentTime()
+	if err := earningCollection.Insert(earning); err != nil {
+		if mgo.IsDup(err) {
+			// recorded already
+			err = earningCollection.Find(bson.M{"key": earning.Key}).One(earning)
+		}
+		return earning, err
+	}
+	return earning, nil
+}
+
+// ReadEarnings reads earnings of driver in time range
+func ReadEarnings(driverID bson.ObjectId, from int64, to int64, offset int, count int) ([]*model.Earning, int, error) {
+	earningCollection, session := earningCollection()
+	defer session.Close()
+
+	query := earningCollection.Find(earningMatch(driverID, from, to))
+	totalCount, _ := query.Count()
+	earnings := []*model.Earning{}
+	err := query.Sort("-createdAt").Skip(offset).Limit(count).All(&earnings)
+	return earnings, totalCount, err
+}
+
+// PeriodStart returns start of day or week of time in UTC
+func PeriodStart(t int64, period string) int64 {
+	if period == config.EarningWeekly {
+		return t - (t-weekOrigin)%(7*24*3600)
+	}
+	return t - t%(24*3600)
+}
+
+// ReadStatements reads daily or weekly statements of driver in time range
+func ReadStatements(driverID bson.ObjectId, period string, from int64, to int64) ([]*model.EarningStatement, error) {
+	earningCollection, session := earningCollection()
+	defer session.Close()
+
+	size, origin := int64(24*3600), int64(0)
+	if period == config.EarningWeekly {
+		size, origin = 7*24*3600, weekOrigin
+	}
+	pipe := []bson.M{
+		{"$match": earningMatch(driverID, from, to)},
+		{"$group": bson.M{
+			"_id": bson.M{"$subtract": []interface{}{
+				"$createdAt",
+				bson.M{"$mod": []interface{}{bson.M{"$subtract": []interface{}{"$createdAt", origin}}, size}},
+			}},
+			"trips":      bson.M{"$sum": bson.M{"$cond": []interface{}{bson.M{"$eq": []interface{}{"$kind", config.EarningTrip}}, 1, 0}}},
+			"kilometers": bson.M{"$sum": "$kilometers"},
+			"minutes":    bson.M{"$sum": "$minutes"},
+			"base":       bson.M{"$sum": "$base"},
+			"distance":   bson.M{"$sum": "$distance"},
+			"time":       bson.M{"$sum": "$time"},
+			"surge":      bson.M{"$sum": "$surge"},
+			"commission": bson.M{"$sum": "$commission"},
+			"tip":        bson.M{"$sum": "$tip"},
+			"adjustment": bson.M{"$sum": "$adjustment"},
+			"total":      bson.M{"$sum": "$total"},
+		}},
+		{"$sort": bson.M{"_id": -1}},
+	}
+	statements := []*model.EarningStatement{}
+	if err := earningCollection.Pipe(pipe).All(&statements); err != nil {
+		return nil, err
+	}
+	for _, statement := range statements {
+		statement.End = statement.Start + size
+	}
+	return statements, nil
+}
+
+func earningMatch(driverID bson.ObjectId, from int64, to int64) bson.M {
+	match := bson.M{"driverId": driverID}
+	if from > 0 || to > 0 {
+		createdAt := bson.M{}
+		if from > 0 {
+			createdAt["$gte"] = from
+		}
+		if to > 0 {
+			createdAt["$lt"] = to
+		}
+		match["createdAt"] = createdAt
+	}
+	return match
+}
@@ -0,0 +1,106 @@
+package earningService
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"../../config"
+	"../../model"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestFareEarning(t *testing.T) {
+	fare := &model.Fare{BaseFare: 2, MinFare: 5, PerKm: 1.5, PerMinute: 0.25, NightSurge: 1.5}
+
+	earning := FareEarning(fare, 4, 12, 1, 20)
+	if earning.Base != 200 || earning.Distance != 600 || earning.Time != 300 || earning.Surge != 0 {
+		t.Errorf("fare components = %+v", earning)
+	}
+	if earning.Commission != 220 || earning.Total != 880 {
+		t.Errorf("fare total = %+v", earning)
+	}
+
+	earning = FareEarning(fare, 4, 12, 1.5, 20)
+	if earning.Surge != 550 || earning.Total != 1430 {
+		t.Errorf("surge fare = %+v", earning)
+	}
+
+	// short trip is topped up to minimum fare
+	earning = FareEarning(fare, 1, 2, 0, 0)
+	if earning.Base != 300 || earning.Total != 500 {
+		t.Errorf("minimum fare = %+v", earning)
+	}
+
+	if earning := FareEarning(nil, 1, 2, 1, 20); earning.Total != 0 {
+		t.Errorf("earning without fare = %+v", earning)
+	}
+}
+
+func TestInPeriod(t *testing.T) {
+	clock := func(hour, minute int) time.Time {
+		return time.Date(2017, 6, 1, hour, minute, 0, 0, time.UTC)
+	}
+	night := &model.Period{From: clock(22, 0), To: clock(6, 0)}
+	day := &model.Period{From: clock(6, 0), To: clock(22, 0)}
+	cases := []struct {
+		period *model.Period
+		t      time.Time
+		want   bool
+	}{
+		{night, clock(23, 30), true},
+		{night, clock(2, 0), true},
+		{night, clock(6, 0), false},
+		{night, clock(12, 0), false},
+		{day, clock(12, 0), true},
+		{day, clock(22, 0), false},
+		{nil, clock(12, 0), false},
+	}
+	for _, c := range cases {
+		if got := InPeriod(c.period, c.t); got != c.want {
+			t.Errorf("InPeriod(%v) = %v, want %v", c.t, got, c.want)
+		}
+	}
+}
+
+func TestPeriodStart(t *testing.T) {
+	// wednesday 2017-06-14 13:00 UTC
+	now := time.Date(2017, 6, 14, 13, 0, 0, 0, time.UTC).Unix()
+	if got, want := PeriodStart(now, config.EarningDaily), time.Date(2017, 6, 14, 0, 0, 0, 0, time.UTC).Unix(); got != want {
+		t.Errorf("daily start = %v, want %v", got, want)
+	}
+	if got, want := PeriodStart(now, config.EarningWeekly), time.Date(2017, 6, 12, 0, 0, 0, 0, time.UTC).Unix(); got != want {
+		t.Errorf("weekly start = %v, want %v", got, want)
+	}
+}
+
+func TestPayoutCSV(t *testing.T) {
+	batch := &model.DriverPayoutBatch{
+		ID: bson.NewObjectId(),
+		Payouts: []*model.DriverPayout{
+			{DriverID: bson.NewObjectId(), Name: "John Doe", Count: 3, Amount: 4250},
+			{DriverID: bson.NewObjectId(), Name: "Jane Roe", Count: 1, Amount: 900, Status: config.PayoutHeld},
+		},
+	}
+	data, err := PayoutCSV(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[1], ",3,42.50") {
+		t.Errorf("csv = %q", lines)
+	}
+}
+
+func TestHoldPayouts(t *testing.T) {
+	valid := model.BankInfo{Routing: "021000021", Number: "123456789"}
+	payouts := HoldPayouts([]*model.DriverPayout{
+		{DriverID: bson.NewObjectId(), BankInfo: valid},
+		{DriverID: bson.NewObjectId(), BankInfo: model.BankInfo{Routing: "123", Number: "123456789"}},
+		{DriverID: bson.NewObjectId()},
+	})
+	if payouts[0].Status != "" || payouts[1].Status != config.PayoutHeld || payouts[2].Status != config.PayoutHeld {
+		t.Errorf("statuses = %q %q %q", payouts[0].Status, payouts[1].Status, payouts[2].Status)
+	}
+}
@@ -0,0 +1,244 @@
+package earningService
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../service/payoutService"
+	"../../util/log"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func payoutCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("driver_payout"), session
+}
+
+// CreatePayoutBatch creates batch of unpaid earnings of drivers before time, until is today by default.
+// Earnings are claimed for batch first, so batches created at same time don't share earnings.
+// Driver whose total is not positive or whose bank info is invalid carries earnings to next batch.
+func CreatePayoutBatch(until int64) (*model.DriverPayoutBatch, error) {
+	if today := PeriodStart(timeHelper.GetCurrentTime(), config.EarningDaily); until <= 0 || until > today {
+		until = today
+	}
+	earningCollection, session := earningCollection()
+	defer session.Close()
+
+	batch := &model.DriverPayoutBatch{
+		ID:        bson.NewObjectId(),
+		Until:     until,
+		Status:    config.PayoutPending,
+		CreatedAt: timeHelper.GetCurrentTime(),
+	}
+	claimed, err := earningCollection.UpdateAll(
+		bson.M{"payoutId": bson.M{"$exists": false}, "createdAt": bson.M{"$lt": until}},
+		bson.M{"$set": bson.M{"payoutId": batch.ID}})
+	if err != nil {
+		return nil, err
+	}
+	if claimed.Updated == 0 {
+		return nil, errors.New("There are no earnings to pay")
+	}
+
+	pipe := []bson.M{
+		{"$match": bson.M{"payoutId": batch.ID}},
+		{"$group": bson.M{
+			"_id":    "$driverId",
+			"count":  bson.M{"$sum": 1},
+			"amount": bson.M{"$sum": "$total"},
+		}},
+		{"$lookup": bson.M{
+			"from":         "driver",
+			"localField":   "_id",
+			"foreignField": "_id",
+			"as":           "driver",
+		}},
+		{"$unwind": bson.M{
+			"path":                       "$driver",
+			"preserveNullAndEmptyArrays": true}},
+		{"$project": bson.M{
+			"count":    1,
+			"amount":   1,
+			"name":     bson.M{"$concat": []interface{}{bson.M{"$ifNull": []interface{}{"$driver.firstname", ""}}, " ", bson.M{"$ifNull": []interface{}{"$driver.lastname", ""}}}},
+			"bankInfo": "$driver.bankInfo",
+		}},
+		{"$sort": bson.M{"_id": 1}},
+	}
+	payouts := []*model.DriverPayout{}
+	if err := earningCollection.Pipe(pipe).All(&payouts); err != nil {
+		releaseEarnings(batch.ID, nil)
+		return nil, err
+	}
+
+	batch.Payouts = []*model.DriverPayout{}
+	carried := []bson.ObjectId{}
+	for _, payout := range HoldPayouts(payouts) {
+		if payout.Status == config.PayoutHeld || payout.Amount <= 0 {
+			carried = append(carried, payout.DriverID)
+		}
+		if payout.Amount <= 0 {
+			continue
+		}
+		batch.Payouts = append(batch.Payouts, payout)
+		if payout.Status != config.PayoutHeld {
+			batch.Count++
+			batch.Amount += payout.Amount
+		}
+	}
+	if batch.Count == 0 {
+		releaseEarnings(batch.ID, nil)
+		return nil, errors.New("There are no earnings to pay")
+	}
+	// held and negative earnings are paid in next batch
+	if len(carried) > 0 {
+		releaseEarnings(batch.ID, carried)
+	}
+
+	payoutCollection, payoutSession := payoutCollection()
+	defer payoutSession.Close()
+	if err := payoutCollection.Insert(batch); err != nil {
+		releaseEarnings(batch.ID, nil)
+		return nil, err
+	}
+	return batch, nil
+}
+
+// HoldPayouts holds payouts of drivers whose bank info is invalid
+func HoldPayouts(payouts []*model.DriverPayout) []*model.DriverPayout {
+	for _, payout := range payouts {
+		if !payoutService.ValidBankInfo(payout.BankInfo) {
+			payout.Status = config.PayoutHeld
+			payout.Note = "Bank info of driver is invalid"
+		}
+	}
+	return payouts
+}
+
+// releaseEarnings releases earnings of drivers claimed for batch, earnings of every driver when drivers are empty
+func releaseEarnings(batchID bson.ObjectId, driverIDs []bson.ObjectId) {
+	earningCollection, session := earningCollection()
+	defer session.Close()
+
+	query := bson.M{"payoutId": batchID}
+	if len(driverIDs) > 0 {
+		query["driverId"] = bson.M{"$in": driverIDs}
+	}
+	_, err := earningCollection.UpdateAll(query, bson.M{"$unset": bson.M{"payoutId": ""}})
+	log.CheckErrorNoStackWithMessage(err, "Failed to release earnings of payout %s", batchID.Hex())
+}
+
+// ReadPayoutBatch reads payout batch
+func ReadPayoutBatch(objid bson.ObjectId) (*model.DriverPayoutBatch, error) {
+	payoutCollection, session := payoutCollection()
+	defer session.Close()
+
+	batch := &model.DriverPayoutBatch{}
+	err := payoutCollection.FindId(objid).One(batch)
+	return batch, err
+}
+
+// ReadPayoutBatches reads payout batches
+func ReadPayoutBatches(offset int, count int) ([]*model.DriverPayoutBatch, int, error) {
+	payoutCollection, session := payoutCollection()
+	defer session.Close()
+
+	query := payoutCollection.Find(bson.M{})
+	totalCount, _ := query.Count()
+	batches := []*model.DriverPayoutBatch{}
+	err := query.Sort("-createdAt").Skip(offset).Limit(count).All(&batches)
+	return batches, totalCount, err
+}
+
+// ReadDriverPayouts reads payout batches that include driver
+func ReadDriverPayouts(driverID bson.ObjectId, offset int, count int) ([]*model.DriverPayout, int, error) {
+	payoutCollection, session := payoutCollection()
+	defer session.Close()
+
+	query := payoutCollection.Find(bson.M{"payouts._id": driverID})
+	totalCount, _ := query.Count()
+	batches := []*model.DriverPayoutBatch{}
+	if err := query.Sort("-createdAt").Skip(offset).Limit(count).All(&batches); err != nil {
+		return nil, 0, err
+	}
+	payouts := []*model.DriverPayout{}
+	for _, batch := range batches {
+		for _, payout := range batch.Payouts {
+			if payout.DriverID == driverID {
+				payouts = append(payouts, payout)
+			}
+		}
+	}
+	return payouts, totalCount, nil
+}
+
+// ExportPayoutBatch returns csv file of payout batch and marks it as exported
+func ExportPayoutBatch(objid bson.ObjectId) ([]byte, error) {
+	batch, err := ReadPayoutBatch(objid)
+	if err != nil {
+		return nil, err
+	}
+	data, err := PayoutCSV(batch)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status == config.PayoutPending {
+		err = updatePayoutStatus(objid, config.PayoutExported, bson.M{})
+	}
+	return data, err
+}
+
+// PayPayoutBatch marks payout batch as paid after bank transfer
+func PayPayoutBatch(objid bson.ObjectId) (*model.DriverPayoutBatch, error) {
+	batch, err := ReadPayoutBatch(objid)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status == config.PayoutPaid {
+		return nil, errors.New("Batch is paid already")
+	}
+	if err := updatePayoutStatus(objid, config.PayoutPaid, bson.M{"paidAt": timeHelper.GetCurrentTime()}); err != nil {
+		return nil, err
+	}
+	return ReadPayoutBatch(objid)
+}
+
+func updatePayoutStatus(objid bson.ObjectId, status string, set bson.M) error {
+	payoutCollection, session := payoutCollection()
+	defer session.Close()
+
+	set["status"] = status
+	return payoutCollection.UpdateId(objid, bson.M{"$set": set})
+}
+
+// PayoutCSV returns csv file of payouts of batch with amounts in dollars, held payouts are not exported
+func PayoutCSV(batch *model.DriverPayoutBatch) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	w.Write([]string{"batchId", "driverId", "name", "bank", "accountName", "routing", "account", "earnings", "amount"})
+	for _, payout := range batch.Payouts {
+		if payout.Status == config.PayoutHeld {
+			continue
+		}
+		w.Write([]string{
+			batch.ID.Hex(),
+			payout.DriverID.Hex(),
+			payout.Name,
+			payout.BankInfo.Name,
+			payout.BankInfo.Account,
+			payout.BankInfo.Routing,
+			payout.BankInfo.Number,
+			fmt.Sprint(payout.Count),
+			fmt.Sprintf("%.2f", float64(payout.Amount)/100),
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
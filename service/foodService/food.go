@@ -20,7 +20,8 @@ func foodCollection() (*mgo.Collection, *mgo.Session) {
 
 // InitService inits service
 func InitService() {
-
+	initRevision()
+	initVersion()
 }
 
 // CreateFood creates food
@@ -54,33 +55,31 @@ func ReadFood(objid bson.ObjectId) (*model.Food, error) {
 }
 
 // UpdateFood updates food
-func UpdateFood(objid bson.ObjectId, food *model.Food) (*model.Food, error) {
-	foodCollection, session := foodCollection()
-	defer session.Close()
+func UpdateFood(objid bson.ObjectId, food *model.Food, authorID bson.ObjectId, authorRole string) (*model.Food, error) {
+	return RecordFoodVersion(objid, bson.M{"$set": bson.M{
+		"foodType":      food.FoodType,
+		"mealKindCodes": food.MealKindCodes,
+		"dietaryCodes":  food.DietaryCodes,
+		"image":         food.Image,
+		"name":          food.Name,
+		"description":   food.Description,
+		"price":         food.Price,
+		"soldOut":       food.SoldOut,
+		"enabled":       food.Enabled,
+		"recommend":     food.Recommend,
+		"mostPopular":   food.MostPopular,
+		"freeDelivery":  food.FreeDelivery,
+		"updatedAt":     timeHelper.GetCurrentTime(),
+	}}, authorID, authorRole, "", "")
+}
 
-	food.UpdatedAt = timeHelper.GetCurrentTime()
-	// Create change info
-	change := mgo.Change{
-		Update: bson.M{"$set": bson.M{
-			"foodType":      food.FoodType,
-			"mealKindCodes": food.MealKindCodes,
-			"dietaryCodes":  food.DietaryCodes,
-			"image":         food.Image,
-			"name":          food.Name,
-			"description":   food.Description,
-			"price":         food.Price,
-			"soldOut":       food.SoldOut,
-			"enabled":       food.Enabled,
-			"recommend":     food.Recommend,
-			"mostPopular":   food.MostPopular,
-			"freeDelivery":  food.FreeDelivery,
-			"updatedAt":     food.UpdatedAt,
-		}},
-		ReturnNew: true,
-	}
-	// Update food
-	_, err := foodCollection.FindId(objid).Apply(change, food)
-	return food, err
+// UpdateFoodAvailability updates fields that don't require approval
+func UpdateFoodAvailability(objid bson.ObjectId, food *model.Food, authorID bson.ObjectId, authorRole string) (*model.Food, error) {
+	return RecordFoodVersion(objid, bson.M{"$set": bson.M{
+		"soldOut":   food.SoldOut,
+		"enabled":   food.Enabled,
+		"updatedAt": timeHelper.GetCurrentTime(),
+	}}, authorID, authorRole, "", "")
 }
 
 // DeleteFood deletes food with object id
@@ -143,6 +142,7 @@ func ReadMostPopularFoods(businessID bson.ObjectId, mealKindCode int) ([]*model.
 			"mealKindCodes": mealKindCode,
 			"mostPopular":   true,
 			"enabled":       true,
+			"status":        true,
 			"soldOut":       false,
 		}},
 	}
@@ -163,6 +163,7 @@ func ReadRecommendFoods(businessID bson.ObjectId, mealKindCode int) ([]*model.Fo
 			"mealKindCodes": mealKindCode,
 			"recommend":     true,
 			"enabled":       true,
+			"status":        true,
 			"soldOut":       false,
 		}},
 	}
@@ -181,6 +182,8 @@ func ReadFoodsByBusiness(query string, offset int, count int, field string, sort
 	totalCount := 0
 	pipe := []bson.M{}
 
+	// customers see only approved foods
+	pipe = append(pipe, bson.M{"$match": bson.M{"status": true}})
 	if businessID != "" {
 		pipe = append(pipe, bson.M{"$match": bson.M{"businessId": businessID}})
 	}
@@ -224,25 +227,3 @@ func RetrieveFoodBaseStructure(f *model.Food) {
 	f.Dietaries = dietaryService.ReadDietariesWithCodes(f.DietaryCodes)
 	f.MealKinds = mealKindService.ReadMealKindsWithCodes(f.MealKindCodes)
 }
-
-func ReadWithoutApprove() ([]*model.Food, error) {
-	foodCollection, session := foodCollection()
-	defer session.Close()
-
-	foods := []*model.Food{}
-	err := foodCollection.Pipe([]bson.M{
-		{"$match": bson.M{"status": false}},
-		{"$lookup": bson.M{
-			"from":         "business",
-			"localField":   "businessId",
-			"foreignField": "_id",
-			"as":           "business",
-		}},
-		{"$unwind": bson.M{
-			"path": "$business",
-			"preserveNullAndEmptyArrays": true,
-		}},
-	}).All(&foods)
-
-	return foods, err
-}
@@ -0,0 +1,282 @@
+package foodService
+
+import (
+	"errors"
+	"reflect"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../service/foodTypeService"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var revisionPipe []bson.M
+
+func foodRevisionCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("food_revision"), session
+}
+
+func initRevision() {
+	revisionPipe = []bson.M{
+		{"$lookup": bson.M{
+			"from":         "food",
+			"localField":   "foodId",
+			"foreignField": "_id",
+			"as":           "food",
+		}},
+		{"$unwind": bson.M{"path": "$food", "preserveNullAndEmptyArrays": true}},
+		{"$lookup": bson.M{
+			"from":         "business",
+			"localField":   "businessId",
+			"foreignField": "_id",
+			"as":           "business",
+		}},
+		{"$unwind": bson.M{"path": "$business", "preserveNullAndEmptyArrays": true}},
+	}
+
+	// foods that were created before approval workflow should be reviewed too
+	foodCollection, session := foodCollection()
+	defer session.Close()
+
+	foods := []*model.Food{}
+	foodCollection.Find(bson.M{"status": false}).All(&foods)
+	for _, food := range foods {
+		if r, err := ReadPendingFoodRevision(food.ID); err == nil && r.ID != "" {
+			continue
+		}
+		CreateFoodRevision(food, food, config.RevisionCreate)
+	}
+}
+
+// ContentChanged returns true when proposed food changes fields that require approval
+func ContentChanged(current *model.Food, proposed *model.Food) bool {
+	return current.Name != proposed.Name ||
+		current.Description != proposed.Description ||
+		current.Price != proposed.Price ||
+		current.Image != proposed.Image ||
+		!reflect.DeepEqual(current.FoodType, proposed.FoodType) ||
+		!reflect.DeepEqual(current.MealKindCodes, proposed.MealKindCodes) ||
+		!reflect.DeepEqual(current.DietaryCodes, proposed.DietaryCodes)
+}
+
+// CreateFoodRevision creates pending revision of food, pending revision is replaced with newer one
+func CreateFoodRevision(food *model.Food, proposed *model.Food, revisionType string) (*model.FoodRevision, error) {
+	foodRevisionCollection, session := foodRevisionCollection()
+	defer session.Close()
+
+	proposed.PendingRevision = nil
+	revision := &model.FoodRevision{}
+	change := mgo.Change{
+		Update: bson.M{
+			"$set": bson.M{
+				"proposed":  proposed,
+				"updatedAt": timeHelper.GetCurrentTime(),
+			},
+			"$setOnInsert": bson.M{
+				"_id":        bson.NewObjectId(),
+				"foodId":     food.ID,
+				"businessId": food.BusinessID,
+				"type":       revisionType,
+				"status":     config.RevisionPending,
+				"createdAt":  timeHelper.GetCurrentTime(),
+			},
+		},
+		Upsert:    true,
+		ReturnNew: true,
+	}
+	_, err := foodRevisionCollection.Find(bson.M{
+		"foodId": food.ID,
+		"status": config.RevisionPending,
+	}).Apply(change, revision)
+	return revision, err
+}
+
+// CreateFoodTypeRevision creates pending revision of food type, pending revision is replaced with newer one
+func CreateFoodTypeRevision(foodType *model.FoodType, proposed *model.FoodType) (*model.FoodRevision, error) {
+	foodRevisionCollection, session := foodRevisionCollection()
+	defer session.Close()
+
+	revision := &model.FoodRevision{}
+	change := mgo.Change{
+		Update: bson.M{
+			"$set": bson.M{
+				"proposedFoodType": proposed,
+				"updatedAt":        timeHelper.GetCurrentTime(),
+			},
+			"$setOnInsert": bson.M{
+				"_id":        bson.NewObjectId(),
+				"foodTypeId": foodType.ID,
+				"businessId": foodType.BusinessID,
+				"type":       config.RevisionFoodType,
+				"status":     config.RevisionPending,
+				"createdAt":  timeHelper.GetCurrentTime(),
+			},
+		},
+		Upsert:    true,
+		ReturnNew: true,
+	}
+	_, err := foodRevisionCollection.Find(bson.M{
+		"foodTypeId": foodType.ID,
+		"status":     config.RevisionPending,
+	}).Apply(change, revision)
+	return revision, err
+}
+
+// ReadPendingFoodTypeRevision returns pending revision of food type
+func ReadPendingFoodTypeRevision(foodTypeID bson.ObjectId) (*model.FoodRevision, error) {
+	foodRevisionCollection, session := foodRevisionCollection()
+	defer session.Close()
+
+	revision := &model.FoodRevision{}
+	err := foodRevisionCollection.Find(bson.M{
+		"foodTypeId": foodTypeID,
+		"status":     config.RevisionPending,
+	}).One(revision)
+	return revision, err
+}
+
+// ReadFoodRevision returns revision with object id
+func ReadFoodRevision(objid bson.ObjectId) (*model.FoodRevision, error) {
+	foodRevisionCollection, session := foodRevisionCollection()
+	defer session.Close()
+
+	pipe := []bson.M{{"$match": bson.M{"_id": objid}}}
+	pipe = append(pipe, revisionPipe...)
+	revision := &model.FoodRevision{}
+	err := foodRevisionCollection.Pipe(pipe).One(revision)
+	return revision, err
+}
+
+// ReadPendingFoodRevision returns pending revision of food
+func ReadPendingFoodRevision(foodID bson.ObjectId) (*model.FoodRevision, error) {
+	foodRevisionCollection, session := foodRevisionCollection()
+	defer session.Close()
+
+	revision := &model.FoodRevision{}
+	err := foodRevisionCollection.Find(bson.M{
+		"foodId": foodID,
+		"status": config.RevisionPending,
+	}).One(revision)
+	return revision, err
+}
+
+// ReadPendingFoodRevisions returns revisions that admin should review
+func ReadPendingFoodRevisions(businessID bson.ObjectId, offset int, count int) ([]*model.FoodRevision, int, error) {
+	foodRevisionCollection, session := foodRevisionCollection()
+	defer session.Close()
+
+	match := bson.M{"status": config.RevisionPending}
+	if businessID != "" {
+		match["businessId"] = businessID
+	}
+	pipe := []bson.M{{"$match": match}}
+	// get total count of collection with initial query
+	totalCount := db.GetCountOfCollection(foodRevisionCollection, &pipe)
+
+	pipe = append(pipe, bson.M{"$sort": bson.M{"updatedAt": 1}})
+	// add page feature
+	if offset == 0 && count == 0 {
+	} else {
+		pipe = append(pipe, bson.M{"$skip": offset})
+		pipe = append(pipe, bson.M{"$limit": count})
+	}
+	pipe = append(pipe, revisionPipe...)
+
+	revisions := []*model.FoodRevision{}
+	err := foodRevisionCollection.Pipe(pipe).All(&revisions)
+	return revisions, totalCount, err
+}
+
+// ReadFoodRevisions returns approval history of food
+func ReadFoodRevisions(foodID bson.ObjectId) ([]*model.FoodRevision, error) {
+	foodRevisionCollection, session := foodRevisionCollection()
+	defer session.Close()
+
+	revisions := []*model.FoodRevision{}
+	err := foodRevisionCollection.Find(bson.M{"foodId": foodID}).Sort("-createdAt").All(&revisions)
+	return revisions, err
+}
+
+// ApproveFoodRevision applies proposed food or food type to live one
+func ApproveFoodRevision(objid bson.ObjectId, reviewerID bson.ObjectId, comment string) (*model.FoodRevision, error) {
+	revision, err := reviewFoodRevision(objid, reviewerID, comment, config.RevisionApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	if revision.Type == config.RevisionFoodType {
+		proposed := revision.ProposedFoodType
+		_, err = foodTypeService.RecordFoodTypeVersion(revision.FoodTypeID, bson.M{"$set": bson.M{
+			"name":        proposed.Name,
+			"description": proposed.Description,
+			"image":       proposed.Image,
+			"foodOptions": proposed.FoodOption,
+			"updatedAt":   timeHelper.GetCurrentTime(),
+		}}, revision.BusinessID, config.RoleBusiness, comment)
+	} else {
+		proposed := revision.Proposed
+		_, err = RecordFoodVersion(revision.FoodID, bson.M{"$set": bson.M{
+			"foodType":      proposed.FoodType,
+			"mealKindCodes": proposed.MealKindCodes,
+			"dietaryCodes":  proposed.DietaryCodes,
+			"image":         proposed.Image,
+			"name":          proposed.Name,
+			"description":   proposed.Description,
+			"price":         proposed.Price,
+			"status":        true,
+			"updatedAt":     timeHelper.GetCurrentTime(),
+		}}, revision.BusinessID, config.RoleBusiness, revision.ID, comment)
+	}
+	if err != nil {
+		// give back revision to review queue
+		foodRevisionCollection, revisionSession := foodRevisionCollection()
+		defer revisionSession.Close()
+		foodRevisionCollection.UpdateId(objid, bson.M{
+			"$set":   bson.M{"status": config.RevisionPending},
+			"$unset": bson.M{"reviewerId": "", "reviewedAt": ""},
+		})
+		return nil, err
+	}
+	return revision, nil
+}
+
+// RejectFoodRevision rejects proposed food with comment
+func RejectFoodRevision(objid bson.ObjectId, reviewerID bson.ObjectId, comment string) (*model.FoodRevision, error) {
+	if comment == "" {
+		return nil, errors.New("Comment is required to reject revision")
+	}
+	return reviewFoodRevision(objid, reviewerID, comment, config.RevisionRejected)
+}
+
+func reviewFoodRevision(objid bson.ObjectId, reviewerID bson.ObjectId, comment string, status string) (*model.FoodRevision, error) {
+	foodRevisionCollection, session := foodRevisionCollection()
+	defer session.Close()
+
+	revision := &model.FoodRevision{}
+	change := mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"status":     status,
+			"comment":    comment,
+			"reviewerId": reviewerID,
+			"reviewedAt": timeHelper.GetCurrentTime(),
+			"updatedAt":  timeHelper.GetCurrentTime(),
+		}},
+		ReturnNew: true,
+	}
+	// only pending revision can be reviewed
+	if _, err := foodRevisionCollection.Find(bson.M{
+		"_id":    objid,
+		"status": config.RevisionPending,
+	}).Apply(change, revision); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, errors.New("This revision is reviewed already")
+		}
+		return nil, err
+	}
+	return revision, nil
+}
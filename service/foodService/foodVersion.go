@@ -0,0 +1,125 @@
+package foodService
+
+import (
+	"errors"
+	"strconv"
+
+	"../../db"
+	"../../model"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func foodVersionCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("food_version"), session
+}
+
+func initVersion() {
+	foodVersionCollection, session := foodVersionCollection()
+	defer session.Close()
+
+	foodVersionCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"foodId", "version"},
+		Unique: true,
+	})
+
+	// foods that were created before versioning get their first version
+	foodCollection, foodSession := foodCollection()
+	defer foodSession.Close()
+
+	foods := []*model.Food{}
+	foodCollection.Find(bson.M{"version": bson.M{"$exists": false}}).All(&foods)
+	for _, food := range foods {
+		RecordFoodVersion(food.ID, nil, "", "", "", "Initial version")
+	}
+}
+
+// RecordFoodVersion applies update to food with increased version and saves snapshot of that version,
+// update is empty when content of food is written already
+func RecordFoodVersion(objid bson.ObjectId, update bson.M, authorID bson.ObjectId, authorRole string, revisionID bson.ObjectId, comment string) (*model.Food, error) {
+	foodCollection, session := foodCollection()
+	defer session.Close()
+
+	if update == nil {
+		update = bson.M{}
+	}
+	update["$inc"] = bson.M{"version": 1}
+	food := &model.Food{}
+	change := mgo.Change{
+		Update:    update,
+		ReturnNew: true,
+	}
+	if _, err := foodCollection.FindId(objid).Apply(change, food); err != nil {
+		return nil, err
+	}
+
+	foodVersionCollection, versionSession := foodVersionCollection()
+	defer versionSession.Close()
+
+	err := foodVersionCollection.Insert(&model.FoodVersion{
+		ID:         bson.NewObjectId(),
+		FoodID:     food.ID,
+		Version:    food.Version,
+		Food:       food,
+		AuthorID:   authorID,
+		AuthorRole: authorRole,
+		RevisionID: revisionID,
+		Comment:    comment,
+		CreatedAt:  timeHelper.GetCurrentTime(),
+	})
+	return food, err
+}
+
+// ReadFoodVersion returns snapshot of food with version number
+func ReadFoodVersion(foodID bson.ObjectId, version int) (*model.FoodVersion, error) {
+	foodVersionCollection, session := foodVersionCollection()
+	defer session.Close()
+
+	foodVersion := &model.FoodVersion{}
+	err := foodVersionCollection.Find(bson.M{"foodId": foodID, "version": version}).One(foodVersion)
+	return foodVersion, err
+}
+
+// ReadFoodVersions returns history of food, from and to limit created date when they are not zero
+func ReadFoodVersions(foodID bson.ObjectId, from int64, to int64) ([]*model.FoodVersion, error) {
+	foodVersionCollection, session := foodVersionCollection()
+	defer session.Close()
+
+	query := bson.M{"foodId": foodID}
+	createdAt := bson.M{}
+	if from != 0 {
+		createdAt["$gte"] = from
+	}
+	if to != 0 {
+		createdAt["$lte"] = to
+	}
+	if len(createdAt) > 0 {
+		query["createdAt"] = createdAt
+	}
+	foodVersions := []*model.FoodVersion{}
+	err := foodVersionCollection.Find(query).Sort("-version").All(&foodVersions)
+	return foodVersions, err
+}
+
+// RollbackFood applies content of old version to food and records it as new version
+func RollbackFood(objid bson.ObjectId, version int, authorID bson.ObjectId, authorRole string) (*model.Food, error) {
+	foodVersion, err := ReadFoodVersion(objid, version)
+	if err != nil {
+		return nil, errors.New("This version is not existed")
+	}
+
+	snapshot := foodVersion.Food
+	return RecordFoodVersion(objid, bson.M{"$set": bson.M{
+		"foodType":      snapshot.FoodType,
+		"mealKindCodes": snapshot.MealKindCodes,
+		"dietaryCodes":  snapshot.DietaryCodes,
+		"image":         snapshot.Image,
+		"name":          snapshot.Name,
+		"description":   snapshot.Description,
+		"price":         snapshot.Price,
+		"updatedAt":     timeHelper.GetCurrentTime(),
+	}}, authorID, authorRole, "", "Rollback to version "+strconv.Itoa(version))
+}
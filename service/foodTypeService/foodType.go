@@ -32,6 +32,7 @@ func InitService() {
 			"path": "$business",
 			"preserveNullAndEmptyArrays": true}},
 	}
+	initVersion()
 }
 
 // CreateFoodType creates foodType
@@ -69,7 +70,7 @@ func ReadFoodType(objid bson.ObjectId) (*model.FoodType, error) {
 }
 
 // UpdateFoodType updates foodType
-func UpdateFoodType(objid bson.ObjectId, foodType *model.FoodType) (*model.FoodType, error) {
+func UpdateFoodType(objid bson.ObjectId, foodType *model.FoodType, authorID bson.ObjectId, authorRole string) (*model.FoodType, error) {
 	foodTypeCollection, session := foodTypeCollection()
 	defer session.Close()
 	// check duplicate name
@@ -80,20 +81,12 @@ func UpdateFoodType(objid bson.ObjectId, foodType *model.FoodType) (*model.FoodT
 		return nil, errors.New("Same foodType is registered already in same business")
 	}
 
-	foodType.UpdatedAt = timeHelper.GetCurrentTime()
-	// Create change info
-	change := mgo.Change{
-		Update: bson.M{"$set": bson.M{
-			"name":        foodType.Name,
-			"description": foodType.Description,
-			"image":       foodType.Image,
-			"updatedAt":   foodType.UpdatedAt,
-		}},
-		ReturnNew: true,
-	}
-	// Update foodType
-	_, err := foodTypeCollection.FindId(objid).Apply(change, foodType)
-	return foodType, err
+	return RecordFoodTypeVersion(objid, bson.M{"$set": bson.M{
+		"name":        foodType.Name,
+		"description": foodType.Description,
+		"image":       foodType.Image,
+		"updatedAt":   timeHelper.GetCurrentTime(),
+	}}, authorID, authorRole, "")
 }
 
 // DeleteFoodType deletes foodType with object id
@@ -166,13 +159,10 @@ func ReadFoodTypesWithBusiness(businessID bson.ObjectId) ([]*model.FoodType, err
 }
 
 // CreateSpec creates food option in foodtype
-func CreateSpec(objid bson.ObjectId, spec *model.FoodOption) (*model.FoodOption, error) {
-	foodTypeCollection, session := foodTypeCollection()
-	defer session.Close()
-
+func CreateSpec(objid bson.ObjectId, spec *model.FoodOption, authorID bson.ObjectId, authorRole string) (*model.FoodOption, error) {
 	number := random.GenerateRandomString(6)
 	spec.Number = number
-	err := foodTypeCollection.UpdateId(objid, bson.M{"$addToSet": bson.M{"foodOptions": spec}})
+	_, err := RecordFoodTypeVersion(objid, bson.M{"$addToSet": bson.M{"foodOptions": spec}}, authorID, authorRole, "")
 
 	return spec, err
 }
@@ -193,23 +183,17 @@ func ReadSpec(objid bson.ObjectId, number string) (*model.FoodOption, error) {
 }
 
 // UpdateSpec update food option in foodtype
-func UpdateSpec(objid bson.ObjectId, number string, spec *model.FoodOption) (*model.FoodOption, error) {
-	foodTypeCollection, session := foodTypeCollection()
-	defer session.Close()
-
-	err := foodTypeCollection.Update(bson.M{"_id": objid, "foodOptions.number": number},
-		bson.M{"$set": bson.M{"foodOptions.$.options": spec.Options}})
+func UpdateSpec(objid bson.ObjectId, number string, spec *model.FoodOption, authorID bson.ObjectId, authorRole string) (*model.FoodOption, error) {
+	_, err := recordFoodTypeVersion(bson.M{"_id": objid, "foodOptions.number": number},
+		bson.M{"$set": bson.M{"foodOptions.$.options": spec.Options}}, authorID, authorRole, "")
 
 	return spec, err
 }
 
 // DeleteSpec delete food option in foodtype
-func DeleteSpec(objid bson.ObjectId, number string) error {
-	foodTypeCollection, session := foodTypeCollection()
-	defer session.Close()
-
-	err := foodTypeCollection.Update(bson.M{"_id": objid},
-		bson.M{"$pull": bson.M{"foodOptions": bson.M{"number": number}}})
+func DeleteSpec(objid bson.ObjectId, number string, authorID bson.ObjectId, authorRole string) error {
+	_, err := RecordFoodTypeVersion(objid,
+		bson.M{"$pull": bson.M{"foodOptions": bson.M{"number": number}}}, authorID, authorRole, "")
 
 	return err
 }
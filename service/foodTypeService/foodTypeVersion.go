@@ -0,0 +1,125 @@
+package foodTypeService
+
+import (
+	"errors"
+	"strconv"
+
+	"../../db"
+	"../../model"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func foodTypeVersionCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("food_type_version"), session
+}
+
+func initVersion() {
+	foodTypeVersionCollection, session := foodTypeVersionCollection()
+	defer session.Close()
+
+	foodTypeVersionCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"foodTypeId", "version"},
+		Unique: true,
+	})
+
+	// food types that were created before versioning get their first version
+	foodTypeCollection, foodTypeSession := foodTypeCollection()
+	defer foodTypeSession.Close()
+
+	foodTypes := []*model.FoodType{}
+	foodTypeCollection.Find(bson.M{"version": bson.M{"$exists": false}}).All(&foodTypes)
+	for _, foodType := range foodTypes {
+		RecordFoodTypeVersion(foodType.ID, nil, "", "", "Initial version")
+	}
+}
+
+// RecordFoodTypeVersion applies update to food type with increased version and saves snapshot of that version
+// with options, update is empty when content of food type is written already
+func RecordFoodTypeVersion(objid bson.ObjectId, update bson.M, authorID bson.ObjectId, authorRole string, comment string) (*model.FoodType, error) {
+	return recordFoodTypeVersion(bson.M{"_id": objid}, update, authorID, authorRole, comment)
+}
+
+func recordFoodTypeVersion(query bson.M, update bson.M, authorID bson.ObjectId, authorRole string, comment string) (*model.FoodType, error) {
+	foodTypeCollection, session := foodTypeCollection()
+	defer session.Close()
+
+	if update == nil {
+		update = bson.M{}
+	}
+	update["$inc"] = bson.M{"version": 1}
+	foodType := &model.FoodType{}
+	change := mgo.Change{
+		Update:    update,
+		ReturnNew: true,
+	}
+	if _, err := foodTypeCollection.Find(query).Apply(change, foodType); err != nil {
+		return nil, err
+	}
+
+	foodTypeVersionCollection, versionSession := foodTypeVersionCollection()
+	defer versionSession.Close()
+
+	err := foodTypeVersionCollection.Insert(&model.FoodTypeVersion{
+		ID:         bson.NewObjectId(),
+		FoodTypeID: foodType.ID,
+		Version:    foodType.Version,
+		FoodType:   foodType,
+		AuthorID:   authorID,
+		AuthorRole: authorRole,
+		Comment:    comment,
+		CreatedAt:  timeHelper.GetCurrentTime(),
+	})
+	return foodType, err
+}
+
+// ReadFoodTypeVersion returns snapshot of food type with version number
+func ReadFoodTypeVersion(foodTypeID bson.ObjectId, version int) (*model.FoodTypeVersion, error) {
+	foodTypeVersionCollection, session := foodTypeVersionCollection()
+	defer session.Close()
+
+	foodTypeVersion := &model.FoodTypeVersion{}
+	err := foodTypeVersionCollection.Find(bson.M{"foodTypeId": foodTypeID, "version": version}).One(foodTypeVersion)
+	return foodTypeVersion, err
+}
+
+// ReadFoodTypeVersions returns history of food type, from and to limit created date when they are not zero
+func ReadFoodTypeVersions(foodTypeID bson.ObjectId, from int64, to int64) ([]*model.FoodTypeVersion, error) {
+	foodTypeVersionCollection, session := foodTypeVersionCollection()
+	defer session.Close()
+
+	query := bson.M{"foodTypeId": foodTypeID}
+	createdAt := bson.M{}
+	if from != 0 {
+		createdAt["$gte"] = from
+	}
+	if to != 0 {
+		createdAt["$lte"] = to
+	}
+	if len(createdAt) > 0 {
+		query["createdAt"] = createdAt
+	}
+	foodTypeVersions := []*model.FoodTypeVersion{}
+	err := foodTypeVersionCollection.Find(query).Sort("-version").All(&foodTypeVersions)
+	return foodTypeVersions, err
+}
+
+// RollbackFoodType applies old version of food type and its options and records it as new version
+func RollbackFoodType(objid bson.ObjectId, version int, authorID bson.ObjectId, authorRole string) (*model.FoodType, error) {
+	foodTypeVersion, err := ReadFoodTypeVersion(objid, version)
+	if err != nil {
+		return nil, errors.New("This version is not existed")
+	}
+
+	snapshot := foodTypeVersion.FoodType
+	return RecordFoodTypeVersion(objid, bson.M{"$set": bson.M{
+		"name":        snapshot.Name,
+		"description": snapshot.Description,
+		"image":       snapshot.Image,
+		"foodOptions": snapshot.FoodOption,
+		"updatedAt":   timeHelper.GetCurrentTime(),
+	}}, authorID, authorRole, "Rollback to version "+strconv.Itoa(version))
+}
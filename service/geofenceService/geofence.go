@@ -0,0 +1,146 @@
+package geofenceService
+
+import (
+	"../../config"
+	"../../model"
+	"../../util/geo"
+	"../../util/timeHelper"
+	"../driverLocationService"
+	"../orderService"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Fence returns geofence that trip goes to next, its center and trip status that entering it means
+func Fence(order *model.Order) (string, []float64, float64, string) {
+	switch order.TripStatus {
+	case config.TripAccepted, config.TripConfirmed, config.TripStarted:
+		if order.Business != nil && len(order.Business.GeoLocation.GeoJSON.Coordinates) >= 2 {
+			return config.GeofencePickup, order.Business.GeoLocation.GeoJSON.Coordinates, config.GeofencePickupRadius, config.TripArrived
+		}
+	case config.TripArrived:
+		if order.DeliveryLocation != nil && len(order.DeliveryLocation.GeoJSON.Coordinates) >= 2 {
+			return config.GeofenceDropoff, order.DeliveryLocation.GeoJSON.Coordinates, config.GeofenceDropoffRadius, config.TripDropped
+		}
+	}
+	return "", nil, 0, ""
+}
+
+// ExpectedPoint returns point that driver should be at when trip status is marked
+func ExpectedPoint(order *model.Order, tripStatus string) []float64 {
+	switch tripStatus {
+	case config.TripArrived:
+		if order.Business != nil {
+			return order.Business.GeoLocation.GeoJSON.Coordinates
+		}
+	case config.TripDropped:
+		if order.DeliveryLocation != nil {
+			return order.DeliveryLocation.GeoJSON.Coordinates
+		}
+	}
+	return nil
+}
+
+// CheckGeofence updates state of driver in geofence of trip with location,
+// returns trip status when driver dwelled in fence and whether state is changed
+func CheckGeofence(order *model.Order, location []float64, now int64) (string, *model.TripGeofence, bool) {
+	fence, center, radius, transition := Fence(order)
+	if fence == "" || len(location) < 2 {
+		return "", order.Geofence, false
+	}
+
+	state := &model.TripGeofence{Fence: fence}
+	if order.Geofence != nil && order.Geofence.Fence == fence {
+		*state = *order.Geofence
+	}
+	changed := order.Geofence == nil || order.Geofence.Fence != fence
+
+	distance := geo.DistanceOfCoordinates(location, center)
+	switch {
+	case distance <= radius:
+		if state.EnteredAt == 0 {
+			state.EnteredAt = now
+		}
+		state.LastAt = now
+		changed = true
+	case distance > radius+config.GeofenceHysteresis && state.EnteredAt != 0:
+		// driver left fence, dwell starts again at next entry
+		state.EnteredAt = 0
+		changed = true
+	}
+
+	if state.EnteredAt != 0 && state.Triggered == "" && state.LastAt-state.EnteredAt >= config.GeofenceDwell {
+		state.Triggered = transition
+		return transition, state, true
+	}
+	return "", state, changed
+}
+
+// CheckAnomaly returns anomaly when driver marked trip status far from expected point,
+// location that is stale or of other driver isn't judged
+func CheckAnomaly(order *model.Order, tripStatus string, location *model.DriverLocation, now int64) *model.TripAnomaly {
+	expected := ExpectedPoint(order, tripStatus)
+	if len(expected) < 2 || location == nil || location.DriverID != order.DriverID || len(location.Location.Coordinates) < 2 {
+		return nil
+	}
+	if now-location.UpdatedAt > config.GeofenceLocationMaxAge {
+		return nil
+	}
+	distance := geo.DistanceOfCoordinates(location.Location.Coordinates, expected)
+	if distance <= config.GeofenceAnomalyDistance {
+		return nil
+	}
+	point := location.Location
+	return &model.TripAnomaly{
+		TripStatus: tripStatus,
+		Distance:   distance,
+		Location:   &point,
+		At:         now,
+	}
+}
+
+// ProcessLocation checks geofence of trip that driver is on with location update,
+// returns trip and trip status when driver dwelled in fence of next transition
+func ProcessLocation(driverLocation *model.DriverLocation) (*model.Order, string, error) {
+	trip, err := orderService.ReadActiveTrip(driverLocation.DriverID)
+	if err == mgo.ErrNotFound {
+		return nil, "", nil // driver isn't on trip
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	order, err := orderService.ReadOrder(trip.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	transition, state, changed := CheckGeofence(order, driverLocation.Location.Coordinates, timeHelper.GetCurrentTime())
+	if changed {
+		if err := orderService.UpdateTripGeofence(order.ID, state); err != nil {
+			return nil, "", err
+		}
+	}
+	return order, transition, nil
+}
+
+// FlagTransition flags trip status that driver marked when driver isn't near expected point
+func FlagTransition(orderID bson.ObjectId, tripStatus string) (*model.TripAnomaly, error) {
+	order, err := orderService.ReadOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	location, err := driverLocationService.ReadDriverLocation(order.DriverID)
+	if err == mgo.ErrNotFound {
+		return nil, nil // driver never sent location
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	anomaly := CheckAnomaly(order, tripStatus, location, timeHelper.GetCurrentTime())
+	if anomaly == nil {
+		return nil, nil
+	}
+	return anomaly, orderService.FlagTripAnomaly(orderID, anomaly)
+}
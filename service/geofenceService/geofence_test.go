@@ -0,0 +1,86 @@
+package geofenceService
+
+import (
+	"testing"
+
+	"../../config"
+	"../../model"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func testOrder() *model.Order {
+	return &model.Order{
+		ID:               bson.NewObjectId(),
+		DriverID:         bson.NewObjectId(),
+		Business:         &model.Business{GeoLocation: model.GeoLocation{GeoJSON: model.GeoJSON{Coordinates: []float64{0, 0}}}},
+		DeliveryLocation: &model.GeoLocation{GeoJSON: model.GeoJSON{Coordinates: []float64{0, 0.02}}},
+		TripStatus:       config.TripStarted,
+	}
+}
+
+func TestCheckGeofence(t *testing.T) {
+	order := testOrder()
+	inside := []float64{0, 0.0005} // about 55m from business
+	edge := []float64{0, 0.0011}   // about 120m, in hysteresis
+	outside := []float64{0, 0.002} // about 220m
+	now := int64(1000)
+
+	transition, state, _ := CheckGeofence(order, inside, now)
+	if transition != "" || state.Fence != config.GeofencePickup || state.EnteredAt != now {
+		t.Fatalf("entry = %s, %+v", transition, state)
+	}
+	// jitter on edge keeps dwell
+	order.Geofence = state
+	if _, state, _ = CheckGeofence(order, edge, now+20); state.EnteredAt != now {
+		t.Errorf("state after jitter = %+v", state)
+	}
+	order.Geofence = state
+	transition, state, _ = CheckGeofence(order, inside, now+config.GeofenceDwell)
+	if transition != config.TripArrived || state.Triggered != config.TripArrived {
+		t.Errorf("dwell = %s, %+v", transition, state)
+	}
+	// transition is triggered once
+	order.Geofence = state
+	if transition, _, _ = CheckGeofence(order, inside, now+2*config.GeofenceDwell); transition != "" {
+		t.Errorf("second transition = %s", transition)
+	}
+
+	// leaving fence resets dwell
+	order = testOrder()
+	_, order.Geofence, _ = CheckGeofence(order, inside, now)
+	_, order.Geofence, _ = CheckGeofence(order, outside, now+30)
+	if transition, state, _ = CheckGeofence(order, inside, now+config.GeofenceDwell); transition != "" || state.EnteredAt != now+config.GeofenceDwell {
+		t.Errorf("reentry = %s, %+v", transition, state)
+	}
+
+	// arrived trip goes to dropoff fence
+	order.TripStatus = config.TripArrived
+	if _, state, _ = CheckGeofence(order, []float64{0, 0.02}, now); state.Fence != config.GeofenceDropoff || state.Triggered != "" {
+		t.Errorf("dropoff = %+v", state)
+	}
+	order.TripStatus = config.TripDropped
+	if transition, _, changed := CheckGeofence(order, []float64{0, 0.02}, now); transition != "" || changed {
+		t.Errorf("dropped trip = %s, %v", transition, changed)
+	}
+}
+
+func TestCheckAnomaly(t *testing.T) {
+	order := testOrder()
+	location := &model.DriverLocation{DriverID: order.DriverID, Location: model.GeoJSON{Coordinates: []float64{0, 0.01}}, UpdatedAt: 1000}
+
+	anomaly := CheckAnomaly(order, config.TripArrived, location, 1030)
+	if anomaly == nil || anomaly.Distance < 1000 || anomaly.TripStatus != config.TripArrived {
+		t.Fatalf("anomaly = %+v", anomaly)
+	}
+	if anomaly = CheckAnomaly(order, config.TripDropped, location, 1030); anomaly == nil {
+		t.Error("drop far from delivery location isn't flagged")
+	}
+	if anomaly = CheckAnomaly(order, config.TripArrived, location, 1000+config.GeofenceLocationMaxAge+1); anomaly != nil {
+		t.Errorf("stale location is flagged: %+v", anomaly)
+	}
+	location.Location.Coordinates = []float64{0, 0.001}
+	if anomaly = CheckAnomaly(order, config.TripArrived, location, 1030); anomaly != nil {
+		t.Errorf("arrival near business is flagged: %+v", anomaly)
+	}
+}
@@ -0,0 +1,108 @@
+package heatmapService
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"../../config"
+	"../../model"
+	"../../util/geo"
+	"../driverLocationService"
+	"../orderService"
+)
+
+// ReadHeatmap reads demand and supply of city in window as geohash cells, every city when place is empty
+func ReadHeatmap(placeID string, window string, precision int, now int64) (*model.Heatmap, error) {
+	duration, ok := config.HeatmapWindows[window]
+	if !ok {
+		return nil, errors.New("Window of heatmap is invalid")
+	}
+	if precision < config.HeatmapMinPrecision || precision > config.HeatmapMaxPrecision {
+		return nil, errors.New("Precision of heatmap is invalid")
+	}
+	from := now - int64(duration.Seconds())
+	orders, err := orderService.ReadOrderLocations(placeID, from, now, config.HeatmapMaxOrders)
+	if err != nil {
+		return nil, err
+	}
+	drivers, err := driverLocationService.ReadOnlineLocations(placeID, now-config.HeatmapLocationMaxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	heatmap := FeatureCollection(Cells(orders, drivers, precision))
+	heatmap.Window = window
+	heatmap.Precision = precision
+	heatmap.From = from
+	heatmap.To = now
+	return heatmap, nil
+}
+
+// Cells aggregates businesses and delivery locations of orders and locations of drivers into geohash cells
+func Cells(orders []*model.Order, drivers []*model.DriverLocation, precision int) map[string]*model.HeatCell {
+	cells := map[string]*model.HeatCell{}
+	cell := func(point []float64) *model.HeatCell {
+		hash := geo.Geohash(point, precision)
+		if hash == "" {
+			return nil
+		}
+		if cells[hash] == nil {
+			cells[hash] = &model.HeatCell{Geohash: hash}
+		}
+		return cells[hash]
+	}
+
+	for _, order := range orders {
+		if order.Business != nil {
+			if pickup := cell(order.Business.GeoLocation.GeoJSON.Coordinates); pickup != nil {
+				pickup.Pickups++
+				// order that business declined is not demand of drivers
+				if order.DriverID == "" && order.OrderStatus != config.OrderDeclined {
+					pickup.Unmet++
+				}
+			}
+		}
+		if order.DeliveryLocation != nil {
+			if dropoff := cell(order.DeliveryLocation.GeoJSON.Coordinates); dropoff != nil {
+				dropoff.Dropoffs++
+			}
+		}
+	}
+	for _, driver := range drivers {
+		if supply := cell(driver.Location.Coordinates); supply != nil {
+			supply.Drivers++
+		}
+	}
+	for _, c := range cells {
+		c.Ratio = math.Floor(float64(c.Pickups)/math.Max(1, float64(c.Drivers))*100+0.5) / 100
+	}
+	return cells
+}
+
+// FeatureCollection returns cells as geojson polygons, cells with most unmet demand come first
+func FeatureCollection(cells map[string]*model.HeatCell) *model.Heatmap {
+	heatmap := &model.Heatmap{Type: "FeatureCollection", Features: []*model.HeatFeature{}}
+	for hash, cell := range cells {
+		rings, err := geo.GeohashPolygon(hash)
+		if err != nil {
+			continue
+		}
+		heatmap.Features = append(heatmap.Features, &model.HeatFeature{
+			Type:       "Feature",
+			Geometry:   model.Polygon{Type: "Polygon", Coordinates: rings},
+			Properties: cell,
+		})
+	}
+	sort.Slice(heatmap.Features, func(i, j int) bool {
+		a, b := heatmap.Features[i].Properties, heatmap.Features[j].Properties
+		if a.Unmet != b.Unmet {
+			return a.Unmet > b.Unmet
+		}
+		if a.Ratio != b.Ratio {
+			return a.Ratio > b.Ratio
+		}
+		return a.Geohash < b.Geohash
+	})
+	return heatmap
+}
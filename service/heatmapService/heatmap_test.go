@@ -0,0 +1,52 @@
+package heatmapService
+
+import (
+	"testing"
+
+	"../../config"
+	"../../model"
+	"../../util/geo"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func location(lng, lat float64) model.GeoLocation {
+	return model.GeoLocation{GeoJSON: model.GeoJSON{Type: "Point", Coordinates: []float64{lng, lat}}}
+}
+
+func TestCells(t *testing.T) {
+	downtown, uptown := location(-74.0060, 40.7128), location(-73.9680, 40.7851)
+	business := &model.Business{GeoLocation: downtown}
+	orders := []*model.Order{
+		{Business: business, DeliveryLocation: &uptown, OrderStatus: config.OrderRequest},
+		{Business: business, DeliveryLocation: &uptown, OrderStatus: config.OrderCancelled},
+		{Business: business, DeliveryLocation: &downtown, OrderStatus: config.OrderDeclined},
+		{Business: business, DeliveryLocation: &uptown, OrderStatus: config.OrderCompleted, DriverID: bson.NewObjectId()},
+		{OrderStatus: config.OrderRequest},
+	}
+	drivers := []*model.DriverLocation{{Location: uptown.GeoJSON}, {Location: uptown.GeoJSON}}
+
+	cells := Cells(orders, drivers, 6)
+	if len(cells) != 2 {
+		t.Fatalf("cells = %v", cells)
+	}
+	pickup := cells[geo.Geohash(downtown.GeoJSON.Coordinates, 6)]
+	if pickup.Pickups != 4 || pickup.Unmet != 2 || pickup.Dropoffs != 1 || pickup.Drivers != 0 || pickup.Ratio != 4 {
+		t.Errorf("downtown cell = %+v", pickup)
+	}
+	dropoff := cells[geo.Geohash(uptown.GeoJSON.Coordinates, 6)]
+	if dropoff.Pickups != 0 || dropoff.Dropoffs != 3 || dropoff.Drivers != 2 || dropoff.Ratio != 0 {
+		t.Errorf("uptown cell = %+v", dropoff)
+	}
+
+	heatmap := FeatureCollection(cells)
+	if heatmap.Type != "FeatureCollection" || len(heatmap.Features) != 2 {
+		t.Fatalf("heatmap = %+v", heatmap)
+	}
+	if first := heatmap.Features[0]; first.Properties != pickup || first.Type != "Feature" || first.Geometry.Type != "Polygon" {
+		t.Errorf("cell with unmet demand is not first: %+v", first)
+	}
+	if !geo.InPolygon(downtown.GeoJSON.Coordinates, heatmap.Features[0].Geometry.Coordinates) {
+		t.Error("polygon of cell doesn't contain its orders")
+	}
+}
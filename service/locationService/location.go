@@ -36,6 +36,7 @@ func InitService() {
 			"countryCode":  bson.M{"$first": "$countryCode"},
 			"city":         bson.M{"$first": "$city"},
 			"placeId":      bson.M{"$first": "$placeId"},
+			"searchRadius": bson.M{"$first": "$searchRadius"},
 			"vehicleInfos": bson.M{"$push": "$$ROOT.vehicleInfos"}}},
 	}
 }
@@ -98,16 +99,17 @@ func UpdateLocation(objid bson.ObjectId, location *model.Location) (*model.Locat
 	// Create change info
 	change := mgo.Change{
 		Update: bson.M{"$set": bson.M{
-			"countryCode": location.CountryCode,
-			"city":        location.City,
-			"latitude":    location.Latitude,
-			"longitude":   location.Longitude,
-			"placeId":     location.PlaceID,
-			"isDayTime":   location.IsDayTime,
-			"dayTime":     location.DayTime,
-			"isNightTime": location.IsNightTime,
-			"nightTime":   location.NightTime,
-			"updatedAt":   location.UpdatedAt,
+			"countryCode":  location.CountryCode,
+			"city":         location.City,
+			"latitude":     location.Latitude,
+			"longitude":    location.Longitude,
+			"placeId":      location.PlaceID,
+			"isDayTime":    location.IsDayTime,
+			"dayTime":      location.DayTime,
+			"isNightTime":  location.IsNightTime,
+			"nightTime":    location.NightTime,
+			"searchRadius": location.SearchRadius,
+			"updatedAt":    location.UpdatedAt,
 		}},
 		ReturnNew: true,
 	}
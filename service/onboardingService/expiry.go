@@ -0,0 +1,156 @@
+package onboardingService
+
+import (
+	"../../config"
+	"../../model"
+	"../../service/authService/driverService"
+	"../../service/documentService"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const daySeconds = 24 * 3600
+
+// ScanExpiry reminds and expires documents of drivers at time now, returns expiries that drivers are notified
+func ScanExpiry(now int64) (map[bson.ObjectId][]*model.DocumentExpiry, error) {
+	documents, err := documentService.ReadExpiringDocuments()
+	if err != nil {
+		return nil, err
+	}
+	maxDays := 0
+	for _, days := range config.DocumentReminderDays {
+		if days > maxDays {
+			maxDays = days
+		}
+	}
+	drivers, err := driverService.ReadDriversWithExpiringDocuments(now + int64(maxDays)*daySeconds)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[bson.ObjectId][]*model.DocumentExpiry{}
+	for _, driver := range drivers {
+		// documents as they are read, document that driver uploads again during scan is not overwritten
+		read := map[*model.VerifyDocument]model.VerifyDocument{}
+		for _, vehicle := range driver.DriverVehicles {
+			for _, uploaded := range vehicle.Documents {
+				read[uploaded] = *uploaded
+			}
+		}
+		expiries, vehicles := CheckExpiry(driver, documents, now)
+		updated := map[bson.ObjectId]bool{}
+		for _, vehicle := range vehicles {
+			for _, uploaded := range vehicle.Documents {
+				before := read[uploaded]
+				if before.Status == uploaded.Status && before.Reminded == uploaded.Reminded {
+					continue
+				}
+				err := driverService.UpdateVehicleDocument(driver.ID, vehicle.Number, uploaded.ID,
+					bson.M{"image": before.Image, "status": before.Status, "expireDate": before.ExpireDate},
+					bson.M{"status": uploaded.Status, "reminded": uploaded.Reminded})
+				if err == mgo.ErrNotFound {
+					continue
+				}
+				if err != nil {
+					return result, err
+				}
+				updated[uploaded.ID] = true
+			}
+		}
+		expiries = updatedExpiries(expiries, updated)
+		for _, expiry := range expiries {
+			if expiry.Days == 0 {
+				// driver or vehicle is taken offline until new document is approved
+				RefreshOnboarding(driver.ID)
+				break
+			}
+		}
+		if len(expiries) > 0 {
+			result[driver.ID] = expiries
+		}
+	}
+	return result, nil
+}
+
+// CheckExpiry marks expired documents of driver and reminded days of expiring documents,
+// returns expiries to notify and vehicles that documents are changed
+func CheckExpiry(driver *model.Driver, documents []*model.Document, now int64) ([]*model.DocumentExpiry, []*model.DriverVehicle) {
+	expiries := []*model.DocumentExpiry{}
+	vehicles := []*model.DriverVehicle{}
+	for _, vehicle := range driver.DriverVehicles {
+		changed := false
+		for _, uploaded := range vehicle.Documents {
+			if uploaded.ExpireDate == 0 || (uploaded.Status != config.DocumentPending && uploaded.Status != config.DocumentAccepted) {
+				continue
+			}
+			var document *model.Document
+			for _, expiring := range documents {
+				if expiring.ID == uploaded.ID && expiring.IsExpired {
+					document = expiring
+				}
+			}
+			if document == nil {
+				continue
+			}
+
+			expiry := &model.DocumentExpiry{
+				Number:     vehicle.Number,
+				DocumentID: document.ID,
+				Name:       document.Name,
+				ExpireDate: uploaded.ExpireDate,
+			}
+			left := uploaded.ExpireDate - now
+			if left <= 0 {
+				uploaded.Status = config.DocumentExpired
+			} else {
+				days := ReminderDays(left)
+				if days == 0 || (uploaded.Reminded != 0 && uploaded.Reminded <= days) {
+					continue
+				}
+				uploaded.Reminded = days
+				expiry.Days = int((left + daySeconds - 1) / daySeconds)
+			}
+			changed = true
+			// driver document uploaded with several vehicles is notified once
+			if !containsExpiry(expiries, expiry) {
+				expiries = append(expiries, expiry)
+			}
+		}
+		if changed {
+			vehicles = append(vehicles, vehicle)
+		}
+	}
+	return expiries, vehicles
+}
+
+// ReminderDays returns reminder days that seconds left before expiry fall in, 0 when it is not reminded yet
+func ReminderDays(left int64) int {
+	reminder := 0
+	for _, days := range config.DocumentReminderDays {
+		if left <= int64(days)*daySeconds && (reminder == 0 || days < reminder) {
+			reminder = days
+		}
+	}
+	return reminder
+}
+
+// updatedExpiries returns expiries of documents that are updated, document that is changed during scan is checked next time
+func updatedExpiries(expiries []*model.DocumentExpiry, updated map[bson.ObjectId]bool) []*model.DocumentExpiry {
+	result := []*model.DocumentExpiry{}
+	for _, expiry := range expiries {
+		if updated[expiry.DocumentID] {
+			result = append(result, expiry)
+		}
+	}
+	return result
+}
+
+func containsExpiry(expiries []*model.DocumentExpiry, expiry *model.DocumentExpiry) bool {
+	for _, e := range expiries {
+		if e.DocumentID == expiry.DocumentID && e.ExpireDate == expiry.ExpireDate && e.Days == expiry.Days {
+			return true
+		}
+	}
+	return false
+}
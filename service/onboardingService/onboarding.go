@@ -0,0 +1,311 @@
+package onboardingService
+
+import (
+	"errors"
+
+	"../../config"
+	"../../model"
+	"../../service/authService/driverService"
+	"../../service/documentService"
+	"../../service/driverLocationService"
+	"../../util/log"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// InitService checks onboarding of drivers that registered before onboarding review and of approved drivers
+func InitService() {
+	driverIDs, err := driverService.ReadDriverIDsToCheckOnboarding()
+	if !log.CheckErrorNoStackWithMessage(err, "Failed to read drivers to check onboarding") {
+		return
+	}
+	for _, driverID := range driverIDs {
+		RefreshOnboarding(driverID)
+	}
+}
+
+// CheckOnboarding returns onboarding of driver against required documents at time now
+func CheckOnboarding(driver *model.Driver, documents []*model.Document, now int64) *model.Onboarding {
+	onboarding := &model.Onboarding{
+		DriverID: driver.ID,
+		Status:   config.OnboardingIncomplete,
+		Reason:   "Vehicle is not registered",
+		Vehicles: []*model.OnboardingVehicle{},
+	}
+
+	for _, vehicle := range driver.DriverVehicles {
+		check := &model.OnboardingVehicle{
+			Number:    vehicle.Number,
+			VehicleID: vehicle.VehicleID,
+			Missing:   []*model.Document{},
+			Expired:   []*model.Document{},
+			Pending:   []*model.Document{},
+			Rejected:  []*model.VerifyDocument{},
+		}
+		for _, document := range documents {
+			if !requiredFor(document, vehicle.VehicleID) {
+				continue
+			}
+			uploaded := FindDocument(vehicle.Documents, document.ID)
+			if document.Type == config.DocTypeDriver {
+				uploaded = driverDocument(driver, document, now)
+			}
+			switch {
+			case uploaded == nil || uploaded.Status == config.DocumentNone:
+				check.Missing = append(check.Missing, document)
+			case uploaded.Status == config.DocumentExpired || expired(document, uploaded, now):
+				check.Expired = append(check.Expired, document)
+			case uploaded.Status == config.DocumentRejected:
+				check.Rejected = append(check.Rejected, uploaded)
+			case uploaded.Status == config.DocumentPending:
+				check.Pending = append(check.Pending, document)
+			}
+		}
+		switch {
+		case len(check.Rejected) > 0:
+			check.Status = config.OnboardingRejected
+			check.Reason = documentName(documents, check.Rejected[0].ID) + " is rejected: " + check.Rejected[0].Reason
+		case len(check.Expired) > 0:
+			check.Status = config.OnboardingIncomplete
+			check.Reason = check.Expired[0].Name + " is expired"
+		case len(check.Missing) > 0:
+			check.Status = config.OnboardingIncomplete
+			check.Reason = check.Missing[0].Name + " is not uploaded"
+		case len(check.Pending) > 0:
+			check.Status = config.OnboardingPending
+			check.Reason = check.Pending[0].Name + " is waiting for review"
+		default:
+			check.Status = config.OnboardingApproved
+		}
+		if len(onboarding.Vehicles) == 0 || statusRank(check.Status) > statusRank(onboarding.Status) {
+			onboarding.Status = check.Status
+			onboarding.Reason = check.Reason
+		}
+		onboarding.Vehicles = append(onboarding.Vehicles, check)
+	}
+	return onboarding
+}
+
+// MergeDocuments keeps review of documents that are not changed, new or changed upload waits for review again
+func MergeDocuments(previous []*model.VerifyDocument, uploaded []*model.VerifyDocument, now int64) {
+	for _, document := range uploaded {
+		old := FindDocument(previous, document.ID)
+		switch {
+		case document.Image == "":
+			document.Status = config.DocumentNone
+			document.Reason = ""
+			document.UploadedAt = 0
+			document.ReviewedAt = 0
+			document.Reminded = 0
+		case old != nil && old.Image == document.Image && old.ExpireDate == document.ExpireDate:
+			document.Status = old.Status
+			document.Reason = old.Reason
+			document.UploadedAt = old.UploadedAt
+			document.ReviewedAt = old.ReviewedAt
+			document.Reminded = old.Reminded
+		default:
+			document.Status = config.DocumentPending
+			document.Reason = ""
+			document.UploadedAt = now
+			document.ReviewedAt = 0
+			document.Reminded = 0
+		}
+	}
+}
+
+// FindDocument returns uploaded document with document id
+func FindDocument(documents []*model.VerifyDocument, documentID bson.ObjectId) *model.VerifyDocument {
+	for _, document := range documents {
+		if document.ID == documentID {
+			return document
+		}
+	}
+	return nil
+}
+
+// ReadOnboarding returns driver and onboarding of driver with current required documents
+func ReadOnboarding(driverID bson.ObjectId) (*model.Driver, *model.Onboarding, error) {
+	driver, err := driverService.ReadDriver(driverID)
+	if err != nil {
+		return nil, nil, err
+	}
+	documents, err := documentService.ReadRequiredDocuments()
+	if err != nil {
+		return nil, nil, err
+	}
+	return driver, CheckOnboarding(driver, documents, timeHelper.GetCurrentTime()), nil
+}
+
+// RefreshOnboarding saves onboarding of driver and returns whether status is changed,
+// driver is taken offline with reason when driver or vehicle of driver is not approved any more
+func RefreshOnboarding(driverID bson.ObjectId) (*model.Onboarding, bool, error) {
+	driver, onboarding, err := ReadOnboarding(driverID)
+	if err != nil {
+		return nil, false, err
+	}
+	if onboarding.Status == config.OnboardingApproved {
+		onboarding.Reason = ""
+	}
+	approvedVehicleIDs := approvedVehicles(onboarding)
+	if driver.Onboarding == onboarding.Status && driver.OfflineReason == onboarding.Reason &&
+		sameVehicles(driver.ApprovedVehicleIDs, approvedVehicleIDs) {
+		return onboarding, false, nil
+	}
+	if err := driverService.UpdateOnboarding(driverID, onboarding.Status, approvedVehicleIDs, onboarding.Reason); err != nil {
+		return onboarding, false, err
+	}
+	// driver on trip finishes it, then dispatch doesn't find driver
+	location, err := driverLocationService.ReadDriverLocation(driverID)
+	if err == nil && location.Status == config.Online &&
+		(onboarding.Status != config.OnboardingApproved || !containsVehicle(approvedVehicleIDs, location.VehicleID)) {
+		driverLocationService.UpdateDriverStatus(driverID, config.Offline)
+	}
+	return onboarding, driver.Onboarding != onboarding.Status, nil
+}
+
+// ReviewDocument approves or rejects pending document of driver's vehicle
+func ReviewDocument(driverID bson.ObjectId, number string, documentID bson.ObjectId, approve bool, reason string) (*model.VerifyDocument, error) {
+	if !approve && reason == "" {
+		return nil, errors.New("Reason is required to reject document")
+	}
+	driver, err := driverService.ReadDriver(driverID)
+	if err != nil {
+		return nil, err
+	}
+	var vehicle *model.DriverVehicle
+	for _, driverVehicle := range driver.DriverVehicles {
+		if driverVehicle.Number == number {
+			vehicle = driverVehicle
+		}
+	}
+	if vehicle == nil {
+		return nil, errors.New("Vehicle is not found")
+	}
+	document := FindDocument(vehicle.Documents, documentID)
+	if document == nil {
+		return nil, errors.New("Document is not uploaded")
+	}
+	if document.Status != config.DocumentPending {
+		return nil, errors.New("Document is not pending review")
+	}
+
+	image := document.Image
+	document.Status = config.DocumentAccepted
+	document.Reason = ""
+	if !approve {
+		document.Status = config.DocumentRejected
+		document.Reason = reason
+	}
+	document.ReviewedAt = timeHelper.GetCurrentTime()
+	// document that driver uploads again during review is not overwritten
+	err = driverService.UpdateVehicleDocument(driverID, number, documentID,
+		bson.M{"image": image, "status": config.DocumentPending},
+		bson.M{"status": document.Status, "reason": document.Reason, "reviewedAt": document.ReviewedAt})
+	if err == mgo.ErrNotFound {
+		return nil, errors.New("Document is changed during review, review it again")
+	}
+	return document, err
+}
+
+// requiredFor checks that document is required for vehicle type
+func requiredFor(document *model.Document, vehicleID bson.ObjectId) bool {
+	if len(document.VehicleIDs) == 0 {
+		return true
+	}
+	for _, id := range document.VehicleIDs {
+		if id == vehicleID {
+			return true
+		}
+	}
+	return false
+}
+
+// documentName returns name of document with id
+func documentName(documents []*model.Document, documentID bson.ObjectId) string {
+	for _, document := range documents {
+		if document.ID == documentID {
+			return document.Name
+		}
+	}
+	return "Document"
+}
+
+func approvedVehicles(onboarding *model.Onboarding) []bson.ObjectId {
+	vehicleIDs := []bson.ObjectId{}
+	for _, vehicle := range onboarding.Vehicles {
+		if vehicle.Status == config.OnboardingApproved && !containsVehicle(vehicleIDs, vehicle.VehicleID) {
+			vehicleIDs = append(vehicleIDs, vehicle.VehicleID)
+		}
+	}
+	return vehicleIDs
+}
+
+func containsVehicle(vehicleIDs []bson.ObjectId, vehicleID bson.ObjectId) bool {
+	for _, id := range vehicleIDs {
+		if id == vehicleID {
+			return true
+		}
+	}
+	return false
+}
+
+func sameVehicles(a []bson.ObjectId, b []bson.ObjectId) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, id := range a {
+		if !containsVehicle(b, id) {
+			return false
+		}
+	}
+	return true
+}
+
+func expired(document *model.Document, uploaded *model.VerifyDocument, now int64) bool {
+	return document.IsExpired && uploaded.ExpireDate > 0 && uploaded.ExpireDate <= now
+}
+
+// driverDocument returns best of driver document that is uploaded with any of vehicles
+func driverDocument(driver *model.Driver, document *model.Document, now int64) *model.VerifyDocument {
+	var best *model.VerifyDocument
+	for _, vehicle := range driver.DriverVehicles {
+		uploaded := FindDocument(vehicle.Documents, document.ID)
+		if uploaded == nil {
+			continue
+		}
+		if best == nil || documentRank(document, uploaded, now) > documentRank(document, best, now) {
+			best = uploaded
+		}
+	}
+	return best
+}
+
+func documentRank(document *model.Document, uploaded *model.VerifyDocument, now int64) int {
+	if expired(document, uploaded, now) {
+		return 0
+	}
+	switch uploaded.Status {
+	case config.DocumentAccepted:
+		return 3
+	case config.DocumentPending:
+		return 2
+	case config.DocumentRejected:
+		return 1
+	}
+	return 0
+}
+
+// statusRank orders status of vehicles, driver takes status of the most advanced vehicle
+func statusRank(status string) int {
+	switch status {
+	case config.OnboardingApproved:
+		return 3
+	case config.OnboardingPending:
+		return 2
+	case config.OnboardingRejected:
+		return 1
+	}
+	return 0
+}
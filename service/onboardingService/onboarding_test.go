@@ -0,0 +1,111 @@
+package onboardingService
+
+import (
+	"testing"
+
+	"../../config"
+	"../../model"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestCheckOnboarding(t *testing.T) {
+	car, bike := bson.NewObjectId(), bson.NewObjectId()
+	license := &model.Document{ID: bson.NewObjectId(), Type: config.DocTypeDriver, IsExpired: true}
+	insurance := &model.Document{ID: bson.NewObjectId(), Type: config.DocTypeVehicle, VehicleIDs: []bson.ObjectId{car}}
+	documents := []*model.Document{license, insurance}
+	now := int64(1500000000)
+
+	driver := &model.Driver{DriverVehicles: []*model.DriverVehicle{
+		{Number: "CAR1", VehicleID: car, Documents: []*model.VerifyDocument{
+			{ID: license.ID, Status: config.DocumentAccepted, ExpireDate: now + 3600},
+			{ID: insurance.ID, Status: config.DocumentPending},
+		}},
+		{Number: "BIKE1", VehicleID: bike},
+	}}
+	onboarding := CheckOnboarding(driver, documents, now)
+	// bike needs only license that is uploaded with car
+	if onboarding.Status != config.OnboardingApproved || onboarding.Vehicles[0].Status != config.OnboardingPending {
+		t.Errorf("onboarding = %s, car = %s", onboarding.Status, onboarding.Vehicles[0].Status)
+	}
+
+	// expired license takes every vehicle offline
+	onboarding = CheckOnboarding(driver, documents, now+7200)
+	if onboarding.Status != config.OnboardingIncomplete || len(onboarding.Vehicles[1].Expired) != 1 || onboarding.Reason == "" {
+		t.Errorf("onboarding with expired license = %+v", onboarding.Vehicles[1])
+	}
+
+	driver.DriverVehicles[0].Documents[1].Status = config.DocumentRejected
+	driver.DriverVehicles = driver.DriverVehicles[:1]
+	if onboarding = CheckOnboarding(driver, documents, now); onboarding.Status != config.OnboardingRejected {
+		t.Errorf("onboarding with rejected insurance = %s", onboarding.Status)
+	}
+
+	if onboarding = CheckOnboarding(&model.Driver{}, documents, now); onboarding.Status != config.OnboardingIncomplete {
+		t.Errorf("onboarding without vehicles = %s", onboarding.Status)
+	}
+}
+
+func TestMergeDocuments(t *testing.T) {
+	kept, changed, removed := bson.NewObjectId(), bson.NewObjectId(), bson.NewObjectId()
+	previous := []*model.VerifyDocument{
+		{ID: kept, Image: "a.png", Status: config.DocumentAccepted, ReviewedAt: 10},
+		{ID: changed, Image: "b.png", Status: config.DocumentRejected, Reason: "blurry"},
+	}
+	uploaded := []*model.VerifyDocument{
+		{ID: kept, Image: "a.png", Status: config.DocumentPending},
+		{ID: changed, Image: "c.png", Status: config.DocumentAccepted},
+		{ID: removed},
+	}
+	MergeDocuments(previous, uploaded, 100)
+
+	if uploaded[0].Status != config.DocumentAccepted || uploaded[0].ReviewedAt != 10 {
+		t.Errorf("unchanged document = %+v", uploaded[0])
+	}
+	if uploaded[1].Status != config.DocumentPending || uploaded[1].Reason != "" || uploaded[1].UploadedAt != 100 {
+		t.Errorf("changed document = %+v", uploaded[1])
+	}
+	if uploaded[2].Status != config.DocumentNone {
+		t.Errorf("document without image = %+v", uploaded[2])
+	}
+}
+
+func TestCheckExpiry(t *testing.T) {
+	license := &model.Document{ID: bson.NewObjectId(), Name: "License", Type: config.DocTypeDriver, IsExpired: true}
+	insurance := &model.Document{ID: bson.NewObjectId(), Name: "Insurance", IsExpired: true}
+	now := int64(1500000000)
+	driver := &model.Driver{DriverVehicles: []*model.DriverVehicle{
+		{Number: "CAR1", Documents: []*model.VerifyDocument{
+			{ID: license.ID, Status: config.DocumentAccepted, ExpireDate: now + 6*daySeconds + 60},
+			{ID: insurance.ID, Status: config.DocumentAccepted, ExpireDate: now - 60},
+		}},
+		{Number: "CAR2", Documents: []*model.VerifyDocument{
+			{ID: license.ID, Status: config.DocumentAccepted, ExpireDate: now + 6*daySeconds + 60},
+		}},
+	}}
+	documents := []*model.Document{license, insurance}
+
+	expiries, vehicles := CheckExpiry(driver, documents, now)
+	// license uploaded with both vehicles is reminded once
+	if len(expiries) != 2 || len(vehicles) != 2 || expiries[0].Days != 7 || expiries[1].Days != 0 {
+		t.Fatalf("expiries = %+v", expiries)
+	}
+	if driver.DriverVehicles[0].Documents[0].Reminded != 7 || driver.DriverVehicles[0].Documents[1].Status != config.DocumentExpired {
+		t.Errorf("documents = %+v", driver.DriverVehicles[0].Documents)
+	}
+	// same reminder is not sent again
+	if expiries, _ = CheckExpiry(driver, documents, now+3600); len(expiries) != 0 {
+		t.Errorf("expiries after reminder = %+v", expiries)
+	}
+	if expiries, _ = CheckExpiry(driver, documents, now+6*daySeconds); len(expiries) != 1 || expiries[0].Days != 1 {
+		t.Errorf("expiries of last day = %+v", expiries)
+	}
+}
+
+func TestReminderDays(t *testing.T) {
+	for left, want := range map[int64]int{31 * daySeconds: 0, 30 * daySeconds: 30, 8 * daySeconds: 30, 7 * daySeconds: 7, 3600: 1} {
+		if got := ReminderDays(left); got != want {
+			t.Errorf("ReminderDays(%d) = %d, want %d", left, got, want)
+		}
+	}
+}
@@ -1,9 +1,23 @@
 package orderService
 
 import (
+	"errors"
+	"time"
+
 	"../../config"
 	"../../db"
 	"../../model"
+	"../../service/authService/businessService"
+	"../../service/foodService"
+	"../../service/foodTypeService"
+	"../../service/paymentService"
+	"../../service/promotionService"
+	"../../service/reasonService"
+	"../../service/surgeService"
+	"../../service/walletService"
+	"../../service/zoneService"
+	"../../util/geo"
+	"../../util/log"
 	"../../util/random"
 	"../../util/timeHelper"
 
@@ -58,6 +72,15 @@ func InitService() {
 			"path": "$reason",
 			"preserveNullAndEmptyArrays": true}},
 	}
+
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	// active trip of driver is read on location updates
+	orderCollection.EnsureIndex(mgo.Index{
+		Key:        []string{"driverId", "tripStatus"},
+		Background: true,
+	})
 }
 
 // CreateOrder creates order
@@ -66,6 +89,10 @@ func CreateOrder(order *model.Order) (*model.Order, error) {
 	defer session.Close()
 
 	// Create url with intialize data
+	// delivery location must be in service area and area of business
+	if err := resolveZone(order); err != nil {
+		return nil, err
+	}
 	order.ID = bson.NewObjectId()
 	order.Number = random.GenerateRandomString(6)
 	order.OrderStatus = config.OrderRequest
@@ -74,12 +101,191 @@ func CreateOrder(order *model.Order) (*model.Order, error) {
 	order.StatusAt = map[string]int64{
 		config.OrderRequest: timeHelper.GetCurrentTime(),
 	}
+	stampMenuVersions(order)
+	// surge locked by quote is charged when order is created before quote expires
+	surgeService.ApplySurge(order, surgeService.ClaimQuote(order.SurgeQuoteID, order.ZoneID))
+	// tip at checkout is paid with order
+	tip, err := paymentService.TipAmount(order.Price, order.TipPercent, order.Tip)
+	if err != nil {
+		return nil, err
+	}
+	order.Tip = tip
+	order.TipPayment = nil
+	order.TippedAt = 0
+	if tip > 0 {
+		order.TippedAt = timeHelper.GetCurrentTime()
+	}
+	// apply promo code, redemption is released when order is not created
+	if err = applyPromotion(order); err != nil {
+		return nil, err
+	}
+	// pay with wallet first and hold rest of total on payment method of user
+	total := paymentService.ToCents(order.Total())
+	wallet, err := walletService.ApplyToOrder(order.UserID, order.ID, total)
+	if err != nil {
+		promotionService.Release(order.ID)
+		return nil, err
+	}
+	payment, err := paymentService.AuthorizeOrder(order, total-wallet)
+	if err != nil {
+		reverseWallet(order, wallet)
+		promotionService.Release(order.ID)
+		return nil, err
+	}
+	payment.Wallet = wallet
+	order.Payment = payment
 	// Insert Data
-	err := orderCollection.Insert(order)
+	if err = orderCollection.Insert(order); err != nil {
+		// order is not created, so wallet, promo code and hold on card are released
+		paymentService.VoidPayment(payment)
+		reverseWallet(order, wallet)
+		promotionService.Release(order.ID)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// TipOrder charges tip of user for driver after trip is completed
+func TipOrder(objid bson.ObjectId, userID bson.ObjectId, percent int, amount float64) (*model.Order, error) {
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	order := &model.Order{}
+	if err := orderCollection.Find(bson.M{"_id": objid, "userId": userID}).One(order); err != nil {
+		return nil, errors.New("This order is not yours")
+	}
+	tip, err := paymentService.TipAmount(order.Price, percent, amount)
+	if err != nil {
+		return nil, err
+	}
+	if tip <= 0 {
+		return nil, errors.New("Tip is invalid")
+	}
+	// order is tipped once in tip window
+	since := timeHelper.FewDurationLater(-time.Duration(config.TipWindowHours) * time.Hour).Unix()
+	change := mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"tip":        tip,
+			"tipPercent": percent,
+			"tippedAt":   timeHelper.GetCurrentTime(),
+		}},
+		ReturnNew: true,
+	}
+	if _, err := orderCollection.Find(bson.M{
+		"_id":                               objid,
+		"driverId":                          bson.M{"$exists": true},
+		"tip":                               bson.M{"$in": []interface{}{0, nil}},
+		"statusAt." + config.TripCompleted: bson.M{"$gte": since},
+	}).Apply(change, order); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, errors.New("This order can't be tipped now")
+		}
+		return nil, err
+	}
 
+	payment, err := paymentService.ChargeTip(order, tip)
+	if err != nil {
+		// tip is not charged, user can tip again
+		orderCollection.UpdateId(objid, bson.M{"$set": bson.M{"tip": 0}, "$unset": bson.M{"tipPercent": "", "tippedAt": ""}})
+		return nil, err
+	}
+	order.TipPayment = payment
+	err = orderCollection.UpdateId(objid, bson.M{"$set": bson.M{"tipPayment": payment}})
 	return order, err
 }
 
+// QuoteOrder returns price of order with discount of promo code and tip
+func QuoteOrder(order *model.Order) (*model.PriceQuote, error) {
+	if order.DeliveryLocation != nil {
+		if err := resolveZone(order); err != nil {
+			return nil, err
+		}
+		surgeService.ApplySurge(order, surgeService.Multiplier(order.ZoneID))
+	}
+	tip, err := paymentService.TipAmount(order.Price, order.TipPercent, order.Tip)
+	if err != nil {
+		return nil, err
+	}
+	quote := promotionService.Quote(order)
+	quote.TipPercents = config.TipPercents
+	if order.Surge > 1 {
+		// surge of quote is kept when order is created in ttl of quote
+		quote.Surge = order.Surge
+		quote.SurgeFee = order.SurgeFee
+		if quote.SurgeQuoteID, err = surgeService.LockQuote(order.ZoneID, order.Surge); err != nil {
+			return nil, err
+		}
+	}
+	if tip > 0 {
+		quote.Tip = tip
+		quote.Total += tip
+		quote.Lines = append(quote.Lines, &model.PriceLine{Label: "Tip", Amount: tip})
+	}
+	return quote, nil
+}
+
+// resolveZone sets zone and city of order from delivery location instead of place id of client
+func resolveZone(order *model.Order) error {
+	zone, err := zoneService.CheckDelivery(order.BusinessID, order.DeliveryLocation)
+	if err != nil {
+		return err
+	}
+	order.ZoneID = zone.ID
+	order.PlaceID = zone.PlaceID
+	order.DeliveryLocation.PlaceID = zone.PlaceID
+	return nil
+}
+
+// applyPromotion validates promo code of order and redeems it
+func applyPromotion(order *model.Order) error {
+	order.Discount = 0
+	if order.PromoCode == "" {
+		return nil
+	}
+	quote := promotionService.Quote(order)
+	if quote.Error != "" {
+		return errors.New(quote.Error)
+	}
+	order.PromoCode = quote.PromoCode
+	order.Discount = quote.Discount
+	return promotionService.Redeem(quote.Promotion, order)
+}
+
+// reverseWallet returns amount paid with wallet for order that is not created
+func reverseWallet(order *model.Order, amount int64) {
+	if amount == 0 {
+		return
+	}
+	walletService.Credit(&model.WalletTransaction{
+		UserID:  order.UserID,
+		Source:  config.WalletReversal,
+		Amount:  amount,
+		OrderID: order.ID,
+		Key:     "reversal:" + order.ID.Hex(),
+	})
+}
+
+// stampMenuVersions saves current menu versions in ordered foods to explain price later
+func stampMenuVersions(order *model.Order) {
+	for _, item := range order.Foods {
+		if item.Food == nil || item.Food.Food == nil {
+			continue
+		}
+		food, err := foodService.ReadFood(item.Food.Food.ID)
+		if err != nil {
+			continue
+		}
+		item.Food.FoodVersion = food.Version
+		if food.FoodType == nil {
+			continue
+		}
+		if foodType, err := foodTypeService.ReadFoodType(food.FoodType.ID); err == nil {
+			item.Food.FoodTypeVersion = foodType.Version
+		}
+	}
+}
+
 // ReadOrder returns order with object id
 func ReadOrder(objid bson.ObjectId) (*model.Order, error) {
 	orderCollection, session := orderCollection()
@@ -142,11 +348,86 @@ func UpdateOrderProcess(objid bson.ObjectId, order *model.Order) (*model.Order,
 		Update:    bson.M{"$set": set},
 		ReturnNew: true,
 	}
-	// Update order
-	_, err := orderCollection.FindId(objid).Apply(change, order)
+	// Update order, order is counted for popularity of business when it is completed first time
+	completing := order.OrderStatus == config.OrderCompleted
+	query := bson.M{"_id": objid}
+	if completing {
+		query["orderStatus"] = bson.M{"$ne": config.OrderCompleted}
+	}
+	_, err := orderCollection.Find(query).Apply(change, order)
+	if err == mgo.ErrNotFound && completing {
+		completing = false
+		_, err = orderCollection.FindId(objid).Apply(change, order)
+	}
+	if err != nil {
+		return order, err
+	}
+	if completing {
+		businessService.IncreaseOrderCount(order.BusinessID)
+	}
+	err = processPayment(order)
 	return order, err
 }
 
+// processPayment captures payment of completed order and refunds declined or cancelled order with refund policy of reason
+func processPayment(order *model.Order) error {
+	if order.Payment == nil {
+		return nil
+	}
+	var payment *model.OrderPayment
+	var err error
+	switch order.OrderStatus {
+	case config.OrderCompleted:
+		if order.Payment.Status != config.PaymentAuthorized {
+			return nil
+		}
+		payment, err = paymentService.CapturePayment(order.Payment, order.Payment.Amount)
+	case config.OrderDeclined, config.OrderCancelled:
+		// cancellation is refunded once
+		if !claimSettlement(order.ID) {
+			return nil
+		}
+		order.Payment.Settled = true
+		promotionService.Release(order.ID)
+		var policy *model.RefundPolicy
+		if reason, err := reasonService.ReadReasonByCode(order.ReasonCode); err == nil {
+			policy = reason.RefundPolicy
+		}
+		payment, err = paymentService.SettleCancellation(order, paymentService.CancellationRefund(order, policy))
+	default:
+		return nil
+	}
+	// status of order is kept, failed payment is resolved by admin with error
+	if !log.CheckErrorNoStackWithMessage(err, "Payment of order %s is failed: ", order.ID.Hex()) {
+		payment.Error = err.Error()
+	}
+	order.Payment = payment
+	return UpdateOrderPayment(order.ID, payment)
+}
+
+// claimSettlement marks payment of order as settled and returns false when it is settled already
+func claimSettlement(objid bson.ObjectId) bool {
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	err := orderCollection.Update(bson.M{
+		"_id":             objid,
+		"payment.settled": bson.M{"$ne": true},
+	}, bson.M{"$set": bson.M{"payment.settled": true}})
+	return err == nil
+}
+
+// UpdateOrderPayment updates payment state of order
+func UpdateOrderPayment(objid bson.ObjectId, payment *model.OrderPayment) error {
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	return orderCollection.UpdateId(objid, bson.M{"$set": bson.M{
+		"payment":   payment,
+		"updatedAt": timeHelper.GetCurrentTime(),
+	}})
+}
+
 // UpdateOrderPickupScore updates order pickup score
 func UpdateOrderPickupScore(objid bson.ObjectId, order *model.Order) (*model.Order, error) {
 	orderCollection, session := orderCollection()
@@ -243,39 +524,275 @@ func ReadOrders(query string, offset int, count int, field string, sort int, bus
 	return orders, totalCount, err
 }
 
-// UpdateOrderRate updates order rate by user
-func UpdateOrderRate(order *model.Order) error {
+// ReadDriverTrips reads recent trips of driver
+func ReadDriverTrips(driverID bson.ObjectId, count int) ([]*model.Order, error) {
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	pipe := []bson.M{
+		{"$match": bson.M{"driverId": driverID}},
+		{"$sort": bson.M{"updatedAt": -1}},
+		{"$limit": count},
+	}
+	pipe = append(pipe, basePipe...)
+	orders := []*model.Order{}
+	err := orderCollection.Pipe(pipe).All(&orders)
+	return orders, err
+}
+
+// CountDriverTrips returns count of trips of driver with trip status
+func CountDriverTrips(driverID bson.ObjectId, tripStatus string) int {
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	count, _ := orderCollection.Find(bson.M{"driverId": driverID, "tripStatus": tripStatus}).Count()
+	return count
+}
+
+// ReadActiveTrip returns order that driver is on trip of
+func ReadActiveTrip(driverID bson.ObjectId) (*model.Order, error) {
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	order := &model.Order{}
+	err := orderCollection.Find(bson.M{
+		"driverId":   driverID,
+		"tripStatus": bson.M{"$in": config.ActiveTripStatuses},
+	}).Select(bson.M{"tripStatus": 1, "statusAt": 1}).Sort("-updatedAt").One(order)
+	return order, err
+}
+
+// ReadDriverIDsOnTrip returns drivers that are on trip of order
+func ReadDriverIDsOnTrip() ([]bson.ObjectId, error) {
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	driverIDs := []bson.ObjectId{}
+	err := orderCollection.Find(bson.M{
+		"tripStatus": bson.M{"$in": config.ActiveTripStatuses},
+	}).Distinct("driverId", &driverIDs)
+	return driverIDs, err
+}
+
+// UpdateTripActuals updates distance and duration of trip that driver went actually
+func UpdateTripActuals(objid bson.ObjectId, distance float64, duration int64) error {
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	return orderCollection.UpdateId(objid, bson.M{"$set": bson.M{
+		"actualDistance": distance,
+		"actualDuration": duration,
+	}})
+}
+
+// TransitTrip changes trip status only when trip is still in status, so that driver and geofence don't change it twice
+func TransitTrip(objid bson.ObjectId, from string, to string) (*model.Order, error) {
 	orderCollection, session := orderCollection()
 	defer session.Close()
 
-	err := orderCollection.Update(bson.M{"_id": order.ID, "userId": order.UserID}, bson.M{
-		"$set": bson.M{
-			"rated":            true,
-			"orderStatus":      config.OrderCompleted,
-			"businessRate":     order.BusinessRate,
-			"businessFeedback": order.BusinessFeedback,
-			"driverRate":       order.DriverRate,
-			"driverFeedback":   order.DriverFeedback,
+	now := timeHelper.GetCurrentTime()
+	change := mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"tripStatus":     to,
+			"statusAt." + to: now,
+			"updatedAt":      now,
 		}},
-	)
-	return err
+		ReturnNew: true,
+	}
+	order := &model.Order{}
+	_, err := orderCollection.Find(bson.M{"_id": objid, "tripStatus": from}).Apply(change, order)
+	return order, err
+}
+
+// UpdateTripGeofence saves state of driver in geofence of trip
+func UpdateTripGeofence(objid bson.ObjectId, geofence *model.TripGeofence) error {
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	return orderCollection.UpdateId(objid, bson.M{"$set": bson.M{"geofence": geofence}})
+}
+
+// FlagTripAnomaly adds transition that driver marked far from expected point
+func FlagTripAnomaly(objid bson.ObjectId, anomaly *model.TripAnomaly) error {
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	return orderCollection.UpdateId(objid, bson.M{"$push": bson.M{"anomalies": anomaly}})
 }
 
-// NoRatedOrders read not rated orders of user
-func NoRatedOrders(objid bson.ObjectId) ([]*model.Order, error) {
+// ReadAnomalousTrips reads trips with anomalies, latest first
+func ReadAnomalousTrips(driverID bson.ObjectId, offset int, count int) ([]*model.Order, int, error) {
 	orderCollection, session := orderCollection()
 	defer session.Close()
 
+	query := bson.M{"anomalies.0": bson.M{"$exists": true}}
+	if driverID != "" {
+		query["driverId"] = driverID
+	}
+	totalCount, _ := orderCollection.Find(query).Count()
+
 	orders := []*model.Order{}
-	t := timeHelper.FewDaysLater(-7)
-	pipe := []bson.M{
+	err := orderCollection.Find(query).Sort("-updatedAt").Skip(offset).Limit(count).All(&orders)
+	return orders, totalCount, err
+}
+
+// CountOpenOrdersByZone returns count of orders that wait for driver in each zone
+func CountOpenOrdersByZone() (map[bson.ObjectId]int, error) {
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	counts := []struct {
+		ZoneID bson.ObjectId `bson:"_id"`
+		Count  int           `bson:"count"`
+	}{}
+	err := orderCollection.Pipe([]bson.M{
 		{"$match": bson.M{
-			"userId":    objid,
-			"rated":     false,
-			"updatedAt": bson.M{"$ge": t},
+			"orderStatus": bson.M{"$in": config.OpenOrderStatuses},
+			"driverId":    bson.M{"$exists": false},
+			"zoneId":      bson.M{"$exists": true},
+		}},
+		{"$group": bson.M{"_id": "$zoneId", "count": bson.M{"$sum": 1}}},
+	}).All(&counts)
+
+	demands := map[bson.ObjectId]int{}
+	for _, count := range counts {
+		demands[count.ZoneID] = count.Count
+	}
+	return demands, err
+}
+
+// CountOrdersInArea returns count of orders created in period in city, within radius of center when center is given
+func CountOrdersInArea(placeID string, center *model.GeoJSON, radius float64, from int64, to int64) int {
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	query := bson.M{"placeId": placeID, "createdAt": bson.M{"$gte": from, "$lt": to}}
+	if center != nil && radius > 0 {
+		query["deliveryLocation.geoJson"] = bson.M{"$geoWithin": bson.M{
+			"$centerSphere": []interface{}{center.Coordinates, radius / geo.EarthRadius},
+		}}
+	}
+	count, _ := orderCollection.Find(query).Count()
+	return count
+}
+
+// ReadOrderLocations reads locations of business and delivery of latest orders created in period, every city when place is empty
+func ReadOrderLocations(placeID string, from int64, to int64, count int) ([]*model.Order, error) {
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	query := bson.M{"createdAt": bson.M{"$gte": from, "$lt": to}}
+	if placeID != "" {
+		query["placeId"] = placeID
+	}
+	orders := []*model.Order{}
+	err := orderCollection.Pipe([]bson.M{
+		{"$match": query},
+		{"$sort": bson.M{"createdAt": -1}},
+		{"$limit": count},
+		{"$lookup": bson.M{
+			"from":         "business",
+			"localField":   "businessId",
+			"foreignField": "_id",
+			"as":           "business",
+		}},
+		{"$unwind": bson.M{
+			"path":                       "$business",
+			"preserveNullAndEmptyArrays": true}},
+		{"$project": bson.M{
+			"business.geoLocation": 1,
+			"deliveryLocation":     1,
+			"driverId":             1,
+			"orderStatus":          1,
 		}},
+	}).All(&orders)
+	return orders, err
+}
+
+// UpdateOrderRate updates rate of user about business and driver
+func UpdateOrderRate(order *model.Order) (*model.Order, error) {
+	return rateOrder(bson.M{"userId": order.UserID, "rated": bson.M{"$ne": true}}, bson.M{
+		"rated":            true,
+		"businessRate":     order.BusinessRate,
+		"businessFeedback": order.BusinessFeedback,
+		"driverRate":       order.DriverRate,
+		"driverFeedback":   order.DriverFeedback,
+		"userTags":         order.UserTags,
+	}, order.ID)
+}
+
+// UpdateOrderRateByDriver updates rate of driver about user
+func UpdateOrderRateByDriver(order *model.Order) (*model.Order, error) {
+	return rateOrder(bson.M{"driverId": order.DriverID, "driverRated": bson.M{"$ne": true}}, bson.M{
+		"driverRated":  true,
+		"userRate":     order.UserRate,
+		"userFeedback": order.UserFeedback,
+		"driverTags":   order.DriverTags,
+	}, order.ID)
+}
+
+// UpdateOrderRateByBusiness updates rate of business about user and driver
+func UpdateOrderRateByBusiness(order *model.Order) (*model.Order, error) {
+	return rateOrder(bson.M{"businessId": order.BusinessID, "businessRated": bson.M{"$ne": true}}, bson.M{
+		"businessRated":      true,
+		"businessUserRate":   order.BusinessUserRate,
+		"businessDriverRate": order.BusinessDriverRate,
+		"businessComment":    order.BusinessComment,
+		"businessTags":       order.BusinessTags,
+	}, order.ID)
+}
+
+// rateOrder updates rate when order is completed in rating window and party didn't rate yet
+func rateOrder(query bson.M, set bson.M, objid bson.ObjectId) (*model.Order, error) {
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	query["_id"] = objid
+	query["$or"] = ratingWindow()
+	set["updatedAt"] = timeHelper.GetCurrentTime()
+	change := mgo.Change{
+		Update:    bson.M{"$set": set},
+		ReturnNew: true,
+	}
+	order := &model.Order{}
+	if _, err := orderCollection.Find(query).Apply(change, order); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, errors.New("This order is rated already or can't be rated now")
+		}
+		return nil, err
+	}
+	return order, nil
+}
+
+// ratingWindow returns condition of orders that are completed in rating window
+func ratingWindow() []bson.M {
+	since := timeHelper.FewDaysLater(-config.RatingWindowDays).Unix()
+	return []bson.M{
+		{"statusAt." + config.OrderCompleted: bson.M{"$gte": since}},
+		{"statusAt." + config.TripCompleted: bson.M{"$gte": since}},
+	}
+}
+
+// NoRatedOrders read orders that client with role can rate yet
+func NoRatedOrders(objid bson.ObjectId, role string) ([]*model.Order, error) {
+	orderCollection, session := orderCollection()
+	defer session.Close()
+
+	match := bson.M{"$or": ratingWindow()}
+	switch role {
+	case config.RoleDriver:
+		match["driverId"] = objid
+		match["driverRated"] = bson.M{"$ne": true}
+	case config.RoleBusiness:
+		match["businessId"] = objid
+		match["businessRated"] = bson.M{"$ne": true}
+	default:
+		match["userId"] = objid
+		match["rated"] = bson.M{"$ne": true}
 	}
+	pipe := []bson.M{{"$match": match}}
 	pipe = append(pipe, basePipe...)
+	orders := []*model.Order{}
 	err := orderCollection.Pipe(pipe).All(&orders)
 
 	return orders, err
@@ -0,0 +1,130 @@
+package paymentService
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Tokens that fake gateway declines
+const (
+	FakeTokenDecline = "tok_decline"
+	FakeTokenFailure = "tok_failure"
+)
+
+type fakePayment struct {
+	token      string
+	authorized int64
+	captured   int64
+	refunded   int64
+	refunds    map[string]bool
+	voided     bool
+}
+
+// FakeGateway is deterministic in-memory gateway for development and tests
+type FakeGateway struct {
+	mutex    sync.Mutex
+	sequence int
+	payments map[string]*fakePayment
+}
+
+// NewFakeGateway returns empty fake gateway
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{payments: map[string]*fakePayment{}}
+}
+
+// Authorize holds amount, payment method with FakeTokenDecline is declined
+func (g *FakeGateway) Authorize(token string, amount int64, currency string) (string, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if amount <= 0 {
+		return "", errInvalidAmount
+	}
+	switch token {
+	case FakeTokenDecline:
+		return "", errors.New("Your card was declined")
+	case FakeTokenFailure:
+		return "", errors.New("Gateway is not available")
+	}
+	g.sequence++
+	reference := fmt.Sprintf("fake_%d", g.sequence)
+	g.payments[reference] = &fakePayment{token: token, authorized: amount, refunds: map[string]bool{}}
+	return reference, nil
+}
+
+// Capture takes authorized amount
+func (g *FakeGateway) Capture(reference string, amount int64) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	payment, err := g.payment(reference)
+	if err != nil {
+		return err
+	}
+	if payment.voided || payment.captured > 0 {
+		return errors.New("Payment can't be captured")
+	}
+	if amount <= 0 || amount > payment.authorized {
+		return errInvalidAmount
+	}
+	payment.captured = amount
+	return nil
+}
+
+// Void releases authorized amount
+func (g *FakeGateway) Void(reference string) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	payment, err := g.payment(reference)
+	if err != nil {
+		return err
+	}
+	if payment.captured > 0 {
+		return errors.New("Captured payment can't be voided")
+	}
+	payment.voided = true
+	return nil
+}
+
+// Refund returns captured amount, refund with key that is already refunded is skipped
+func (g *FakeGateway) Refund(reference string, amount int64, key string) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	payment, err := g.payment(reference)
+	if err != nil {
+		return err
+	}
+	if key != "" && payment.refunds[key] {
+		return nil
+	}
+	if amount <= 0 || payment.refunded+amount > payment.captured {
+		return errInvalidAmount
+	}
+	payment.refunded += amount
+	if key != "" {
+		payment.refunds[key] = true
+	}
+	return nil
+}
+
+// Balance returns captured amount that is not refunded
+func (g *FakeGateway) Balance(reference string) int64 {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if payment, ok := g.payments[reference]; ok {
+		return payment.captured - payment.refunded
+	}
+	return 0
+}
+
+func (g *FakeGateway) payment(reference string) (*fakePayment, error) {
+	payment, ok := g.payments[reference]
+	if !ok {
+		return nil, errors.New("Payment is not existed")
+	}
+	return payment, nil
+}
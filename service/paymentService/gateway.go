@@ -0,0 +1,54 @@
+package paymentService
+
+import (
+	"errors"
+	"math"
+
+	"../../config"
+	"../../util/log"
+)
+
+// Gateway processes payments with tokenized payment methods, amounts are in cents
+type Gateway interface {
+	// Authorize holds amount on payment method and returns reference of payment
+	Authorize(token string, amount int64, currency string) (string, error)
+	// Capture takes authorized amount
+	Capture(reference string, amount int64) error
+	// Void releases authorized amount
+	Void(reference string) error
+	// Refund returns captured amount, refund with same key is made once
+	Refund(reference string, amount int64, key string) error
+}
+
+var gateway Gateway
+
+// SetGateway sets gateway that processes payments
+func SetGateway(g Gateway) {
+	gateway = g
+}
+
+// initGateway sets gateway from configuration, fake gateway must be chosen explicitly and only works in development
+func initGateway() {
+	if gateway != nil {
+		return
+	}
+	switch config.PaymentGateway {
+	case config.PaymentGatewayFake:
+		if config.Environment != "DEVELOPMENT" {
+			log.Fatal("Fake payment gateway can't be used out of development")
+		}
+		gateway = NewFakeGateway()
+	default:
+		if config.StripeSecretKey == "" && config.Environment != "DEVELOPMENT" {
+			log.Fatal("Stripe secret key is not set")
+		}
+		gateway = NewStripeGateway(config.StripeURL, config.StripeSecretKey)
+	}
+}
+
+// ToCents converts amount to cents
+func ToCents(amount float64) int64 {
+	return int64(math.Floor(amount*100 + 0.5))
+}
+
+var errInvalidAmount = errors.New("Amount of payment is invalid")
@@ -0,0 +1,118 @@
+package paymentService
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFakeGateway(t *testing.T) {
+	g := NewFakeGateway()
+
+	if _, err := g.Authorize(FakeTokenDecline, 1000, "usd"); err == nil {
+		t.Error("declined token is authorized")
+	}
+	reference, err := g.Authorize("tok_visa", 1000, "usd")
+	if err != nil || reference != "fake_1" {
+		t.Fatalf("authorize returns %s, %v", reference, err)
+	}
+	if err := g.Capture(reference, 1200); err == nil {
+		t.Error("amount over authorization is captured")
+	}
+	if err := g.Capture(reference, 1000); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Void(reference); err == nil {
+		t.Error("captured payment is voided")
+	}
+	if err := g.Refund(reference, 400, "refund_1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Refund(reference, 400, "refund_1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Refund(reference, 700, "refund_2"); err == nil {
+		t.Error("refund over captured amount")
+	}
+	if balance := g.Balance(reference); balance != 600 {
+		t.Errorf("balance is %d, expected 600", balance)
+	}
+
+	reference, _ = g.Authorize("tok_visa", 500, "usd")
+	if err := g.Void(reference); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Capture(reference, 500); err == nil {
+		t.Error("voided payment is captured")
+	}
+}
+
+func TestStripeGateway(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"message":"Invalid API Key"}}`))
+			return
+		}
+		r.ParseForm()
+		switch r.URL.Path {
+		case "/payment_intents":
+			if r.Form.Get("payment_method") == "pm_card_chargeDeclined" {
+				w.WriteHeader(http.StatusPaymentRequired)
+				w.Write([]byte(`{"error":{"message":"Your card was declined."}}`))
+				return
+			}
+			if r.Form.Get("capture_method") != "manual" || r.Form.Get("amount") != "1250" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":{"message":"Invalid request"}}`))
+				return
+			}
+			w.Write([]byte(`{"id":"pi_1","status":"requires_capture"}`))
+		case "/payment_intents/pi_1/capture":
+			w.Write([]byte(`{"id":"pi_1","status":"succeeded"}`))
+		case "/payment_intents/pi_1/cancel":
+			w.Write([]byte(`{"id":"pi_1","status":"canceled"}`))
+		case "/refunds":
+			if r.Header.Get("Idempotency-Key") != "refund_1" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":{"message":"Idempotency key is missing"}}`))
+				return
+			}
+			w.Write([]byte(`{"id":"re_1","status":"succeeded"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"message":"Unrecognized request URL"}}`))
+		}
+	}))
+	defer server.Close()
+
+	g := NewStripeGateway(server.URL, "sk_test")
+	reference, err := g.Authorize("pm_card_visa", 1250, "usd")
+	if err != nil || reference != "pi_1" {
+		t.Fatalf("authorize returns %s, %v", reference, err)
+	}
+	if _, err := g.Authorize("pm_card_chargeDeclined", 1250, "usd"); err == nil || err.Error() != "Your card was declined." {
+		t.Errorf("decline returns %v", err)
+	}
+	if err := g.Capture(reference, 1250); err != nil {
+		t.Error(err)
+	}
+	if err := g.Void(reference); err != nil {
+		t.Error(err)
+	}
+	if err := g.Refund(reference, 250, "refund_1"); err != nil {
+		t.Error(err)
+	}
+	if err := NewStripeGateway(server.URL, "wrong").Void(reference); err == nil {
+		t.Error("request with invalid key is succeeded")
+	}
+}
+
+func TestToCents(t *testing.T) {
+	if cents := ToCents(12.34); cents != 1234 {
+		t.Errorf("ToCents(12.34) = %d", cents)
+	}
+	if cents := ToCents(0.29); cents != 29 {
+		t.Errorf("ToCents(0.29) = %d", cents)
+	}
+}
@@ -0,0 +1,348 @@
+package paymentService
+
+import (
+	"errors"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func methodCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("payment_method"), session
+}
+
+func intentCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("payment_intent"), session
+}
+
+// InitService inits service
+func InitService() {
+	initGateway()
+
+	methodCollection, session := methodCollection()
+	defer session.Close()
+	methodCollection.EnsureIndex(mgo.Index{
+		Key:        []string{"userId"},
+		Background: true,
+	})
+
+	intentCollection, intentSession := intentCollection()
+	defer intentSession.Close()
+	intentCollection.EnsureIndex(mgo.Index{
+		Key:        []string{"orderId"},
+		Background: true,
+	})
+
+	refundCollection, refundSession := refundCollection()
+	defer refundSession.Close()
+	refundCollection.EnsureIndex(mgo.Index{
+		Key:        []string{"orderId"},
+		Background: true,
+	})
+}
+
+// CreatePaymentMethod saves tokenized payment method of user
+func CreatePaymentMethod(method *model.PaymentMethod) (*model.PaymentMethod, error) {
+	if method.Token == "" {
+		return nil, errors.New("Token of payment method is empty")
+	}
+	methodCollection, session := methodCollection()
+	defer session.Close()
+
+	// first payment method is default
+	if count, _ := methodCollection.Find(bson.M{"userId": method.UserID}).Count(); count == 0 {
+		method.Default = true
+	}
+	if method.Default {
+		methodCollection.UpdateAll(bson.M{"userId": method.UserID}, bson.M{"$set": bson.M{"default": false}})
+	}
+	method.ID = bson.NewObjectId()
+	method.Gateway = config.PaymentGateway
+	method.CreatedAt = timeHelper.GetCurrentTime()
+	method.UpdatedAt = timeHelper.GetCurrentTime()
+
+	err := methodCollection.Insert(method)
+	return method, err
+}
+
+// ReadPaymentMethod returns payment method with object id
+func ReadPaymentMethod(objid bson.ObjectId) (*model.PaymentMethod, error) {
+	methodCollection, session := methodCollection()
+	defer session.Close()
+
+	method := &model.PaymentMethod{}
+	err := methodCollection.FindId(objid).One(method)
+	return method, err
+}
+
+// ReadPaymentMethods returns payment methods of user
+func ReadPaymentMethods(userID bson.ObjectId) ([]*model.PaymentMethod, error) {
+	methodCollection, session := methodCollection()
+	defer session.Close()
+
+	methods := []*model.PaymentMethod{}
+	err := methodCollection.Find(bson.M{"userId": userID}).Sort("-default", "-createdAt").All(&methods)
+	return methods, err
+}
+
+// SetDefaultPaymentMethod sets default payment method of user
+func SetDefaultPaymentMethod(objid bson.ObjectId, userID bson.ObjectId) (*model.PaymentMethod, error) {
+	methodCollection, session := methodCollection()
+	defer session.Close()
+
+	method := &model.PaymentMethod{}
+	if err := methodCollection.Find(bson.M{"_id": objid, "userId": userID}).One(method); err != nil {
+		return nil, errors.New("Payment method is not existed")
+	}
+	methodCollection.UpdateAll(bson.M{"userId": userID}, bson.M{"$set": bson.M{"default": false}})
+	change := mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"default":   true,
+			"updatedAt": timeHelper.GetCurrentTime(),
+		}},
+		ReturnNew: true,
+	}
+	_, err := methodCollection.FindId(objid).Apply(change, method)
+	return method, err
+}
+
+// DeletePaymentMethod deletes payment method of user
+func DeletePaymentMethod(objid bson.ObjectId, userID bson.ObjectId) error {
+	methodCollection, session := methodCollection()
+	defer session.Close()
+
+	return methodCollection.Remove(bson.M{"_id": objid, "userId": userID})
+}
+
+// defaultPaymentMethod returns payment method that pays order
+func defaultPaymentMethod(userID bson.ObjectId, methodID bson.ObjectId) (*model.PaymentMethod, error) {
+	methodCollection, session := methodCollection()
+	defer session.Close()
+
+	query := bson.M{"userId": userID, "default": true}
+	if methodID != "" {
+		query = bson.M{"userId": userID, "_id": methodID}
+	}
+	method := &model.PaymentMethod{}
+	if err := methodCollection.Find(query).One(method); err != nil {
+		return nil, errors.New("Payment method is not existed")
+	}
+	return method, nil
+}
+
+// ReadPaymentIntent returns payment intent with object id
+func ReadPaymentIntent(objid bson.ObjectId) (*model.PaymentIntent, error) {
+	intentCollection, session := intentCollection()
+	defer session.Close()
+
+	intent := &model.PaymentIntent{}
+	err := intentCollection.FindId(objid).One(intent)
+	return intent, err
+}
+
+// ReadOrderPaymentIntents returns payment intents of order
+func ReadOrderPaymentIntents(orderID bson.ObjectId) ([]*model.PaymentIntent, error) {
+	intentCollection, session := intentCollection()
+	defer session.Close()
+
+	intents := []*model.PaymentIntent{}
+	err := intentCollection.Find(bson.M{"orderId": orderID}).Sort("createdAt").All(&intents)
+	return intents, err
+}
+
+// AuthorizeOrder authorizes amount of order on payment method of user
+func AuthorizeOrder(order *model.Order, amount int64) (*model.OrderPayment, error) {
+	intentCollection, session := intentCollection()
+	defer session.Close()
+
+	payment := &model.OrderPayment{Currency: config.PaymentCurrency}
+	if order.Payment != nil {
+		payment.MethodID = order.Payment.MethodID
+	}
+	// order is paid with wallet fully
+	if amount == 0 {
+		payment.Status = config.PaymentCaptured
+		return payment, nil
+	}
+	method, err := defaultPaymentMethod(order.UserID, payment.MethodID)
+	if err != nil {
+		return nil, err
+	}
+	intent := &model.PaymentIntent{
+		ID:        bson.NewObjectId(),
+		OrderID:   order.ID,
+		UserID:    order.UserID,
+		MethodID:  method.ID,
+		Gateway:   method.Gateway,
+		Amount:    amount,
+		Currency:  config.PaymentCurrency,
+		Status:    config.PaymentAuthorized,
+		CreatedAt: timeHelper.GetCurrentTime(),
+		UpdatedAt: timeHelper.GetCurrentTime(),
+	}
+	intent.Reference, err = gateway.Authorize(method.Token, intent.Amount, intent.Currency)
+	if err != nil {
+		intent.Status = config.PaymentFailed
+		intent.Error = err.Error()
+	}
+	if insertErr := intentCollection.Insert(intent); insertErr != nil {
+		return nil, insertErr
+	}
+	return withIntent(payment, intent), err
+}
+
+// CapturePayment captures amount of authorized payment of order, rest of authorization is released
+func CapturePayment(payment *model.OrderPayment, amount int64) (*model.OrderPayment, error) {
+	intent, err := readIntent(payment, config.PaymentAuthorized)
+	if err != nil {
+		return payment, err
+	}
+	if amount <= 0 || amount > intent.Amount {
+		return payment, errInvalidAmount
+	}
+	if err := gateway.Capture(intent.Reference, amount); err != nil {
+		return payment, err
+	}
+	intent, err = updateIntent(intent.ID, config.PaymentAuthorized, bson.M{
+		"status":         config.PaymentCaptured,
+		"amountCaptured": amount,
+		"amountReleased": intent.Amount - amount,
+	})
+	if err != nil {
+		return payment, err
+	}
+	return withIntent(payment, intent), nil
+}
+
+// VoidPayment releases authorized payment of order
+func VoidPayment(payment *model.OrderPayment) (*model.OrderPayment, error) {
+	intent, err := readIntent(payment, config.PaymentAuthorized)
+	if err != nil {
+		return payment, err
+	}
+	if err := gateway.Void(intent.Reference); err != nil {
+		return payment, err
+	}
+	intent, err = updateIntent(intent.ID, config.PaymentAuthorized, bson.M{
+		"status":         config.PaymentVoided,
+		"amountReleased": intent.Amount,
+	})
+	if err != nil {
+		return payment, err
+	}
+	return withIntent(payment, intent), nil
+}
+
+// RefundPayment refunds amount of captured payment, amount is reserved on intent before gateway is called
+// so concurrent refunds never exceed captured amount, key is idempotency key of refund in gateway
+func RefundPayment(payment *model.OrderPayment, amount int64, key string) (*model.OrderPayment, error) {
+	intent, err := readIntent(payment, config.PaymentCaptured)
+	if err != nil {
+		return payment, err
+	}
+	if amount <= 0 || amount > intent.AmountCaptured {
+		return payment, errInvalidAmount
+	}
+	intent, err = reserveRefund(intent.ID, intent.AmountCaptured, amount)
+	if err == mgo.ErrNotFound {
+		return payment, errInvalidAmount
+	}
+	if err != nil {
+		return payment, err
+	}
+	if err := gateway.Refund(intent.Reference, amount, key); err != nil {
+		releaseRefund(intent.ID, amount)
+		return payment, err
+	}
+	if intent.AmountRefunded == intent.AmountCaptured {
+		if refunded, err := updateIntent(intent.ID, config.PaymentCaptured, bson.M{"status": config.PaymentRefunded}); err == nil {
+			intent = refunded
+		}
+	}
+	return withIntent(payment, intent), nil
+}
+
+// reserveRefund adds amount to refunded amount of captured intent when it doesn't exceed captured amount
+func reserveRefund(objid bson.ObjectId, captured int64, amount int64) (*model.PaymentIntent, error) {
+	intentCollection, session := intentCollection()
+	defer session.Close()
+
+	change := mgo.Change{
+		Update: bson.M{
+			"$inc": bson.M{"amountRefunded": amount},
+			"$set": bson.M{"updatedAt": timeHelper.GetCurrentTime()},
+		},
+		ReturnNew: true,
+	}
+	query := bson.M{
+		"_id":            objid,
+		"status":         config.PaymentCaptured,
+		"amountRefunded": bson.M{"$lte": captured - amount},
+	}
+	intent := &model.PaymentIntent{}
+	_, err := intentCollection.Find(query).Apply(change, intent)
+	return intent, err
+}
+
+// releaseRefund returns reserved amount of refund that gateway failed
+func releaseRefund(objid bson.ObjectId, amount int64) error {
+	intentCollection, session := intentCollection()
+	defer session.Close()
+
+	return intentCollection.UpdateId(objid, bson.M{
+		"$inc": bson.M{"amountRefunded": -amount},
+		"$set": bson.M{"updatedAt": timeHelper.GetCurrentTime()},
+	})
+}
+
+func readIntent(payment *model.OrderPayment, status string) (*model.PaymentIntent, error) {
+	if payment == nil || payment.IntentID == "" {
+		return nil, errors.New("Order has no payment")
+	}
+	intent, err := ReadPaymentIntent(payment.IntentID)
+	if err != nil {
+		return nil, err
+	}
+	if intent.Status != status {
+		return nil, errors.New("Payment is " + intent.Status)
+	}
+	return intent, nil
+}
+
+// updateIntent updates intent when it is in status yet
+func updateIntent(objid bson.ObjectId, status string, set bson.M) (*model.PaymentIntent, error) {
+	intentCollection, session := intentCollection()
+	defer session.Close()
+
+	set["updatedAt"] = timeHelper.GetCurrentTime()
+	change := mgo.Change{
+		Update:    bson.M{"$set": set},
+		ReturnNew: true,
+	}
+	intent := &model.PaymentIntent{}
+	_, err := intentCollection.Find(bson.M{"_id": objid, "status": status}).Apply(change, intent)
+	return intent, err
+}
+
+// withIntent returns payment state of order with card payment
+func withIntent(payment *model.OrderPayment, intent *model.PaymentIntent) *model.OrderPayment {
+	result := &model.OrderPayment{}
+	if payment != nil {
+		*result = *payment
+	}
+	result.MethodID = intent.MethodID
+	result.IntentID = intent.ID
+	result.Amount = intent.Amount
+	result.Refunded = intent.AmountRefunded + intent.AmountReleased
+	result.Currency = intent.Currency
+	result.Status = intent.Status
+	result.Error = intent.Error
+	return result
+}
@@ -0,0 +1,298 @@
+package paymentService
+
+import (
+	"errors"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../service/walletService"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func refundCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("refund"), session
+}
+
+func balanceCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("refund_balance"), session
+}
+
+// CancellationRefund returns amount in cents that is refunded for order cancelled with policy
+func CancellationRefund(order *model.Order, policy *model.RefundPolicy) int64 {
+	total := ToCents(order.Total())
+	if policy == nil {
+		return total
+	}
+	refundType, percent := policy.Type, policy.Percent
+	// order is late to cancel when restaurant accepted it
+	if _, ok := order.StatusAt[config.OrderAccepted]; ok && policy.LateType != "" {
+		refundType, percent = policy.LateType, policy.LatePercent
+	}
+
+	amount := total
+	switch refundType {
+	case config.RefundNone:
+		amount = 0
+	case config.RefundFeeOnly:
+		amount = ToCents(order.BookingFee)
+	case config.RefundPercent:
+		amount = total * int64(percent) / 100
+	}
+	if amount > total {
+		return total
+	}
+	if amount < 0 {
+		return 0
+	}
+	return amount
+}
+
+// ItemsRefund returns amount in cents of ordered foods, price of ordered food is price of all count,
+// foods in succeeded refunds of order are not refunded again
+func ItemsRefund(order *model.Order, items []*model.RefundItem, refunded []*model.Refund) (int64, error) {
+	amount := int64(0)
+	counts := map[int]int{}
+	for _, refund := range refunded {
+		if refund.Status != config.RefundSucceeded {
+			continue
+		}
+		for _, item := range refund.Items {
+			counts[item.Index] += item.Count
+		}
+	}
+	for _, item := range items {
+		if item.Index < 0 || item.Index >= len(order.Foods) || item.Count <= 0 {
+			return 0, errors.New("Refunded food is not in order")
+		}
+		food := order.Foods[item.Index]
+		counts[item.Index] += item.Count
+		if counts[item.Index] > food.Count {
+			return 0, errors.New("Refunded count is more than ordered count")
+		}
+		amount += ToCents(food.Price * float64(item.Count) / float64(food.Count))
+	}
+	return amount, nil
+}
+
+// SettleCancellation refunds amount of declined or cancelled order to card first and to wallet next,
+// rest of card payment is captured
+func SettleCancellation(order *model.Order, amount int64) (*model.OrderPayment, error) {
+	payment := order.Payment
+	if payment == nil {
+		return payment, nil
+	}
+	var err error
+	cardAmount := int64(0)
+	if payment.IntentID != "" {
+		cardAmount = amount
+		if cardAmount > payment.Amount {
+			cardAmount = payment.Amount
+		}
+		refund := &model.Refund{
+			OrderID:    order.ID,
+			UserID:     order.UserID,
+			IntentID:   payment.IntentID,
+			Kind:       config.RefundCancellation,
+			Amount:     cardAmount,
+			ReasonCode: order.ReasonCode,
+			IssuerRole: "system",
+		}
+		switch payment.Status {
+		case config.PaymentAuthorized:
+			refund.Method = config.RefundRelease
+			if cardAmount == payment.Amount {
+				payment, err = VoidPayment(payment)
+			} else {
+				payment, err = CapturePayment(payment, payment.Amount-cardAmount)
+			}
+		case config.PaymentCaptured:
+			refund.Method = config.RefundGateway
+			if cardAmount > 0 {
+				if err = reserveOrderRefund(order.ID, payment, cardAmount); err == nil {
+					if payment, err = RefundPayment(payment, cardAmount, "cancel:"+order.ID.Hex()); err != nil {
+						releaseOrderRefund(order.ID, cardAmount)
+					}
+				}
+			}
+		}
+		if refund.Amount > 0 && refund.Method != "" {
+			recordRefund(refund, err)
+		}
+		if err != nil {
+			return payment, err
+		}
+	}
+
+	walletAmount := amount - cardAmount
+	if walletAmount > payment.Wallet {
+		walletAmount = payment.Wallet
+	}
+	if walletAmount > 0 {
+		refund := &model.Refund{
+			OrderID:    order.ID,
+			UserID:     order.UserID,
+			Kind:       config.RefundCancellation,
+			Method:     config.RefundWallet,
+			Amount:     walletAmount,
+			ReasonCode: order.ReasonCode,
+			IssuerRole: "system",
+		}
+		if err := reserveOrderRefund(order.ID, payment, walletAmount); err != nil {
+			return payment, err
+		}
+		if payment, err = refundToWallet(order, payment, refund, "cancel:"+order.ID.Hex()); err != nil {
+			releaseOrderRefund(order.ID, walletAmount)
+		}
+	}
+	return payment, err
+}
+
+// IssueRefund refunds items or amount of order to card or wallet by support
+func IssueRefund(order *model.Order, refund *model.Refund) (*model.OrderPayment, *model.Refund, error) {
+	payment := order.Payment
+	if payment == nil {
+		return nil, nil, errors.New("Order has no payment")
+	}
+	if len(refund.Items) > 0 {
+		refunded, _, err := ReadRefunds(order.ID, 0, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		amount, err := ItemsRefund(order, refund.Items, refunded)
+		if err != nil {
+			return nil, nil, err
+		}
+		refund.Amount = amount
+	}
+	if refund.Amount <= 0 {
+		return nil, nil, errInvalidAmount
+	}
+	refund.OrderID = order.ID
+	refund.UserID = order.UserID
+	refund.Kind = config.RefundSupport
+
+	if refund.Method == config.RefundWallet {
+		// credit is refunded instead of card, up to paid amount
+		if err := reserveOrderRefund(order.ID, payment, refund.Amount); err != nil {
+			return nil, nil, err
+		}
+		payment, err := refundToWallet(order, payment, refund, "")
+		if err != nil {
+			releaseOrderRefund(order.ID, refund.Amount)
+		}
+		return payment, refund, err
+	}
+
+	if payment.Status != config.PaymentCaptured || payment.IntentID == "" {
+		return nil, nil, errors.New("Card payment of order is not captured")
+	}
+	if err := reserveOrderRefund(order.ID, payment, refund.Amount); err != nil {
+		return nil, nil, err
+	}
+	refund.ID = bson.NewObjectId()
+	refund.IntentID = payment.IntentID
+	refund.Method = config.RefundGateway
+	payment, err := RefundPayment(payment, refund.Amount, "refund:"+refund.ID.Hex())
+	if err != nil {
+		releaseOrderRefund(order.ID, refund.Amount)
+	}
+	recordRefund(refund, err)
+	return payment, refund, err
+}
+
+// reserveOrderRefund adds amount to refunded total of order when total doesn't exceed captured card and wallet payment,
+// total of order starts from refunds that are made before it
+func reserveOrderRefund(orderID bson.ObjectId, payment *model.OrderPayment, amount int64) error {
+	paid, refunded := payment.Wallet, payment.WalletRefunded
+	if payment.IntentID != "" {
+		intent, err := ReadPaymentIntent(payment.IntentID)
+		if err != nil {
+			return err
+		}
+		paid += intent.AmountCaptured
+		refunded += intent.AmountRefunded
+	}
+	if amount <= 0 || amount > paid {
+		return errInvalidAmount
+	}
+	balanceCollection, session := balanceCollection()
+	defer session.Close()
+
+	if err := balanceCollection.Insert(bson.M{"_id": orderID, "refunded": refunded}); err != nil && !mgo.IsDup(err) {
+		return err
+	}
+	err := balanceCollection.Update(bson.M{
+		"_id":      orderID,
+		"refunded": bson.M{"$lte": paid - amount},
+	}, bson.M{"$inc": bson.M{"refunded": amount}})
+	if err == mgo.ErrNotFound {
+		return errInvalidAmount
+	}
+	return err
+}
+
+// releaseOrderRefund returns reserved amount of refund that is failed
+func releaseOrderRefund(orderID bson.ObjectId, amount int64) {
+	balanceCollection, session := balanceCollection()
+	defer session.Close()
+
+	balanceCollection.UpdateId(orderID, bson.M{"$inc": bson.M{"refunded": -amount}})
+}
+
+// refundToWallet credits refund to wallet of user
+func refundToWallet(order *model.Order, payment *model.OrderPayment, refund *model.Refund, key string) (*model.OrderPayment, error) {
+	_, err := walletService.Credit(&model.WalletTransaction{
+		UserID:   order.UserID,
+		Source:   config.WalletRefund,
+		Amount:   refund.Amount,
+		OrderID:  order.ID,
+		Key:      key,
+		Note:     refund.Note,
+		IssuerID: refund.IssuerID,
+	})
+	recordRefund(refund, err)
+	if err != nil {
+		return payment, err
+	}
+	result := *payment
+	result.WalletRefunded += refund.Amount
+	return &result, nil
+}
+
+// recordRefund appends refund to ledger
+func recordRefund(refund *model.Refund, err error) {
+	refundCollection, session := refundCollection()
+	defer session.Close()
+
+	if refund.ID == "" {
+		refund.ID = bson.NewObjectId()
+	}
+	refund.Status = config.RefundSucceeded
+	if err != nil {
+		refund.Status = config.RefundFailed
+		refund.Error = err.Error()
+	}
+	refund.CreatedAt = timeHelper.GetCurrentTime()
+	refundCollection.Insert(refund)
+}
+
+// ReadRefunds returns refunds in ledger, refunds of order are returned with order id
+func ReadRefunds(orderID bson.ObjectId, offset int, count int) ([]*model.Refund, int, error) {
+	refundCollection, session := refundCollection()
+	defer session.Close()
+
+	query := bson.M{}
+	if orderID != "" {
+		query["orderId"] = orderID
+	}
+	totalCount, _ := refundCollection.Find(query).Count()
+	refunds := []*model.Refund{}
+	err := refundCollection.Find(query).Sort("-createdAt").Skip(offset).Limit(count).All(&refunds)
+	return refunds, totalCount, err
+}
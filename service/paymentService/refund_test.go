@@ -0,0 +1,76 @@
+package paymentService
+
+import (
+	"encoding/json"
+	"testing"
+
+	"../../config"
+	"../../model"
+)
+
+func testOrder(t *testing.T, accepted bool) *model.Order {
+	order := &model.Order{}
+	err := json.Unmarshal([]byte(`{
+		"foods": [{"count": 2, "price": 10}, {"count": 1, "price": 4.5}],
+		"price": 14.5, "tax": 1.5, "bookingFee": 2,
+		"statusAt": {"OrderRequest": 1}
+	}`), order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accepted {
+		order.StatusAt[config.OrderAccepted] = 2
+	}
+	return order
+}
+
+func TestCancellationRefund(t *testing.T) {
+	policy := &model.RefundPolicy{Type: config.RefundFull, LateType: config.RefundFeeOnly}
+	cases := []struct {
+		order  *model.Order
+		policy *model.RefundPolicy
+		amount int64
+	}{
+		{testOrder(t, false), nil, 1800},
+		{testOrder(t, false), policy, 1800},
+		{testOrder(t, true), policy, 200},
+		{testOrder(t, true), &model.RefundPolicy{Type: config.RefundPercent, Percent: 50}, 900},
+		{testOrder(t, true), &model.RefundPolicy{Type: config.RefundNone}, 0},
+		{testOrder(t, false), &model.RefundPolicy{Type: config.RefundPercent, Percent: 150}, 1800},
+	}
+	for i, test := range cases {
+		if amount := CancellationRefund(test.order, test.policy); amount != test.amount {
+			t.Errorf("case %d: refund is %d, expected %d", i, amount, test.amount)
+		}
+	}
+}
+
+func TestItemsRefund(t *testing.T) {
+	order := testOrder(t, true)
+
+	amount, err := ItemsRefund(order, []*model.RefundItem{{Index: 0, Count: 1}, {Index: 1, Count: 1}}, nil)
+	if err != nil || amount != 950 {
+		t.Errorf("refund is %d, %v, expected 950", amount, err)
+	}
+	if _, err := ItemsRefund(order, []*model.RefundItem{{Index: 0, Count: 1}, {Index: 0, Count: 2}}, nil); err == nil {
+		t.Error("refunded count is more than ordered count")
+	}
+	if _, err := ItemsRefund(order, []*model.RefundItem{{Index: 2, Count: 1}}, nil); err == nil {
+		t.Error("food out of order is refunded")
+	}
+
+	// foods refunded in earlier refunds are counted
+	refunded := []*model.Refund{
+		{Items: []*model.RefundItem{{Index: 0, Count: 1}, {Index: 1, Count: 1}}, Status: config.RefundSucceeded},
+		{Items: []*model.RefundItem{{Index: 0, Count: 1}}, Status: config.RefundFailed},
+	}
+	if amount, err := ItemsRefund(order, []*model.RefundItem{{Index: 0, Count: 1}}, refunded); err != nil || amount != 500 {
+		t.Errorf("refund is %d, %v, expected 500", amount, err)
+	}
+	if _, err := ItemsRefund(order, []*model.RefundItem{{Index: 1, Count: 1}}, refunded); err == nil {
+		t.Error("refunded food is refunded again")
+	}
+	if _, err := ItemsRefund(order, []*model.RefundItem{{Index: 0, Count: 2}}, refunded); err == nil {
+		t.Error("refunded count is more than ordered count with earlier refunds")
+	}
+}
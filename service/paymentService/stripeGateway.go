@@ -0,0 +1,114 @@
+package paymentService
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StripeGateway processes payments with payment intents of Stripe
+type StripeGateway struct {
+	URL    string
+	Key    string
+	Client *http.Client
+}
+
+type stripeResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewStripeGateway returns Stripe gateway with API url and secret key
+func NewStripeGateway(apiURL string, key string) *StripeGateway {
+	return &StripeGateway{
+		URL:    strings.TrimRight(apiURL, "/"),
+		Key:    key,
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Authorize creates and confirms payment intent that is captured manually
+func (g *StripeGateway) Authorize(token string, amount int64, currency string) (string, error) {
+	if amount <= 0 {
+		return "", errInvalidAmount
+	}
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(amount, 10))
+	form.Set("currency", currency)
+	form.Set("payment_method", token)
+	form.Set("capture_method", "manual")
+	form.Set("confirm", "true")
+
+	res, err := g.post("/payment_intents", form)
+	if err != nil {
+		return "", err
+	}
+	if res.Status != "requires_capture" {
+		return "", errors.New("Payment is not authorized: " + res.Status)
+	}
+	return res.ID, nil
+}
+
+// Capture captures payment intent
+func (g *StripeGateway) Capture(reference string, amount int64) error {
+	form := url.Values{}
+	form.Set("amount_to_capture", strconv.FormatInt(amount, 10))
+	_, err := g.post("/payment_intents/"+reference+"/capture", form)
+	return err
+}
+
+// Void cancels payment intent
+func (g *StripeGateway) Void(reference string) error {
+	_, err := g.post("/payment_intents/"+reference+"/cancel", url.Values{})
+	return err
+}
+
+// Refund refunds payment intent, key is sent as idempotency key so retried refund isn't made twice
+func (g *StripeGateway) Refund(reference string, amount int64, key string) error {
+	form := url.Values{}
+	form.Set("payment_intent", reference)
+	form.Set("amount", strconv.FormatInt(amount, 10))
+	_, err := g.postWithKey("/refunds", form, key)
+	return err
+}
+
+func (g *StripeGateway) post(path string, form url.Values) (*stripeResponse, error) {
+	return g.postWithKey(path, form, "")
+}
+
+func (g *StripeGateway) postWithKey(path string, form url.Values, key string) (*stripeResponse, error) {
+	req, err := http.NewRequest("POST", g.URL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer "+g.Key)
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	res := &stripeResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return nil, err
+	}
+	if res.Error != nil {
+		return nil, errors.New(res.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("Stripe returns " + resp.Status)
+	}
+	return res, nil
+}
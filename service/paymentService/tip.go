@@ -0,0 +1,40 @@
+package paymentService
+
+import (
+	"errors"
+	"math"
+
+	"../../config"
+	"../../model"
+)
+
+// TipAmount returns tip with preset percent of price or custom amount up to max of tip
+func TipAmount(price float64, percent int, amount float64) (float64, error) {
+	if percent == 0 {
+		if amount < 0 {
+			return 0, errors.New("Tip is invalid")
+		}
+		if amount > config.TipMax {
+			return 0, errors.New("Tip is more than max of tip")
+		}
+		return math.Floor(amount*100+0.5) / 100, nil
+	}
+	for _, preset := range config.TipPercents {
+		if preset == percent {
+			return math.Floor(price*float64(percent)+0.5) / 100, nil
+		}
+	}
+	return 0, errors.New("Percent of tip is invalid")
+}
+
+// ChargeTip charges tip after trip on payment method of order
+func ChargeTip(order *model.Order, amount float64) (*model.OrderPayment, error) {
+	if amount <= 0 {
+		return nil, errors.New("Tip is invalid")
+	}
+	payment, err := AuthorizeOrder(order, ToCents(amount))
+	if err != nil {
+		return payment, err
+	}
+	return CapturePayment(payment, payment.Amount)
+}
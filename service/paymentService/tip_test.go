@@ -0,0 +1,28 @@
+package paymentService
+
+import "testing"
+
+func TestTipAmount(t *testing.T) {
+	cases := []struct {
+		price   float64
+		percent int
+		amount  float64
+		tip     float64
+		valid   bool
+	}{
+		{24.5, 10, 0, 2.45, true},
+		{24.5, 15, 0, 3.68, true},
+		{24.5, 0, 4.999, 5, true},
+		{24.5, 12, 0, 0, false},
+		{24.5, 0, -1, 0, false},
+		{24.5, 0, 100, 100, true},
+		{24.5, 0, 500, 0, false},
+		{24.5, 0, 100.01, 0, false},
+	}
+	for i, test := range cases {
+		tip, err := TipAmount(test.price, test.percent, test.amount)
+		if (err == nil) != test.valid || tip != test.tip {
+			t.Errorf("case %d: tip is %v, %v", i, tip, err)
+		}
+	}
+}
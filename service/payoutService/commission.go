@@ -0,0 +1,87 @@
+package payoutService
+
+import (
+	"errors"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func commissionCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("commission"), session
+}
+
+// SetCommission creates or updates commission rule of business or city
+func SetCommission(commission *model.Commission) (*model.Commission, error) {
+	if commission.BusinessID == "" && commission.PlaceID == "" {
+		return nil, errors.New("Business or city of commission is empty")
+	}
+	if commission.BusinessID != "" && commission.PlaceID != "" {
+		return nil, errors.New("Commission is only for business or city")
+	}
+	if commission.Percent < 0 || commission.Percent > 100 {
+		return nil, errors.New("Percent of commission is invalid")
+	}
+	commissionCollection, session := commissionCollection()
+	defer session.Close()
+
+	query := bson.M{"placeId": commission.PlaceID}
+	if commission.BusinessID != "" {
+		query = bson.M{"businessId": commission.BusinessID}
+	}
+	now := timeHelper.GetCurrentTime()
+	change := mgo.Change{
+		Update: bson.M{
+			"$set":         bson.M{"percent": commission.Percent, "updatedAt": now},
+			"$setOnInsert": bson.M{"createdAt": now},
+		},
+		Upsert:    true,
+		ReturnNew: true,
+	}
+	result := &model.Commission{}
+	_, err := commissionCollection.Find(query).Apply(change, result)
+	return result, err
+}
+
+// ReadCommissions reads commission rules
+func ReadCommissions(offset int, count int) ([]*model.Commission, int, error) {
+	commissionCollection, session := commissionCollection()
+	defer session.Close()
+
+	query := commissionCollection.Find(bson.M{})
+	totalCount, _ := query.Count()
+	commissions := []*model.Commission{}
+	err := query.Sort("-updatedAt").Skip(offset).Limit(count).All(&commissions)
+	return commissions, totalCount, err
+}
+
+// DeleteCommission deletes commission rule
+func DeleteCommission(objid bson.ObjectId) error {
+	commissionCollection, session := commissionCollection()
+	defer session.Close()
+
+	return commissionCollection.RemoveId(objid)
+}
+
+// CommissionPercent returns commission percent of order by rule of business, city or default
+func CommissionPercent(order *model.Order) float64 {
+	commissionCollection, session := commissionCollection()
+	defer session.Close()
+
+	commission := &model.Commission{}
+	if err := commissionCollection.Find(bson.M{"businessId": order.BusinessID}).One(commission); err == nil {
+		return commission.Percent
+	}
+	if order.PlaceID != "" {
+		if err := commissionCollection.Find(bson.M{"placeId": order.PlaceID}).One(commission); err == nil {
+			return commission.Percent
+		}
+	}
+	return config.CommissionPercent
+}
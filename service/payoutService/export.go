@@ -0,0 +1,150 @@
+package payoutService
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"../../config"
+	"../../model"
+)
+
+const nachaRecordSize = 94
+
+// ExportCSV returns csv file of statements with bank info and amounts in dollars
+func ExportCSV(statements []*model.PayoutStatement) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	w.Write([]string{"statementId", "businessId", "name", "bank", "accountName", "routing", "account", "periodStart", "periodEnd", "gross", "commission", "fees", "refunds", "net"})
+	for _, s := range statements {
+		w.Write([]string{
+			s.ID.Hex(),
+			s.BusinessID.Hex(),
+			s.Name,
+			s.BankInfo.Name,
+			s.BankInfo.Account,
+			s.BankInfo.Routing,
+			s.BankInfo.Number,
+			time.Unix(s.PeriodStart, 0).UTC().Format("2006-01-02"),
+			time.Unix(s.PeriodEnd, 0).UTC().Format("2006-01-02"),
+			dollars(s.Gross),
+			dollars(s.Commission),
+			dollars(s.Fees),
+			dollars(s.Refunds),
+			dollars(s.Net),
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// ExportNACHA returns ACH file of credits of statements to checking accounts of businesses
+func ExportNACHA(batch *model.PayoutBatch, statements []*model.PayoutStatement, now time.Time) ([]byte, error) {
+	origin := config.PayoutBankRouting
+	if len(origin) != 9 {
+		return nil, errors.New("Routing number of payout bank is invalid")
+	}
+	now = now.UTC()
+	records := []string{}
+	// file header
+	records = append(records, "101"+
+		" "+origin+
+		nachaAlpha(config.PayoutCompanyID, 10)+
+		now.Format("060102")+now.Format("1504")+
+		"A094101"+
+		nachaAlpha(config.PayoutBankName, 23)+
+		nachaAlpha(config.PayoutCompanyName, 23)+
+		nachaAlpha(batch.ID.Hex(), 8))
+	// batch header of credits only
+	effective := now.AddDate(0, 0, 1).Format("060102")
+	records = append(records, "5220"+
+		nachaAlpha(config.PayoutCompanyName, 16)+
+		nachaAlpha("", 20)+
+		nachaAlpha(config.PayoutCompanyID, 10)+
+		"CCD"+
+		nachaAlpha("PAYOUT", 10)+
+		time.Unix(batch.PeriodEnd, 0).UTC().Format("060102")+
+		effective+
+		"   "+
+		"1"+
+		origin[:8]+
+		nachaNumber(1, 7))
+
+	var hash, total int64
+	for i, s := range statements {
+		if !ValidBankInfo(s.BankInfo) {
+			return nil, fmt.Errorf("Bank info of statement %s is invalid", s.ID.Hex())
+		}
+		routing, _ := strconv.ParseInt(s.BankInfo.Routing[:8], 10, 64)
+		hash += routing
+		total += s.Net
+		records = append(records, "622"+
+			s.BankInfo.Routing+
+			nachaAlpha(s.BankInfo.Number, 17)+
+			nachaNumber(s.Net, 10)+
+			nachaAlpha(s.BusinessID.Hex(), 15)+
+			nachaAlpha(s.Name, 22)+
+			"  "+
+			"0"+
+			origin[:8]+nachaNumber(int64(i+1), 7))
+	}
+	hash %= 10000000000
+
+	// batch control
+	records = append(records, "8220"+
+		nachaNumber(int64(len(statements)), 6)+
+		nachaNumber(hash, 10)+
+		nachaNumber(0, 12)+
+		nachaNumber(total, 12)+
+		nachaAlpha(config.PayoutCompanyID, 10)+
+		nachaAlpha("", 25)+
+		origin[:8]+
+		nachaNumber(1, 7))
+	// file control, block is 10 records
+	blocks := (len(records) + 1 + 9) / 10
+	records = append(records, "9"+
+		nachaNumber(1, 6)+
+		nachaNumber(int64(blocks), 6)+
+		nachaNumber(int64(len(statements)), 8)+
+		nachaNumber(hash, 10)+
+		nachaNumber(0, 12)+
+		nachaNumber(total, 12)+
+		nachaAlpha("", 39))
+	for len(records)%10 != 0 {
+		records = append(records, strings.Repeat("9", nachaRecordSize))
+	}
+	for _, record := range records {
+		if len(record) != nachaRecordSize {
+			return nil, errors.New("Record of batch file is invalid")
+		}
+	}
+	return []byte(strings.Join(records, "\n") + "\n"), nil
+}
+
+// nachaAlpha returns upper case ascii field that is left justified and padded with spaces
+func nachaAlpha(value string, size int) string {
+	value = strings.Map(func(r rune) rune {
+		if r < ' ' || r > '~' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(value))
+	if len(value) > size {
+		value = value[:size]
+	}
+	return value + strings.Repeat(" ", size-len(value))
+}
+
+// nachaNumber returns field that is right justified and padded with zeros
+func nachaNumber(value int64, size int) string {
+	s := fmt.Sprintf("%0*d", size, value)
+	return s[len(s)-size:]
+}
+
+func dollars(cents int64) string {
+	return fmt.Sprintf("%.2f", float64(cents)/100)
+}
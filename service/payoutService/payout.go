@@ -0,0 +1,250 @@
+package payoutService
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../service/authService/businessService"
+	"../../util/log"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var routingRegexp = regexp.MustCompile(`^[0-9]{9}$`)
+
+func statementCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("payout_statement"), session
+}
+
+func batchCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("payout_batch"), session
+}
+
+// WeekStart returns start of week of time, week starts at monday in UTC
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-day, 0, 0, 0, 0, time.UTC)
+}
+
+// ValidBankInfo checks that payout can be sent to bank account
+func ValidBankInfo(bank model.BankInfo) bool {
+	return routingRegexp.MatchString(bank.Routing) && bank.Number != "" && len(bank.Number) <= 17
+}
+
+// GenerateStatements creates statements of week that starts at period start and batch of payable statements.
+// Unsettled lines before week are included, business whose net is not positive or whose bank info is invalid
+// carries lines to next week.
+func GenerateStatements(periodStart int64) (*model.PayoutBatch, error) {
+	start := WeekStart(time.Unix(periodStart, 0))
+	end := start.AddDate(0, 0, 7)
+	if end.Unix() > timeHelper.GetCurrentTime() {
+		return nil, errors.New("Week of payout is not finished")
+	}
+
+	batchCollection, session := batchCollection()
+	defer session.Close()
+
+	batch := &model.PayoutBatch{
+		ID:          bson.NewObjectId(),
+		PeriodStart: start.Unix(),
+		PeriodEnd:   end.Unix(),
+		Status:      config.PayoutPending,
+		CreatedAt:   timeHelper.GetCurrentTime(),
+	}
+	// batch of week is created only once
+	if err := batchCollection.Insert(batch); err != nil {
+		if mgo.IsDup(err) {
+			return nil, errors.New("Payouts of week are generated already")
+		}
+		return nil, err
+	}
+
+	lineCollection, lineSession := lineCollection()
+	defer lineSession.Close()
+
+	match := bson.M{"statementId": bson.M{"$exists": false}, "createdAt": bson.M{"$lt": batch.PeriodEnd}}
+	pipe := []bson.M{
+		{"$match": match},
+		{"$group": bson.M{
+			"_id":        "$businessId",
+			"gross":      bson.M{"$sum": "$gross"},
+			"commission": bson.M{"$sum": "$commission"},
+			"fees":       bson.M{"$sum": "$fees"},
+			"refunds":    bson.M{"$sum": "$refunds"},
+			"net":        bson.M{"$sum": "$net"},
+			"lineCount":  bson.M{"$sum": 1},
+		}},
+	}
+	totals := []*model.PayoutStatement{}
+	if err := lineCollection.Pipe(pipe).All(&totals); err != nil {
+		return nil, err
+	}
+
+	statementCollection, statementSession := statementCollection()
+	defer statementSession.Close()
+
+	for _, statement := range totals {
+		if statement.Net <= 0 {
+			continue
+		}
+		statement.BusinessID = statement.ID
+		statement.ID = bson.NewObjectId()
+		statement.PeriodStart = batch.PeriodStart
+		statement.PeriodEnd = batch.PeriodEnd
+		statement.Status = config.PayoutPending
+		statement.CreatedAt = batch.CreatedAt
+		statement.UpdatedAt = batch.CreatedAt
+		if business, err := businessService.ReadBusiness(statement.BusinessID); err == nil {
+			statement.Name = business.Name
+			statement.BankInfo = business.BankInfo
+		}
+		if ValidBankInfo(statement.BankInfo) {
+			statement.BatchID = batch.ID
+			batch.Count++
+			batch.Amount += statement.Net
+		} else {
+			statement.Status = config.PayoutHeld
+			statement.Note = "Bank info of business is invalid, lines are carried to next week"
+		}
+		if err := statementCollection.Insert(statement); !log.CheckErrorNoStackWithMessage(err, "Failed to create statement of business %s", statement.BusinessID.Hex()) {
+			continue
+		}
+		// lines of held statement stay unsettled so they are paid once bank info is fixed
+		if statement.Status == config.PayoutHeld {
+			continue
+		}
+		lineMatch := bson.M{"businessId": statement.BusinessID}
+		for key, value := range match {
+			lineMatch[key] = value
+		}
+		_, err := lineCollection.UpdateAll(lineMatch, bson.M{"$set": bson.M{"statementId": statement.ID}})
+		log.CheckErrorNoStackWithMessage(err, "Failed to settle lines of statement %s", statement.ID.Hex())
+	}
+
+	err := batchCollection.UpdateId(batch.ID, bson.M{"$set": bson.M{"count": batch.Count, "amount": batch.Amount}})
+	return batch, err
+}
+
+// ReadStatement reads statement
+func ReadStatement(objid bson.ObjectId) (*model.PayoutStatement, error) {
+	statementCollection, session := statementCollection()
+	defer session.Close()
+
+	statement := &model.PayoutStatement{}
+	err := statementCollection.FindId(objid).One(statement)
+	return statement, err
+}
+
+// ReadStatements reads statements of business
+func ReadStatements(businessID bson.ObjectId, offset int, count int) ([]*model.PayoutStatement, int, error) {
+	statementCollection, session := statementCollection()
+	defer session.Close()
+
+	query := statementCollection.Find(bson.M{"businessId": businessID})
+	totalCount, _ := query.Count()
+	statements := []*model.PayoutStatement{}
+	err := query.Sort("-periodStart").Skip(offset).Limit(count).All(&statements)
+	return statements, totalCount, err
+}
+
+// ReadBatch reads batch
+func ReadBatch(objid bson.ObjectId) (*model.PayoutBatch, error) {
+	batchCollection, session := batchCollection()
+	defer session.Close()
+
+	batch := &model.PayoutBatch{}
+	err := batchCollection.FindId(objid).One(batch)
+	return batch, err
+}
+
+// ReadBatches reads batches
+func ReadBatches(offset int, count int) ([]*model.PayoutBatch, int, error) {
+	batchCollection, session := batchCollection()
+	defer session.Close()
+
+	query := batchCollection.Find(bson.M{})
+	totalCount, _ := query.Count()
+	batches := []*model.PayoutBatch{}
+	err := query.Sort("-periodStart").Skip(offset).Limit(count).All(&batches)
+	return batches, totalCount, err
+}
+
+// ReadBatchStatements reads statements that are paid by batch
+func ReadBatchStatements(batchID bson.ObjectId) ([]*model.PayoutStatement, error) {
+	statementCollection, session := statementCollection()
+	defer session.Close()
+
+	statements := []*model.PayoutStatement{}
+	err := statementCollection.Find(bson.M{"batchId": batchID}).Sort("_id").All(&statements)
+	return statements, err
+}
+
+// ExportBatch returns batch file of format and marks batch as exported
+func ExportBatch(objid bson.ObjectId, format string) ([]byte, error) {
+	batch, err := ReadBatch(objid)
+	if err != nil {
+		return nil, err
+	}
+	statements, err := ReadBatchStatements(objid)
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	switch format {
+	case config.PayoutFormatCSV:
+		data, err = ExportCSV(statements)
+	case config.PayoutFormatNACHA:
+		data, err = ExportNACHA(batch, statements, time.Now())
+	default:
+		err = errors.New("Format of batch file is invalid")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status == config.PayoutPending {
+		err = updateBatchStatus(batch, config.PayoutExported, bson.M{"exportedAt": timeHelper.GetCurrentTime()})
+	}
+	return data, err
+}
+
+// PayBatch marks batch and its statements as paid after bank transfer
+func PayBatch(objid bson.ObjectId) (*model.PayoutBatch, error) {
+	batch, err := ReadBatch(objid)
+	if err != nil {
+		return nil, err
+	}
+	if batch.Status == config.PayoutPaid {
+		return nil, errors.New("Batch is paid already")
+	}
+	if err := updateBatchStatus(batch, config.PayoutPaid, bson.M{"paidAt": timeHelper.GetCurrentTime()}); err != nil {
+		return nil, err
+	}
+	return ReadBatch(objid)
+}
+
+func updateBatchStatus(batch *model.PayoutBatch, status string, set bson.M) error {
+	batchCollection, session := batchCollection()
+	defer session.Close()
+
+	set["status"] = status
+	if err := batchCollection.UpdateId(batch.ID, bson.M{"$set": set}); err != nil {
+		return err
+	}
+
+	statementCollection, statementSession := statementCollection()
+	defer statementSession.Close()
+	_, err := statementCollection.UpdateAll(bson.M{"batchId": batch.ID}, bson.M{"$set": bson.M{
+		"status":    status,
+		"updatedAt": timeHelper.GetCurrentTime(),
+	}})
+	return err
+}
@@ -0,0 +1,105 @@
+package payoutService
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"../../config"
+	"../../model"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestOrderLine(t *testing.T) {
+	order := &model.Order{ID: bson.NewObjectId(), Price: 20, Tax: 2, BookingFee: 3}
+	line := OrderLine(order, 15)
+	if line.Gross != 2200 || line.Commission != 300 || line.Fees != 94 || line.Net != 1806 {
+		t.Errorf("order line = %+v", line)
+	}
+
+	order.Payment = &model.OrderPayment{Refunded: 500, WalletRefunded: 100}
+	line = OrderLine(order, 15)
+	if line.Refunds != 600 || line.Net != 1206 {
+		t.Errorf("order line with refunds = %+v", line)
+	}
+
+	refund := &model.Refund{ID: bson.NewObjectId(), Amount: 1000}
+	line = RefundLine(order, refund, 15)
+	if line.Commission != -150 || line.Net != -850 {
+		t.Errorf("refund line = %+v", line)
+	}
+}
+
+func TestWeekStart(t *testing.T) {
+	cases := []struct {
+		t    time.Time
+		want time.Time
+	}{
+		{time.Date(2017, 6, 14, 13, 0, 0, 0, time.UTC), time.Date(2017, 6, 12, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2017, 6, 12, 0, 0, 0, 0, time.UTC), time.Date(2017, 6, 12, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2017, 6, 18, 23, 59, 0, 0, time.UTC), time.Date(2017, 6, 12, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2017, 7, 2, 10, 0, 0, 0, time.UTC), time.Date(2017, 6, 26, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		if got := WeekStart(c.t); !got.Equal(c.want) {
+			t.Errorf("WeekStart(%v) = %v, want %v", c.t, got, c.want)
+		}
+	}
+}
+
+func testStatements() []*model.PayoutStatement {
+	bank := model.BankInfo{Name: "Bank", Account: "Cafe", Number: "123456789", Routing: "021000021"}
+	return []*model.PayoutStatement{
+		{ID: bson.NewObjectId(), BusinessID: bson.NewObjectId(), Name: "Café Ünique", BankInfo: bank, Net: 12345},
+		{ID: bson.NewObjectId(), BusinessID: bson.NewObjectId(), Name: "Pizza", BankInfo: bank, Net: 500},
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	data, err := ExportCSV(testStatements())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("csv has %d lines", len(lines))
+	}
+	if !strings.HasSuffix(lines[1], ",123.45") {
+		t.Errorf("csv line = %s", lines[1])
+	}
+}
+
+func TestExportNACHA(t *testing.T) {
+	config.PayoutBankRouting = "091000019"
+	batch := &model.PayoutBatch{ID: bson.NewObjectId(), PeriodEnd: 1497830400}
+	data, err := ExportNACHA(batch, testStatements(), time.Date(2017, 6, 19, 9, 30, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(records) != 10 {
+		t.Fatalf("file has %d records", len(records))
+	}
+	for _, record := range records {
+		if len(record) != nachaRecordSize {
+			t.Errorf("record size is %d: %q", len(record), record)
+		}
+	}
+	if records[2][:3] != "622" || records[2][29:39] != "0000012345" {
+		t.Errorf("entry = %q", records[2])
+	}
+	// entry hash is sum of receiving routing numbers without check digit
+	if records[4][10:20] != "0004200004" || records[4][32:44] != "000000012845" {
+		t.Errorf("batch control = %q", records[4])
+	}
+	if records[5][:1] != "9" || records[9] != strings.Repeat("9", nachaRecordSize) {
+		t.Errorf("file control = %q", records[5])
+	}
+
+	statements := testStatements()
+	statements[0].BankInfo.Routing = "123"
+	if _, err := ExportNACHA(batch, statements, time.Now()); err == nil {
+		t.Error("invalid bank info is exported")
+	}
+}
@@ -0,0 +1,157 @@
+package payoutService
+
+import (
+	"math"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../service/paymentService"
+	"../../util/log"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func lineCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("settlement_line"), session
+}
+
+// InitService inits service
+func InitService() {
+	lineCollection, session := lineCollection()
+	defer session.Close()
+
+	// key makes recording of order and refund idempotent
+	lineCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"key"},
+		Unique: true,
+	})
+	lineCollection.EnsureIndex(mgo.Index{
+		Key: []string{"businessId", "statementId", "createdAt"},
+	})
+
+	commissionCollection, commissionSession := commissionCollection()
+	defer commissionSession.Close()
+	commissionCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"businessId"},
+		Unique: true,
+		Sparse: true,
+	})
+	commissionCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"placeId"},
+		Unique: true,
+		Sparse: true,
+	})
+
+	statementCollection, statementSession := statementCollection()
+	defer statementSession.Close()
+	statementCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"businessId", "periodStart"},
+		Unique: true,
+	})
+
+	batchCollection, batchSession := batchCollection()
+	defer batchSession.Close()
+	batchCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"periodStart"},
+		Unique: true,
+	})
+}
+
+// RecordOrder records settlement of completed order to ledger of business
+func RecordOrder(order *model.Order) (*model.SettlementLine, error) {
+	line, err := insertLine(OrderLine(order, CommissionPercent(order)))
+	log.CheckErrorNoStackWithMessage(err, "Failed to record settlement of order %s", order.ID.Hex())
+	return line, err
+}
+
+// RecordRefund records refund of completed order to ledger of business
+func RecordRefund(order *model.Order, refund *model.Refund) (*model.SettlementLine, error) {
+	if order.OrderStatus != config.OrderCompleted {
+		// refund before completion is settled with order
+		return nil, nil
+	}
+	line, err := insertLine(RefundLine(order, refund, CommissionPercent(order)))
+	log.CheckErrorNoStackWithMessage(err, "Failed to record settlement of refund %s", refund.ID.Hex())
+	return line, err
+}
+
+func insertLine(line *model.SettlementLine) (*model.SettlementLine, error) {
+	lineCollection, session := lineCollection()
+	defer session.Close()
+
+	line.ID = bson.NewObjectId()
+	line.CreatedAt = timeHelper.GetCurrentTime()
+	if err := lineCollection.Insert(line); err != nil {
+		if mgo.IsDup(err) {
+			// recorded already
+			err = lineCollection.Find(bson.M{"key": line.Key}).One(line)
+		}
+		return line, err
+	}
+	return line, nil
+}
+
+// OrderLine returns settlement of order, gross is food price and tax that business earns
+func OrderLine(order *model.Order, percent float64) *model.SettlementLine {
+	gross := paymentService.ToCents(order.Price + order.Tax)
+	line := &model.SettlementLine{
+		BusinessID: order.BusinessID,
+		OrderID:    order.ID,
+		Number:     order.Number,
+		Kind:       config.SettlementOrder,
+		Key:        config.SettlementOrder + ":" + order.ID.Hex(),
+		Gross:      gross,
+		Percent:    percent,
+		Commission: percentOf(paymentService.ToCents(order.Price), percent),
+		Fees:       percentOf(gross, config.ProcessingFeePercent) + config.ProcessingFeeFixed,
+	}
+	// refunds before completion are paid by business
+	if order.Payment != nil {
+		line.Refunds = order.Payment.Refunded + order.Payment.WalletRefunded
+		if line.Refunds > gross {
+			line.Refunds = gross
+		}
+	}
+	line.Net = line.Gross - line.Commission - line.Fees - line.Refunds
+	return line
+}
+
+// RefundLine returns settlement of refund after completion, commission of refunded amount is returned to business
+func RefundLine(order *model.Order, refund *model.Refund, percent float64) *model.SettlementLine {
+	line := &model.SettlementLine{
+		BusinessID: order.BusinessID,
+		OrderID:    order.ID,
+		Number:     order.Number,
+		Kind:       config.SettlementRefund,
+		Key:        config.SettlementRefund + ":" + refund.ID.Hex(),
+		Percent:    percent,
+		Commission: -percentOf(refund.Amount, percent),
+		Refunds:    refund.Amount,
+	}
+	line.Net = -line.Commission - line.Refunds
+	return line
+}
+
+func percentOf(amount int64, percent float64) int64 {
+	return int64(math.Floor(float64(amount)*percent/100 + 0.5))
+}
+
+// ReadLines reads ledger of business, lines of statement or unsettled lines when statement is empty
+func ReadLines(businessID bson.ObjectId, statementID bson.ObjectId, offset int, count int) ([]*model.SettlementLine, int, error) {
+	lineCollection, session := lineCollection()
+	defer session.Close()
+
+	filter := bson.M{"businessId": businessID, "statementId": bson.M{"$exists": false}}
+	if statementID != "" {
+		filter["statementId"] = statementID
+	}
+	query := lineCollection.Find(filter)
+	totalCount, _ := query.Count()
+	lines := []*model.SettlementLine{}
+	err := query.Sort("-createdAt").Skip(offset).Limit(count).All(&lines)
+	return lines, totalCount, err
+}
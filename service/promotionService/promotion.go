@@ -0,0 +1,421 @@
+package promotionService
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../service/authService/businessService"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func promotionCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("promotion"), session
+}
+
+func usageCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("promotion_usage"), session
+}
+
+func redemptionCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("promotion_redemption"), session
+}
+
+// InitService inits service
+func InitService() {
+	promotionCollection, session := promotionCollection()
+	defer session.Close()
+	promotionCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"code"},
+		Unique: true,
+	})
+
+	usageCollection, usageSession := usageCollection()
+	defer usageSession.Close()
+	usageCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"promotionId", "userId"},
+		Unique: true,
+	})
+
+	redemptionCollection, redemptionSession := redemptionCollection()
+	defer redemptionSession.Close()
+	redemptionCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"orderId"},
+		Unique: true,
+	})
+}
+
+// CreatePromotion creates promotion
+func CreatePromotion(promotion *model.Promotion) (*model.Promotion, error) {
+	promotionCollection, session := promotionCollection()
+	defer session.Close()
+
+	if err := validatePromotion(promotion); err != nil {
+		return nil, err
+	}
+	// Check if code is existed already
+	if count, _ := promotionCollection.Find(bson.M{"code": promotion.Code}).Count(); count > 0 {
+		return nil, errors.New("This promo code is registered already")
+	}
+	promotion.ID = bson.NewObjectId()
+	promotion.RedeemedCount = 0
+	promotion.CreatedAt = timeHelper.GetCurrentTime()
+	promotion.UpdatedAt = timeHelper.GetCurrentTime()
+	// Insert Data
+	err := promotionCollection.Insert(promotion)
+	return promotion, err
+}
+
+// ReadPromotion returns promotion with object id
+func ReadPromotion(objid bson.ObjectId) (*model.Promotion, error) {
+	promotionCollection, session := promotionCollection()
+	defer session.Close()
+
+	promotion := &model.Promotion{}
+	err := promotionCollection.FindId(objid).One(promotion)
+	return promotion, err
+}
+
+// ReadPromotionByCode returns promotion with code
+func ReadPromotionByCode(code string) (*model.Promotion, error) {
+	promotionCollection, session := promotionCollection()
+	defer session.Close()
+
+	promotion := &model.Promotion{}
+	if err := promotionCollection.Find(bson.M{"code": normalizeCode(code)}).One(promotion); err != nil {
+		return nil, errors.New("Promo code is invalid")
+	}
+	return promotion, nil
+}
+
+// UpdatePromotion updates promotion
+func UpdatePromotion(objid bson.ObjectId, promotion *model.Promotion) (*model.Promotion, error) {
+	promotionCollection, session := promotionCollection()
+	defer session.Close()
+
+	if err := validatePromotion(promotion); err != nil {
+		return nil, err
+	}
+	promotion.UpdatedAt = timeHelper.GetCurrentTime()
+	// Create change info
+	change := mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"name":           promotion.Name,
+			"description":    promotion.Description,
+			"type":           promotion.Type,
+			"value":          promotion.Value,
+			"maxDiscount":    promotion.MaxDiscount,
+			"minBasket":      promotion.MinBasket,
+			"businessIds":    promotion.BusinessIDs,
+			"dietaryCodes":   promotion.DietaryCodes,
+			"placeIds":       promotion.PlaceIDs,
+			"firstOrderOnly": promotion.FirstOrderOnly,
+			"perUserLimit":   promotion.PerUserLimit,
+			"totalLimit":     promotion.TotalLimit,
+			"startAt":        promotion.StartAt,
+			"endAt":          promotion.EndAt,
+			"status":         promotion.Status,
+			"updatedAt":      promotion.UpdatedAt,
+		}},
+		ReturnNew: true,
+	}
+	// Update promotion
+	_, err := promotionCollection.FindId(objid).Apply(change, promotion)
+	return promotion, err
+}
+
+// DeletePromotion deletes promotion with object id
+func DeletePromotion(objid bson.ObjectId) error {
+	promotionCollection, session := promotionCollection()
+	defer session.Close()
+
+	err := promotionCollection.RemoveId(objid)
+	return err
+}
+
+// ReadPromotions returns promotions after search query
+func ReadPromotions(query string, offset int, count int) ([]*model.Promotion, int, error) {
+	promotionCollection, session := promotionCollection()
+	defer session.Close()
+
+	param := bson.M{}
+	if query != "" {
+		param = bson.M{"$or": []interface{}{
+			bson.M{"code": bson.RegEx{Pattern: query, Options: "i"}},
+			bson.M{"name": bson.RegEx{Pattern: query, Options: "i"}},
+		}}
+	}
+	totalCount, _ := promotionCollection.Find(param).Count()
+	promotions := []*model.Promotion{}
+	err := promotionCollection.Find(param).Sort("-createdAt").Skip(offset).Limit(count).All(&promotions)
+	return promotions, totalCount, err
+}
+
+// Quote returns price of order with promo code of order
+func Quote(order *model.Order) *model.PriceQuote {
+	quote := &model.PriceQuote{
+		Subtotal:   order.Price,
+		Tax:        order.Tax,
+		BookingFee: order.BookingFee,
+		Lines: []*model.PriceLine{
+			{Label: "Subtotal", Amount: order.Price},
+			{Label: "Tax", Amount: order.Tax},
+			{Label: "Booking fee", Amount: order.BookingFee},
+		},
+	}
+	if order.PromoCode != "" {
+		promotion, err := ReadPromotionByCode(order.PromoCode)
+		if err == nil {
+			quote.Discount, err = Discount(promotion, readCart(order), timeHelper.GetCurrentTime())
+		}
+		if err != nil {
+			quote.Error = err.Error()
+		} else {
+			quote.PromoCode = promotion.Code
+			quote.Promotion = promotion
+			quote.Lines = append(quote.Lines, &model.PriceLine{Label: promotion.Name, Amount: -quote.Discount})
+		}
+	}
+	quote.Total = math.Max(0, quote.Subtotal+quote.Tax+quote.BookingFee-quote.Discount)
+	return quote
+}
+
+// Discount validates promotion for cart and returns discount
+func Discount(promotion *model.Promotion, cart *model.PromotionCart, now int64) (float64, error) {
+	if !promotion.Status || (promotion.StartAt > 0 && now < promotion.StartAt) || (promotion.EndAt > 0 && now > promotion.EndAt) {
+		return 0, errors.New("Promo code is expired")
+	}
+	if promotion.TotalLimit > 0 && promotion.RedeemedCount >= promotion.TotalLimit {
+		return 0, errors.New("Promo code is fully redeemed")
+	}
+	if promotion.PerUserLimit > 0 && cart.UserRedeemed >= promotion.PerUserLimit {
+		return 0, errors.New("You have used this promo code already")
+	}
+	if promotion.UserID != "" && promotion.UserID != cart.UserID {
+		return 0, errors.New("Promo code is invalid")
+	}
+	if promotion.FirstOrderOnly && cart.UserOrders > 0 {
+		return 0, errors.New("Promo code is for first order only")
+	}
+	if cart.Basket < promotion.MinBasket {
+		return 0, errors.New("Basket is less than minimum of promo code")
+	}
+	if len(promotion.PlaceIDs) > 0 && !containString(promotion.PlaceIDs, cart.PlaceID) {
+		return 0, errors.New("Promo code is not available in this city")
+	}
+	if len(promotion.BusinessIDs) > 0 && !containObjectID(promotion.BusinessIDs, cart.BusinessID) {
+		return 0, errors.New("Promo code is not available for this restaurant")
+	}
+	if len(promotion.DietaryCodes) > 0 && !intersectInt(promotion.DietaryCodes, cart.DietaryCodes) {
+		return 0, errors.New("Promo code is not available for this restaurant")
+	}
+
+	discount := 0.0
+	switch promotion.Type {
+	case config.PromotionPercent:
+		discount = cart.Basket * promotion.Value / 100
+	case config.PromotionFixed:
+		discount = promotion.Value
+	case config.PromotionFreeDelivery:
+		discount = cart.BookingFee
+	}
+	if promotion.MaxDiscount > 0 && discount > promotion.MaxDiscount {
+		discount = promotion.MaxDiscount
+	}
+	if promotion.Type != config.PromotionFreeDelivery && discount > cart.Basket {
+		discount = cart.Basket
+	}
+	return math.Floor(discount*100+0.5) / 100, nil
+}
+
+// Redeem records redemption of promotion by order, caps of promotion hold under concurrent orders
+func Redeem(promotion *model.Promotion, order *model.Order) error {
+	// reserve usage of user
+	if promotion.PerUserLimit > 0 {
+		usageCollection, session := usageCollection()
+		defer session.Close()
+
+		_, err := usageCollection.Upsert(bson.M{
+			"promotionId": promotion.ID,
+			"userId":      order.UserID,
+			"count":       bson.M{"$lt": promotion.PerUserLimit},
+		}, bson.M{"$inc": bson.M{"count": 1}})
+		// usage of user reached limit, upsert conflicts with existed usage
+		if mgo.IsDup(err) {
+			return errors.New("You have used this promo code already")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// reserve global usage
+	promotionCollection, session := promotionCollection()
+	defer session.Close()
+
+	query := bson.M{"_id": promotion.ID, "status": true}
+	if promotion.TotalLimit > 0 {
+		query["redeemedCount"] = bson.M{"$lt": promotion.TotalLimit}
+	}
+	if err := promotionCollection.Update(query, bson.M{"$inc": bson.M{"redeemedCount": 1}}); err != nil {
+		if promotion.PerUserLimit > 0 {
+			releaseUsage(promotion.ID, order.UserID)
+		}
+		if err == mgo.ErrNotFound {
+			return errors.New("Promo code is fully redeemed")
+		}
+		return err
+	}
+
+	redemptionCollection, redemptionSession := redemptionCollection()
+	defer redemptionSession.Close()
+
+	return redemptionCollection.Insert(&model.PromotionRedemption{
+		ID:          bson.NewObjectId(),
+		PromotionID: promotion.ID,
+		Code:        promotion.Code,
+		UserID:      order.UserID,
+		OrderID:     order.ID,
+		Discount:    order.Discount,
+		CreatedAt:   timeHelper.GetCurrentTime(),
+	})
+}
+
+// Release releases redemption of order that is declined or cancelled
+func Release(orderID bson.ObjectId) error {
+	redemptionCollection, session := redemptionCollection()
+	defer session.Close()
+
+	redemption := &model.PromotionRedemption{}
+	change := mgo.Change{
+		Update:    bson.M{"$set": bson.M{"released": true}},
+		ReturnNew: true,
+	}
+	if _, err := redemptionCollection.Find(bson.M{"orderId": orderID, "released": false}).Apply(change, redemption); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	promotionCollection, promotionSession := promotionCollection()
+	defer promotionSession.Close()
+	promotionCollection.Update(bson.M{"_id": redemption.PromotionID, "redeemedCount": bson.M{"$gt": 0}},
+		bson.M{"$inc": bson.M{"redeemedCount": -1}})
+
+	releaseUsage(redemption.PromotionID, redemption.UserID)
+	return nil
+}
+
+// ReadRedemptions returns redemptions of promotion
+func ReadRedemptions(promotionID bson.ObjectId, offset int, count int) ([]*model.PromotionRedemption, int, error) {
+	redemptionCollection, session := redemptionCollection()
+	defer session.Close()
+
+	query := bson.M{"promotionId": promotionID}
+	totalCount, _ := redemptionCollection.Find(query).Count()
+	redemptions := []*model.PromotionRedemption{}
+	err := redemptionCollection.Find(query).Sort("-createdAt").Skip(offset).Limit(count).All(&redemptions)
+	return redemptions, totalCount, err
+}
+
+func releaseUsage(promotionID bson.ObjectId, userID bson.ObjectId) {
+	usageCollection, session := usageCollection()
+	defer session.Close()
+
+	usageCollection.Update(bson.M{
+		"promotionId": promotionID,
+		"userId":      userID,
+		"count":       bson.M{"$gt": 0},
+	}, bson.M{"$inc": bson.M{"count": -1}})
+}
+
+// readCart returns cart of order with history of user
+func readCart(order *model.Order) *model.PromotionCart {
+	cart := &model.PromotionCart{
+		UserID:     order.UserID,
+		BusinessID: order.BusinessID,
+		PlaceID:    order.PlaceID,
+		Basket:     order.Price,
+		BookingFee: order.BookingFee,
+	}
+	if business, err := businessService.ReadBusiness(order.BusinessID); err == nil {
+		cart.DietaryCodes = business.DietaryCodes
+	}
+
+	mgoDB, session := db.MongoDB()
+	defer session.Close()
+	// orders that are not declined or cancelled
+	cart.UserOrders, _ = mgoDB.C("order").Find(bson.M{
+		"userId":      order.UserID,
+		"orderStatus": bson.M{"$nin": []string{config.OrderDeclined, config.OrderCancelled}},
+	}).Count()
+	cart.UserRedeemed, _ = mgoDB.C("promotion_redemption").Find(bson.M{
+		"userId":   order.UserID,
+		"code":     normalizeCode(order.PromoCode),
+		"released": false,
+	}).Count()
+	return cart
+}
+
+func validatePromotion(promotion *model.Promotion) error {
+	promotion.Code = normalizeCode(promotion.Code)
+	if promotion.Code == "" {
+		return errors.New("Promo code is empty")
+	}
+	switch promotion.Type {
+	case config.PromotionPercent:
+		if promotion.Value <= 0 || promotion.Value > 100 {
+			return errors.New("Percent of promotion is invalid")
+		}
+	case config.PromotionFixed:
+		if promotion.Value <= 0 {
+			return errors.New("Amount of promotion is invalid")
+		}
+	case config.PromotionFreeDelivery:
+	default:
+		return errors.New("Type of promotion is invalid")
+	}
+	return nil
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func containString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containObjectID(values []bson.ObjectId, value bson.ObjectId) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func intersectInt(a []int, b []int) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package promotionService
+
+import (
+	"testing"
+
+	"../../config"
+	"../../model"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestDiscount(t *testing.T) {
+	businessID := bson.NewObjectId()
+	cart := func() *model.PromotionCart {
+		return &model.PromotionCart{
+			BusinessID:   businessID,
+			DietaryCodes: []int{1, 2},
+			PlaceID:      "place",
+			Basket:       40,
+			BookingFee:   3,
+		}
+	}
+	cases := []struct {
+		promotion *model.Promotion
+		cart      *model.PromotionCart
+		discount  float64
+		valid     bool
+	}{
+		{&model.Promotion{Type: config.PromotionPercent, Value: 15, Status: true}, cart(), 6, true},
+		{&model.Promotion{Type: config.PromotionPercent, Value: 50, MaxDiscount: 10, Status: true}, cart(), 10, true},
+		{&model.Promotion{Type: config.PromotionFixed, Value: 50, Status: true}, cart(), 40, true},
+		{&model.Promotion{Type: config.PromotionFreeDelivery, Status: true}, cart(), 3, true},
+		{&model.Promotion{Type: config.PromotionFixed, Value: 5, Status: false}, cart(), 0, false},
+		{&model.Promotion{Type: config.PromotionFixed, Value: 5, Status: true, EndAt: 99}, cart(), 0, false},
+		{&model.Promotion{Type: config.PromotionFixed, Value: 5, Status: true, MinBasket: 50}, cart(), 0, false},
+		{&model.Promotion{Type: config.PromotionFixed, Value: 5, Status: true, TotalLimit: 2, RedeemedCount: 2}, cart(), 0, false},
+		{&model.Promotion{Type: config.PromotionFixed, Value: 5, Status: true, PerUserLimit: 1}, &model.PromotionCart{Basket: 40, UserRedeemed: 1}, 0, false},
+		{&model.Promotion{Type: config.PromotionFixed, Value: 5, Status: true, FirstOrderOnly: true}, &model.PromotionCart{Basket: 40, UserOrders: 1}, 0, false},
+		{&model.Promotion{Type: config.PromotionFixed, Value: 5, Status: true, PlaceIDs: []string{"other"}}, cart(), 0, false},
+		{&model.Promotion{Type: config.PromotionFixed, Value: 5, Status: true, BusinessIDs: []bson.ObjectId{businessID}}, cart(), 5, true},
+		{&model.Promotion{Type: config.PromotionFixed, Value: 5, Status: true, DietaryCodes: []int{3}}, cart(), 0, false},
+		{&model.Promotion{Type: config.PromotionFixed, Value: 5, Status: true, DietaryCodes: []int{2, 3}}, cart(), 5, true},
+	}
+	for i, test := range cases {
+		discount, err := Discount(test.promotion, test.cart, 100)
+		if (err == nil) != test.valid || discount != test.discount {
+			t.Errorf("case %d: discount is %v, %v", i, discount, err)
+		}
+	}
+}
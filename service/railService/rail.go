@@ -0,0 +1,128 @@
+package railService
+
+import (
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func railCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("rail"), session
+}
+
+// InitService inits service
+func InitService() {
+	railCollection, session := railCollection()
+	defer session.Close()
+
+	// home screen rails that were hard-coded before
+	if c, _ := railCollection.Find(bson.M{}).Count(); c > 0 {
+		return
+	}
+	rails := []*model.Rail{
+		{Code: config.QueryPopular, Title: "Most Popular", IsSlide: true, Position: 1,
+			Query: &model.DiscoveryQuery{MostPopular: true, Sort: config.DiscoverDistance, Count: 10}},
+		{Code: config.QueryRecommend, Title: "Recommended", IsSlide: true, Position: 2,
+			Query: &model.DiscoveryQuery{Recommend: true, Sort: config.DiscoverDistance, Count: 10}},
+		{Code: config.QueryUnder, Title: "Under 30 minutes", IsSlide: true, Position: 3,
+			Query: &model.DiscoveryQuery{MaxETA: 30, Sort: config.DiscoverETA, Count: 10}},
+		{Code: config.QueryAll, Title: "More Restaurants", IsSlide: false, Position: 4,
+			Query: &model.DiscoveryQuery{Sort: config.DiscoverDistance, Count: config.DiscoverCount}},
+	}
+	for _, rail := range rails {
+		rail.ID = bson.NewObjectId()
+		rail.Enabled = true
+		rail.CreatedAt = timeHelper.GetCurrentTime()
+		rail.UpdatedAt = timeHelper.GetCurrentTime()
+		railCollection.Insert(rail)
+	}
+}
+
+// CreateRail creates rail
+func CreateRail(rail *model.Rail) (*model.Rail, error) {
+	railCollection, session := railCollection()
+	defer session.Close()
+
+	rail.ID = bson.NewObjectId()
+	rail.Code = createCode()
+	if rail.Query == nil {
+		rail.Query = &model.DiscoveryQuery{}
+	}
+	rail.CreatedAt = timeHelper.GetCurrentTime()
+	rail.UpdatedAt = timeHelper.GetCurrentTime()
+	err := railCollection.Insert(rail)
+	return rail, err
+}
+
+// ReadRail returns rail with object id
+func ReadRail(objid bson.ObjectId) (*model.Rail, error) {
+	railCollection, session := railCollection()
+	defer session.Close()
+
+	rail := &model.Rail{}
+	err := railCollection.FindId(objid).One(rail)
+	return rail, err
+}
+
+// UpdateRail updates rail
+func UpdateRail(objid bson.ObjectId, rail *model.Rail) (*model.Rail, error) {
+	railCollection, session := railCollection()
+	defer session.Close()
+
+	rail.UpdatedAt = timeHelper.GetCurrentTime()
+	change := mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"title":     rail.Title,
+			"isSlide":   rail.IsSlide,
+			"query":     rail.Query,
+			"position":  rail.Position,
+			"enabled":   rail.Enabled,
+			"updatedAt": rail.UpdatedAt,
+		}},
+		ReturnNew: true,
+	}
+	_, err := railCollection.FindId(objid).Apply(change, rail)
+	return rail, err
+}
+
+// DeleteRail deletes rail with object id
+func DeleteRail(objid bson.ObjectId) error {
+	railCollection, session := railCollection()
+	defer session.Close()
+
+	err := railCollection.RemoveId(objid)
+	return err
+}
+
+// ReadRails returns rails in order of position, enabled returns rails only for home screen
+func ReadRails(enabled bool) ([]*model.Rail, error) {
+	railCollection, session := railCollection()
+	defer session.Close()
+
+	query := bson.M{}
+	if enabled {
+		query["enabled"] = true
+	}
+	rails := []*model.Rail{}
+	err := railCollection.Find(query).Sort("position", "code").All(&rails)
+	return rails, err
+}
+
+func createCode() int {
+	railCollection, session := railCollection()
+	defer session.Close()
+
+	rail := &model.Rail{}
+	if err := railCollection.Pipe([]bson.M{
+		{"$sort": bson.M{"code": -1}},
+		{"$limit": 1},
+	}).One(&rail); err != nil {
+		return 1
+	}
+	return rail.Code + 1
+}
@@ -0,0 +1,275 @@
+package ratingService
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// subject field and rated fields of order for each subject type, driver and user are rated by business too
+var orderFields = map[string]struct {
+	subject string
+	rates   []string
+}{
+	config.RatingBusiness: {"businessId", []string{"businessRate"}},
+	config.RatingDriver:   {"driverId", []string{"driverRate", "businessDriverRate"}},
+	config.RatingUser:     {"userId", []string{"userRate", "businessUserRate"}},
+}
+
+func ratingCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("rating_summary"), session
+}
+
+// InitService inits service
+func InitService() {
+	ratingCollection, session := ratingCollection()
+	defer session.Close()
+
+	ratingCollection.EnsureIndex(mgo.Index{
+		Key:        []string{"subjectType", "-average"},
+		Background: true,
+	})
+}
+
+// AddRating adds rating of order to summary of subject and updates rating of subject
+func AddRating(subjectType string, subjectID bson.ObjectId, orderID bson.ObjectId, rate float32) (*model.RatingSummary, error) {
+	if _, ok := orderFields[subjectType]; !ok {
+		return nil, errors.New("Rating type is invalid")
+	}
+	if subjectID == "" || rate <= 0 {
+		return nil, errors.New("Rating is empty")
+	}
+	if rate < config.RatingMin || rate > config.RatingMax {
+		return nil, errors.New("Rate must be between 1 and 5")
+	}
+	ratingCollection, session := ratingCollection()
+	defer session.Close()
+
+	recent := &model.RecentRating{
+		OrderID:   orderID,
+		Rate:      rate,
+		CreatedAt: timeHelper.GetCurrentTime(),
+	}
+	summary := &model.RatingSummary{}
+	change := mgo.Change{
+		Update: bson.M{
+			"$inc": bson.M{
+				"count":                      1,
+				"total":                      rate,
+				"distribution." + star(rate): 1,
+			},
+			"$push": bson.M{"recent": bson.M{
+				"$each":  []*model.RecentRating{recent},
+				"$slice": -config.RatingRecentCount,
+			}},
+			"$set":         bson.M{"updatedAt": timeHelper.GetCurrentTime()},
+			"$setOnInsert": bson.M{"subjectType": subjectType},
+		},
+		Upsert:    true,
+		ReturnNew: true,
+	}
+	if _, err := ratingCollection.FindId(subjectID).Apply(change, summary); err != nil {
+		return nil, err
+	}
+	calculateAverages(summary)
+	return summary, saveAverages(summary)
+}
+
+// RebuildRatings recomputes summaries of subject type from order history, all subjects are rebuilt with empty id
+func RebuildRatings(subjectType string, subjectID bson.ObjectId) (int, error) {
+	fields, ok := orderFields[subjectType]
+	if !ok {
+		return 0, errors.New("Rating type is invalid")
+	}
+	mgoDB, session := db.MongoDB()
+	defer session.Close()
+
+	rated := []bson.M{}
+	rates := []string{}
+	for _, field := range fields.rates {
+		rated = append(rated, bson.M{field: bson.M{"$gt": 0}})
+		rates = append(rates, "$"+field)
+	}
+	match := bson.M{"$or": rated}
+	if subjectID != "" {
+		match[fields.subject] = subjectID
+	}
+	groups := []struct {
+		ID      bson.ObjectId         `bson:"_id"`
+		Ratings []*model.RecentRating `bson:"ratings"`
+	}{}
+	// each rated field of order is one rating
+	if err := mgoDB.C("order").Pipe([]bson.M{
+		{"$match": match},
+		{"$project": bson.M{"subject": "$" + fields.subject, "updatedAt": 1, "rate": rates}},
+		{"$unwind": "$rate"},
+		{"$match": bson.M{"rate": bson.M{"$gt": 0}}},
+		{"$sort": bson.M{"updatedAt": 1}},
+		{"$group": bson.M{
+			"_id": "$subject",
+			"ratings": bson.M{"$push": bson.M{
+				"orderId":   "$_id",
+				"rate":      "$rate",
+				"createdAt": "$updatedAt",
+			}},
+		}},
+	}).All(&groups); err != nil {
+		return 0, err
+	}
+
+	ratingCollection := mgoDB.C("rating_summary")
+	for _, group := range groups {
+		summary := summarize(subjectType, group.ID, group.Ratings)
+		if _, err := ratingCollection.UpsertId(summary.ID, summary); err != nil {
+			return 0, err
+		}
+		if err := saveRating(mgoDB, summary, true); err != nil {
+			return 0, err
+		}
+	}
+	return len(groups), nil
+}
+
+// ReadRatingSummary returns rating summary of subject
+func ReadRatingSummary(subjectID bson.ObjectId) (*model.RatingSummary, error) {
+	ratingCollection, session := ratingCollection()
+	defer session.Close()
+
+	summary := &model.RatingSummary{}
+	err := ratingCollection.FindId(subjectID).One(summary)
+	return summary, err
+}
+
+// ReadRatingSummaries returns rating summaries of subject type for admin
+func ReadRatingSummaries(subjectType string, offset int, count int, sort int) ([]*model.RatingSummary, int, error) {
+	ratingCollection, session := ratingCollection()
+	defer session.Close()
+
+	pipe := []bson.M{{"$match": bson.M{"subjectType": subjectType}}}
+	// get total count of collection with initial query
+	totalCount := db.GetCountOfCollection(ratingCollection, &pipe)
+
+	if sort == 0 {
+		sort = -1
+	}
+	pipe = append(pipe, bson.M{"$sort": bson.M{"average": sort}})
+	// add page feature
+	if offset == 0 && count == 0 {
+	} else {
+		pipe = append(pipe, bson.M{"$skip": offset})
+		pipe = append(pipe, bson.M{"$limit": count})
+	}
+	summaries := []*model.RatingSummary{}
+	err := ratingCollection.Pipe(pipe).All(&summaries)
+	return summaries, totalCount, err
+}
+
+// ReadRatingDistribution returns count of each star for all subjects of type
+func ReadRatingDistribution(subjectType string) (map[string]int, error) {
+	ratingCollection, session := ratingCollection()
+	defer session.Close()
+
+	group := bson.M{"_id": nil}
+	for i := 1; i <= 5; i++ {
+		group[strconv.Itoa(i)] = bson.M{"$sum": "$distribution." + strconv.Itoa(i)}
+	}
+	distribution := map[string]int{}
+	err := ratingCollection.Pipe([]bson.M{
+		{"$match": bson.M{"subjectType": subjectType}},
+		{"$group": group},
+		{"$project": bson.M{"_id": 0}},
+	}).One(&distribution)
+	if err == mgo.ErrNotFound {
+		return distribution, nil
+	}
+	return distribution, err
+}
+
+// summarize builds rating summary from ratings in order of time
+func summarize(subjectType string, subjectID bson.ObjectId, ratings []*model.RecentRating) *model.RatingSummary {
+	summary := &model.RatingSummary{
+		ID:           subjectID,
+		SubjectType:  subjectType,
+		Distribution: map[string]int{},
+		UpdatedAt:    timeHelper.GetCurrentTime(),
+	}
+	for _, rating := range ratings {
+		summary.Count++
+		summary.Total += float64(rating.Rate)
+		summary.Distribution[star(rating.Rate)]++
+	}
+	summary.Recent = ratings
+	if len(ratings) > config.RatingRecentCount {
+		summary.Recent = ratings[len(ratings)-config.RatingRecentCount:]
+	}
+	calculateAverages(summary)
+	return summary
+}
+
+func calculateAverages(summary *model.RatingSummary) {
+	if summary.Count > 0 {
+		summary.Average = float32(summary.Total / float64(summary.Count))
+	}
+	total := float32(0)
+	for _, rating := range summary.Recent {
+		total += rating.Rate
+	}
+	summary.RecentCount = len(summary.Recent)
+	if summary.RecentCount > 0 {
+		summary.RecentAverage = total / float32(summary.RecentCount)
+	}
+}
+
+// saveAverages saves averages in summary and rating of subject
+func saveAverages(summary *model.RatingSummary) error {
+	mgoDB, session := db.MongoDB()
+	defer session.Close()
+
+	// newer rating may be saved already by concurrent request
+	mgoDB.C("rating_summary").Update(bson.M{"_id": summary.ID, "count": summary.Count}, bson.M{"$set": bson.M{
+		"average":       summary.Average,
+		"recentCount":   summary.RecentCount,
+		"recentAverage": summary.RecentAverage,
+	}})
+	return saveRating(mgoDB, summary, false)
+}
+
+// saveRating updates rating of business, driver or user
+func saveRating(mgoDB *mgo.Database, summary *model.RatingSummary, force bool) error {
+	query := bson.M{"_id": summary.ID}
+	if !force {
+		query["$or"] = []bson.M{
+			{"ratingCount": bson.M{"$lt": summary.Count}},
+			{"ratingCount": bson.M{"$exists": false}},
+		}
+	}
+	err := mgoDB.C(summary.SubjectType).Update(query, bson.M{"$set": bson.M{
+		"rating":      summary.Average,
+		"ratingCount": summary.Count,
+	}})
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// star returns star bucket of rate between 1 and 5
+func star(rate float32) string {
+	s := int(math.Floor(float64(rate) + 0.5))
+	if s < 1 {
+		s = 1
+	}
+	if s > 5 {
+		s = 5
+	}
+	return strconv.Itoa(s)
+}
@@ -0,0 +1,41 @@
+package ratingService
+
+import (
+	"testing"
+
+	"../../config"
+	"../../model"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestSummarize(t *testing.T) {
+	ratings := []*model.RecentRating{}
+	for i := 0; i < config.RatingRecentCount; i++ {
+		ratings = append(ratings, &model.RecentRating{Rate: 5})
+	}
+	ratings = append([]*model.RecentRating{{Rate: 1}, {Rate: 2.6}}, ratings...)
+
+	summary := summarize(config.RatingDriver, bson.NewObjectId(), ratings)
+	if summary.Count != config.RatingRecentCount+2 {
+		t.Errorf("expected count %d, got %d", config.RatingRecentCount+2, summary.Count)
+	}
+	if summary.RecentCount != config.RatingRecentCount || summary.RecentAverage != 5 {
+		t.Errorf("recent window should keep last %d ratings, got %d with %f", config.RatingRecentCount, summary.RecentCount, summary.RecentAverage)
+	}
+	if summary.Distribution["1"] != 1 || summary.Distribution["3"] != 1 || summary.Distribution["5"] != config.RatingRecentCount {
+		t.Errorf("unexpected distribution %v", summary.Distribution)
+	}
+	if summary.Average >= 5 || summary.Average < 4.9 {
+		t.Errorf("unexpected average %f", summary.Average)
+	}
+}
+
+func TestAddRatingOutOfStars(t *testing.T) {
+	// rate is checked before summary is updated
+	for _, rate := range []float32{0, 0.5, 5.5, 1e6} {
+		if _, err := AddRating(config.RatingBusiness, bson.NewObjectId(), bson.NewObjectId(), rate); err == nil {
+			t.Errorf("rate %v is added", rate)
+		}
+	}
+}
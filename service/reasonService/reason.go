@@ -25,6 +25,16 @@ func InitService() {
 	CreateReason(&model.Reason{Code: 303, Type: config.ReasonTripCancel, Status: true, Message: "Other"})
 	CreateReason(&model.Reason{Code: 304, Type: config.ReasonTripCancel, Status: true, Message: "Too far away"})
 	CreateReason(&model.Reason{Code: 305, Type: config.ReasonTripCancel, Status: true, Message: "I don't want to do  delivery"})
+
+	userCancel := &model.RefundPolicy{Type: config.RefundFull, LateType: config.RefundFeeOnly}
+	CreateReason(&model.Reason{Code: 100, Type: config.ReasonUserCancel, Status: true, Message: "Changed my mind", RefundPolicy: userCancel})
+	CreateReason(&model.Reason{Code: 101, Type: config.ReasonUserCancel, Status: true, Message: "Order takes too long", RefundPolicy: userCancel})
+	CreateReason(&model.Reason{Code: 102, Type: config.ReasonUserCancel, Status: true, Message: "Wrong order", RefundPolicy: userCancel})
+
+	businessDecline := &model.RefundPolicy{Type: config.RefundFull, LateType: config.RefundFull}
+	CreateReason(&model.Reason{Code: 200, Type: config.ReasonOrderDecline, Status: true, Message: "Restaurant is too busy", RefundPolicy: businessDecline})
+	CreateReason(&model.Reason{Code: 201, Type: config.ReasonOrderDecline, Status: true, Message: "Food is sold out", RefundPolicy: businessDecline})
+	CreateReason(&model.Reason{Code: 202, Type: config.ReasonOrderDecline, Status: true, Message: "Restaurant is closed", RefundPolicy: businessDecline})
 }
 
 // CreateReason creates reason
@@ -59,6 +69,16 @@ func ReadReason(objid bson.ObjectId) (*model.Reason, error) {
 	return reason, err
 }
 
+// ReadReasonByCode returns reason with code
+func ReadReasonByCode(code int) (*model.Reason, error) {
+	reasonCollection, session := reasonCollection()
+	defer session.Close()
+
+	reason := &model.Reason{}
+	err := reasonCollection.Find(bson.M{"code": code}).One(reason)
+	return reason, err
+}
+
 // UpdateReason updates reason
 func UpdateReason(objid bson.ObjectId, reason *model.Reason) (*model.Reason, error) {
 	reasonCollection, session := reasonCollection()
@@ -68,10 +88,11 @@ func UpdateReason(objid bson.ObjectId, reason *model.Reason) (*model.Reason, err
 	// Create change info
 	change := mgo.Change{
 		Update: bson.M{"$set": bson.M{
-			"type":      reason.Type,
-			"message":   reason.Message,
-			"status":    reason.Status,
-			"updatedAt": reason.UpdatedAt,
+			"type":         reason.Type,
+			"message":      reason.Message,
+			"status":       reason.Status,
+			"refundPolicy": reason.RefundPolicy,
+			"updatedAt":    reason.UpdatedAt,
 		}},
 		ReturnNew: true,
 	}
@@ -0,0 +1,254 @@
+package receiptService
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../service/paymentService"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func receiptCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("receipt"), session
+}
+
+func counterCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("invoice_counter"), session
+}
+
+// InitService inits service
+func InitService() {
+	receiptCollection, session := receiptCollection()
+	defer session.Close()
+
+	// order has only one receipt
+	receiptCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"orderId"},
+		Unique: true,
+	})
+}
+
+// InvoiceRequired checks that business in country requires sequential invoice number
+func InvoiceRequired(countryCode string) bool {
+	for _, code := range config.InvoiceCountries {
+		if strings.EqualFold(code, countryCode) {
+			return true
+		}
+	}
+	return false
+}
+
+// IssueReceipt issues receipt of completed order once, invoice number is given when business requires it
+func IssueReceipt(order *model.Order) (*model.ReceiptRecord, error) {
+	if order.OrderStatus != config.OrderCompleted {
+		return nil, errors.New("Order is not completed")
+	}
+	receiptCollection, session := receiptCollection()
+	defer session.Close()
+
+	record := &model.ReceiptRecord{}
+	if err := receiptCollection.Find(bson.M{"orderId": order.ID}).One(record); err != nil {
+		record = &model.ReceiptRecord{
+			ID:         bson.NewObjectId(),
+			OrderID:    order.ID,
+			BusinessID: order.BusinessID,
+			IssuedAt:   timeHelper.GetCurrentTime(),
+		}
+		if err := receiptCollection.Insert(record); err != nil {
+			if !mgo.IsDup(err) {
+				return record, err
+			}
+			// issued by other request
+			if err := receiptCollection.Find(bson.M{"orderId": order.ID}).One(record); err != nil {
+				return record, err
+			}
+		}
+	}
+	// sequence is taken after receipt is inserted so that numbers have no gap,
+	// receipt that failed to get number gets it when it is read again
+	if record.InvoiceNumber != "" || order.Business == nil || !InvoiceRequired(order.Business.CountryCode) {
+		return record, nil
+	}
+	sequence, err := nextSequence(order.BusinessID)
+	if err != nil {
+		return record, err
+	}
+	number := InvoiceNumber(order.BusinessID, sequence)
+	err = receiptCollection.Update(
+		bson.M{"_id": record.ID, "invoiceNumber": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"sequence": sequence, "invoiceNumber": number}},
+	)
+	if err == mgo.ErrNotFound {
+		// numbered by other request
+		err = receiptCollection.FindId(record.ID).One(record)
+		return record, err
+	}
+	if err == nil {
+		record.Sequence = sequence
+		record.InvoiceNumber = number
+	}
+	return record, err
+}
+
+// InvoiceNumber returns invoice number of sequence of business
+func InvoiceNumber(businessID bson.ObjectId, sequence int64) string {
+	hex := businessID.Hex()
+	return fmt.Sprintf("%s-%s-%06d", config.InvoicePrefix, strings.ToUpper(hex[len(hex)-6:]), sequence)
+}
+
+func nextSequence(businessID bson.ObjectId) (int64, error) {
+	counterCollection, session := counterCollection()
+	defer session.Close()
+
+	counter := &struct {
+		Seq int64 `bson:"seq"`
+	}{}
+	change := mgo.Change{
+		Update:    bson.M{"$inc": bson.M{"seq": 1}},
+		Upsert:    true,
+		ReturnNew: true,
+	}
+	_, err := counterCollection.FindId(businessID).Apply(change, counter)
+	return counter.Seq, err
+}
+
+// MarkEmailed marks receipt of order as emailed, returns false when it is emailed already
+func MarkEmailed(orderID bson.ObjectId) bool {
+	receiptCollection, session := receiptCollection()
+	defer session.Close()
+
+	err := receiptCollection.Update(
+		bson.M{"orderId": orderID, "emailedAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"emailedAt": timeHelper.GetCurrentTime()}},
+	)
+	return err == nil
+}
+
+// UnmarkEmailed marks receipt of order as not emailed when sending fails
+func UnmarkEmailed(orderID bson.ObjectId) error {
+	receiptCollection, session := receiptCollection()
+	defer session.Close()
+
+	return receiptCollection.Update(bson.M{"orderId": orderID}, bson.M{"$unset": bson.M{"emailedAt": ""}})
+}
+
+// ReadReceipt reads receipt of completed order with current tip and refunds
+func ReadReceipt(order *model.Order) (*model.Receipt, error) {
+	record, err := IssueReceipt(order)
+	if err != nil {
+		return nil, err
+	}
+	var method *model.PaymentMethod
+	if order.Payment != nil && order.Payment.MethodID != "" {
+		method, _ = paymentService.ReadPaymentMethod(order.Payment.MethodID)
+	}
+	refunds, _, err := paymentService.ReadRefunds(order.ID, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return BuildReceipt(order, record, method, refunds), nil
+}
+
+// BuildReceipt returns receipt of order with payment method and refund ledger
+func BuildReceipt(order *model.Order, record *model.ReceiptRecord, method *model.PaymentMethod, refunds []*model.Refund) *model.Receipt {
+	receipt := &model.Receipt{
+		OrderID:       order.ID,
+		UserID:        order.UserID,
+		BusinessID:    order.BusinessID,
+		Number:        order.Number,
+		InvoiceNumber: record.InvoiceNumber,
+		IssuedAt:      record.IssuedAt,
+		Subtotal:      paymentService.ToCents(order.Price),
+		Tax:           paymentService.ToCents(order.Tax),
+		BookingFee:    paymentService.ToCents(order.BookingFee),
+		Discount:      paymentService.ToCents(order.Discount),
+		PromoCode:     order.PromoCode,
+		Tip:           paymentService.ToCents(order.Tip),
+		Items:         []*model.ReceiptItem{},
+		Refunds:       []*model.ReceiptRefund{},
+		Currency:      config.PaymentCurrency,
+	}
+	if business := order.Business; business != nil {
+		receipt.BusinessName = business.Name
+		receipt.Identification = business.Identification
+		receipt.BusinessAddr = business.GeoLocation.Address
+	}
+	if user := order.User; user != nil {
+		receipt.CustomerName = strings.TrimSpace(user.Firstname + " " + user.Lastname)
+		receipt.CustomerEmail = user.Email
+	}
+	if order.DeliveryLocation != nil {
+		receipt.DeliveryAddr = order.DeliveryLocation.Address
+	}
+
+	for _, food := range order.Foods {
+		item := &model.ReceiptItem{Count: food.Count, Price: paymentService.ToCents(food.Price), Note: food.Note}
+		if food.Food != nil && food.Food.Food != nil {
+			item.Name = food.Food.Food.Name
+			item.Options = selectedOptions(food.Food.Food.FoodType)
+		}
+		receipt.Items = append(receipt.Items, item)
+	}
+
+	total := receipt.Subtotal + receipt.Tax + receipt.BookingFee - receipt.Discount
+	if total < 0 {
+		total = 0
+	}
+	receipt.Total = total + receipt.Tip
+	if payment := order.Payment; payment != nil {
+		receipt.Wallet = payment.Wallet
+		receipt.Card = payment.Amount
+		if payment.Currency != "" {
+			receipt.Currency = payment.Currency
+		}
+	}
+	// tip after trip is charged separately with card
+	if order.TipPayment != nil {
+		receipt.Card += order.TipPayment.Amount
+	}
+	switch {
+	case method != nil:
+		receipt.PaymentMethod = fmt.Sprintf("%s **** %s", method.Brand, method.Last4)
+	case receipt.Card == 0 && receipt.Wallet > 0:
+		receipt.PaymentMethod = "Wallet"
+	}
+
+	for _, refund := range refunds {
+		if refund.Status != config.RefundSucceeded {
+			continue
+		}
+		receipt.Refunds = append(receipt.Refunds, &model.ReceiptRefund{
+			Amount:    refund.Amount,
+			Method:    refund.Method,
+			Note:      refund.Note,
+			CreatedAt: refund.CreatedAt,
+		})
+		receipt.Refunded += refund.Amount
+	}
+	return receipt
+}
+
+// selectedOptions returns names of options that are chosen for food
+func selectedOptions(foodType *model.FoodType) []string {
+	options := []string{}
+	if foodType == nil {
+		return options
+	}
+	for _, foodOption := range foodType.FoodOption {
+		for _, option := range foodOption.Options {
+			if option.Enabled {
+				options = append(options, option.Name)
+			}
+		}
+	}
+	return options
+}
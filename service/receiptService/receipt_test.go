@@ -0,0 +1,84 @@
+package receiptService
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"../../config"
+	"../../model"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func testOrder(t *testing.T) *model.Order {
+	order := &model.Order{}
+	err := json.Unmarshal([]byte(`{
+		"number": "A100",
+		"foods": [
+			{"count": 2, "price": 20, "food": {"food": {"name": "Burger", "foodType": {"foodOptions": [
+				{"options": [{"name": "Cheese", "enabled": true}, {"name": "Bacon", "enabled": false}]}
+			]}}}},
+			{"count": 1, "price": 4.5, "note": "no ice", "food": {"food": {"name": "Cola <large>"}}}
+		],
+		"price": 24.5, "tax": 2, "bookingFee": 1.5, "discount": 3, "promoCode": "SAVE3", "tip": 4,
+		"business": {"name": "Diner", "identification": "DE123456789", "countryCode": "DE"},
+		"user": {"firstname": "Ann", "lastname": "Lee", "email": "ann@example.com"},
+		"payment": {"amount": 2400, "wallet": 500, "currency": "usd"}
+	}`), order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order.ID = bson.NewObjectId()
+	return order
+}
+
+func TestBuildReceipt(t *testing.T) {
+	order := testOrder(t)
+	record := &model.ReceiptRecord{InvoiceNumber: "INV-000001-000007", IssuedAt: 1497830400}
+	method := &model.PaymentMethod{Brand: "Visa", Last4: "4242"}
+	refunds := []*model.Refund{
+		{Amount: 450, Method: config.RefundGateway, Status: config.RefundSucceeded},
+		{Amount: 999, Method: config.RefundGateway, Status: config.RefundFailed},
+	}
+	receipt := BuildReceipt(order, record, method, refunds)
+
+	if receipt.Total != 2900 || receipt.Subtotal != 2450 || receipt.Discount != 300 || receipt.Tip != 400 {
+		t.Errorf("amounts of receipt = %+v", receipt)
+	}
+	if receipt.Card != 2400 || receipt.Wallet != 500 || receipt.PaymentMethod != "Visa **** 4242" {
+		t.Errorf("payment of receipt = %+v", receipt)
+	}
+	if len(receipt.Refunds) != 1 || receipt.Refunded != 450 {
+		t.Errorf("refunds of receipt = %+v", receipt.Refunds)
+	}
+	if len(receipt.Items) != 2 || receipt.Items[0].Price != 2000 || strings.Join(receipt.Items[0].Options, ",") != "Cheese" {
+		t.Errorf("items of receipt = %+v", receipt.Items[0])
+	}
+	if receipt.Identification != "DE123456789" || receipt.InvoiceNumber != record.InvoiceNumber {
+		t.Errorf("identity of receipt = %+v", receipt)
+	}
+
+	html, err := RenderHTML(receipt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Invoice INV-000001-000007", "Cola &lt;large&gt;", "Discount (SAVE3)", "-$4.50", "$29.00"} {
+		if !bytes.Contains(html, []byte(want)) {
+			t.Errorf("html does not contain %q", want)
+		}
+	}
+	if data := RenderPDF(receipt); !bytes.HasPrefix(data, []byte("%PDF-")) || !bytes.Contains(data, []byte("(Paid with Visa **** 4242)")) {
+		t.Error("pdf of receipt is invalid")
+	}
+}
+
+func TestInvoiceNumber(t *testing.T) {
+	if got := InvoiceNumber(bson.ObjectIdHex("5940d1e2a1b2c3d4e5f6a7b8"), 42); got != "INV-F6A7B8-000042" {
+		t.Errorf("InvoiceNumber = %s", got)
+	}
+	if !InvoiceRequired("de") || InvoiceRequired("US") {
+		t.Error("InvoiceRequired is wrong")
+	}
+}
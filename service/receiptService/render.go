@@ -0,0 +1,139 @@
+package receiptService
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"../../model"
+	"../../util/pdf"
+)
+
+var receiptTemplate = template.Must(template.New("receipt").Funcs(template.FuncMap{
+	"money": Money,
+	"date":  receiptDate,
+	"join":  strings.Join,
+}).Parse(`<div class="receipt">
+	<h2>{{.BusinessName}}</h2>
+	{{if .Identification}}<p>Tax ID: {{.Identification}}</p>{{end}}
+	{{if .BusinessAddr}}<p>{{.BusinessAddr}}</p>{{end}}
+	<p>{{if .InvoiceNumber}}Invoice {{.InvoiceNumber}}<br>{{end}}Order {{.Number}}<br>{{date .IssuedAt}}</p>
+	<p>{{.CustomerName}}{{if .DeliveryAddr}}<br>{{.DeliveryAddr}}{{end}}</p>
+	<table>
+		{{range .Items}}<tr>
+			<td>{{.Count}} x {{.Name}}{{if .Options}}<br><small>{{join .Options ", "}}</small>{{end}}{{if .Note}}<br><small>{{.Note}}</small>{{end}}</td>
+			<td align="right">{{money .Price}}</td>
+		</tr>{{end}}
+		<tr><td>Subtotal</td><td align="right">{{money .Subtotal}}</td></tr>
+		<tr><td>Tax</td><td align="right">{{money .Tax}}</td></tr>
+		<tr><td>Booking fee</td><td align="right">{{money .BookingFee}}</td></tr>
+		{{if .Discount}}<tr><td>Discount{{if .PromoCode}} ({{.PromoCode}}){{end}}</td><td align="right">-{{money .Discount}}</td></tr>{{end}}
+		{{if .Tip}}<tr><td>Tip</td><td align="right">{{money .Tip}}</td></tr>{{end}}
+		<tr><td><strong>Total</strong></td><td align="right"><strong>{{money .Total}}</strong></td></tr>
+		{{if .Wallet}}<tr><td>Paid with wallet</td><td align="right">{{money .Wallet}}</td></tr>{{end}}
+		{{if .Card}}<tr><td>Paid with {{if .PaymentMethod}}{{.PaymentMethod}}{{else}}card{{end}}</td><td align="right">{{money .Card}}</td></tr>{{end}}
+		{{range .Refunds}}<tr><td>Refund {{date .CreatedAt}}{{if .Note}} - {{.Note}}{{end}}</td><td align="right">-{{money .Amount}}</td></tr>{{end}}
+	</table>
+</div>`))
+
+// Money returns amount in cents as dollars
+func Money(cents int64) string {
+	return fmt.Sprintf("$%.2f", float64(cents)/100)
+}
+
+func receiptDate(t int64) string {
+	return time.Unix(t, 0).UTC().Format("Jan 2, 2006 15:04 MST")
+}
+
+// RenderHTML returns receipt as html fragment that is put in email body
+func RenderHTML(receipt *model.Receipt) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	err := receiptTemplate.Execute(buf, receipt)
+	return buf.Bytes(), err
+}
+
+// RenderPDF returns receipt as pdf file
+func RenderPDF(receipt *model.Receipt) []byte {
+	const left, right, bottom = 50.0, pdf.PageWidth - 50, 60.0
+	doc := pdf.New()
+	y := pdf.PageHeight - 60
+	line := func(size float64, bold bool, text string, amount string) {
+		if y < bottom {
+			doc.AddPage()
+			y = pdf.PageHeight - 60
+		}
+		doc.Text(left, y, size, bold, text)
+		if amount != "" {
+			doc.TextRight(right, y, size, bold, amount)
+		}
+		y -= size + 6
+	}
+	rule := func() {
+		doc.Line(left, y+4, right, y+4)
+		y -= 8
+	}
+
+	line(18, true, receipt.BusinessName, "")
+	if receipt.Identification != "" {
+		line(10, false, "Tax ID: "+receipt.Identification, "")
+	}
+	if receipt.BusinessAddr != "" {
+		line(10, false, receipt.BusinessAddr, "")
+	}
+	y -= 10
+	if receipt.InvoiceNumber != "" {
+		line(11, true, "Invoice "+receipt.InvoiceNumber, "")
+	}
+	line(11, false, "Order "+receipt.Number, receiptDate(receipt.IssuedAt))
+	line(11, false, receipt.CustomerName, "")
+	if receipt.DeliveryAddr != "" {
+		line(10, false, receipt.DeliveryAddr, "")
+	}
+	y -= 10
+	rule()
+	for _, item := range receipt.Items {
+		line(11, false, fmt.Sprintf("%d x %s", item.Count, item.Name), Money(item.Price))
+		if len(item.Options) > 0 {
+			line(9, false, "    "+strings.Join(item.Options, ", "), "")
+		}
+		if item.Note != "" {
+			line(9, false, "    "+item.Note, "")
+		}
+	}
+	rule()
+	line(11, false, "Subtotal", Money(receipt.Subtotal))
+	line(11, false, "Tax", Money(receipt.Tax))
+	line(11, false, "Booking fee", Money(receipt.BookingFee))
+	if receipt.Discount > 0 {
+		label := "Discount"
+		if receipt.PromoCode != "" {
+			label += " (" + receipt.PromoCode + ")"
+		}
+		line(11, false, label, "-"+Money(receipt.Discount))
+	}
+	if receipt.Tip > 0 {
+		line(11, false, "Tip", Money(receipt.Tip))
+	}
+	rule()
+	line(13, true, "Total", Money(receipt.Total))
+	if receipt.Wallet > 0 {
+		line(11, false, "Paid with wallet", Money(receipt.Wallet))
+	}
+	if receipt.Card > 0 {
+		method := receipt.PaymentMethod
+		if method == "" {
+			method = "card"
+		}
+		line(11, false, "Paid with "+method, Money(receipt.Card))
+	}
+	for _, refund := range receipt.Refunds {
+		label := "Refund " + receiptDate(refund.CreatedAt)
+		if refund.Note != "" {
+			label += " - " + refund.Note
+		}
+		line(11, false, label, "-"+Money(refund.Amount))
+	}
+	return doc.Bytes()
+}
@@ -0,0 +1,299 @@
+package referralService
+
+import (
+	"errors"
+	"strings"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../service/authService/userService"
+	"../../service/promotionService"
+	"../../service/walletService"
+	"../../util/random"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func referralCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("referral"), session
+}
+
+// InitService inits service
+func InitService() {
+	referralCollection, session := referralCollection()
+	defer session.Close()
+
+	// user is referred once
+	referralCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"refereeId"},
+		Unique: true,
+	})
+	referralCollection.EnsureIndex(mgo.Index{
+		Key:        []string{"referrerId", "status"},
+		Background: true,
+	})
+	// users registered before referral program get code
+	go userService.GeneratePromoCodes()
+}
+
+// ValidateReferralCode checks that referral code is existed
+func ValidateReferralCode(code string) error {
+	if code == "" {
+		return nil
+	}
+	if _, err := userService.ReadUserByPromoCode(normalizeCode(code)); err != nil {
+		return errors.New("Referral code is invalid")
+	}
+	return nil
+}
+
+// ApplyReferral records referral of registered user, suspicious referral is rejected
+func ApplyReferral(user *model.User) (*model.Referral, error) {
+	code := normalizeCode(user.ReferralCode)
+	if code == "" {
+		return nil, nil
+	}
+	referrer, err := userService.ReadUserByPromoCode(code)
+	if err != nil {
+		return nil, errors.New("Referral code is invalid")
+	}
+	referralCollection, session := referralCollection()
+	defer session.Close()
+
+	referral := &model.Referral{
+		ID:         bson.NewObjectId(),
+		Code:       code,
+		ReferrerID: referrer.ID,
+		RefereeID:  user.ID,
+		Status:     config.ReferralPending,
+		CreatedAt:  timeHelper.GetCurrentTime(),
+	}
+	if reason := checkFraud(referrer, user, referral.ID); reason != "" {
+		referral.Status = config.ReferralRejected
+		referral.RejectReason = reason
+	} else {
+		userService.UpdateUserReferral(user.ID, referrer.ID)
+	}
+
+	err = referralCollection.Insert(referral)
+	return referral, err
+}
+
+// checkFraud returns reason that referral of referee is rejected, phone and device of referee must not be used by other users.
+// Referral of id is not counted in referrals of referrer.
+func checkFraud(referrer *model.User, referee *model.User, referralID bson.ObjectId) string {
+	referralCollection, session := referralCollection()
+	defer session.Close()
+
+	phoneUsers, deviceUsers := 0, 0
+	if referee.Phone != "" {
+		phoneUsers = userService.CountUsers(bson.M{"_id": bson.M{"$ne": referee.ID}, "phoneCode": referee.PhoneCode, "phone": referee.Phone})
+	}
+	if referee.DeviceID != "" {
+		deviceUsers = userService.CountUsers(bson.M{"_id": bson.M{"$ne": referee.ID}, "deviceId": referee.DeviceID})
+	}
+	referred, _ := referralCollection.Find(bson.M{
+		"_id":        bson.M{"$ne": referralID},
+		"referrerId": referrer.ID,
+		"status":     bson.M{"$ne": config.ReferralRejected},
+	}).Count()
+	return FraudReason(referrer, referee, phoneUsers, deviceUsers, referred)
+}
+
+// FraudReason returns reason that referral is rejected, it is empty for valid referral
+func FraudReason(referrer *model.User, referee *model.User, phoneUsers int, deviceUsers int, referred int) string {
+	switch {
+	case referrer.ID == referee.ID,
+		referrer.Email != "" && strings.EqualFold(referrer.Email, referee.Email),
+		referrer.Phone != "" && referrer.PhoneCode == referee.PhoneCode && referrer.Phone == referee.Phone,
+		referrer.DeviceID != "" && referrer.DeviceID == referee.DeviceID:
+		return "Self referral"
+	case phoneUsers > 0:
+		return "Phone is used by other user"
+	case deviceUsers > 0:
+		return "Device is used by other user"
+	case referred >= config.ReferralLimit:
+		return "Referrer reached limit of referrals"
+	}
+	return ""
+}
+
+// CompleteReferral rewards referrer and referee when referee completes first order,
+// referral is checked again with verified phone of referee before reward
+func CompleteReferral(refereeID bson.ObjectId, orderID bson.ObjectId) (*model.Referral, error) {
+	referralCollection, session := referralCollection()
+	defer session.Close()
+
+	referral := &model.Referral{}
+	if err := referralCollection.Find(bson.M{"refereeId": refereeID, "status": config.ReferralPending}).One(referral); err != nil {
+		return nil, err
+	}
+	referee, err := userService.ReadUser(refereeID)
+	if err != nil {
+		return nil, err
+	}
+	// referral waits until phone of referee is verified
+	if referee.Verify == nil || !referee.Verify.IsVerified || referee.Phone == "" {
+		return nil, errors.New("Phone of referee is not verified")
+	}
+	referrer, err := userService.ReadUser(referral.ReferrerID)
+	if err != nil {
+		return nil, err
+	}
+	if reason := checkFraud(referrer, referee, referral.ID); reason != "" {
+		referralCollection.Update(bson.M{"_id": referral.ID, "status": config.ReferralPending}, bson.M{"$set": bson.M{
+			"status":       config.ReferralRejected,
+			"rejectReason": reason,
+		}})
+		return nil, errors.New(reason)
+	}
+
+	change := mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"status":     config.ReferralRewarded,
+			"orderId":    orderID,
+			"rewardType": config.ReferralRewardType,
+			"rewardedAt": timeHelper.GetCurrentTime(),
+		}},
+		ReturnNew: true,
+	}
+	if _, err := referralCollection.Find(bson.M{
+		"_id":    referral.ID,
+		"status": config.ReferralPending,
+	}).Apply(change, referral); err != nil {
+		return nil, err
+	}
+
+	referral.RefereeReward = config.RefereeReward
+	// referrer is rewarded up to limit
+	if rewarded, _ := referralCollection.Find(bson.M{
+		"referrerId": referral.ReferrerID,
+		"status":     config.ReferralRewarded,
+	}).Count(); rewarded <= config.ReferralLimit {
+		referral.ReferrerReward = config.ReferrerReward
+	}
+	if err := reward(referral, referral.ReferrerID, referral.ReferrerReward, "referrer"); err != nil {
+		referral.ReferrerReward = 0
+	}
+	if err := reward(referral, referral.RefereeID, referral.RefereeReward, "referee"); err != nil {
+		referral.RefereeReward = 0
+	}
+	err = referralCollection.UpdateId(referral.ID, bson.M{"$set": bson.M{
+		"referrerReward": referral.ReferrerReward,
+		"refereeReward":  referral.RefereeReward,
+	}})
+	return referral, err
+}
+
+// reward gives wallet credit or personal promotion to user
+func reward(referral *model.Referral, userID bson.ObjectId, amount int64, party string) error {
+	if amount <= 0 {
+		return nil
+	}
+	expiresAt := timeHelper.FewDaysLater(config.ReferralRewardDays).Unix()
+	if config.ReferralRewardType == config.ReferralRewardPromotion {
+		_, err := promotionService.CreatePromotion(&model.Promotion{
+			Code:         "REF" + random.GenerateRandomString(8),
+			Name:         "Referral reward",
+			Type:         config.PromotionFixed,
+			Value:        float64(amount) / 100,
+			PerUserLimit: 1,
+			TotalLimit:   1,
+			StartAt:      timeHelper.GetCurrentTime(),
+			EndAt:        expiresAt,
+			Status:       true,
+			UserID:       userID,
+		})
+		return err
+	}
+	_, err := walletService.Credit(&model.WalletTransaction{
+		UserID:    userID,
+		Source:    config.WalletReferral,
+		Amount:    amount,
+		ExpiresAt: expiresAt,
+		Key:       "referral:" + referral.ID.Hex() + ":" + party,
+		Note:      "Referral reward",
+	})
+	return err
+}
+
+// ReadUserReferrals returns referrals of referrer
+func ReadUserReferrals(referrerID bson.ObjectId) ([]*model.Referral, error) {
+	referralCollection, session := referralCollection()
+	defer session.Close()
+
+	referrals := []*model.Referral{}
+	err := referralCollection.Find(bson.M{"referrerId": referrerID}).Sort("-createdAt").All(&referrals)
+	return referrals, err
+}
+
+// ReadReferrals returns referrals with status
+func ReadReferrals(status string, offset int, count int) ([]*model.Referral, int, error) {
+	referralCollection, session := referralCollection()
+	defer session.Close()
+
+	query := bson.M{}
+	if status != "" {
+		query["status"] = status
+	}
+	totalCount, _ := referralCollection.Find(query).Count()
+	referrals := []*model.Referral{}
+	err := referralCollection.Find(query).Sort("-createdAt").Skip(offset).Limit(count).All(&referrals)
+	return referrals, totalCount, err
+}
+
+// ReadReferralReport returns referral results of referrers in order of rewarded referrals
+func ReadReferralReport(from int64, to int64, offset int, count int) ([]*model.ReferralReport, int, error) {
+	referralCollection, session := referralCollection()
+	defer session.Close()
+
+	match := bson.M{}
+	if from > 0 || to > 0 {
+		createdAt := bson.M{}
+		if from > 0 {
+			createdAt["$gte"] = from
+		}
+		if to > 0 {
+			createdAt["$lt"] = to
+		}
+		match["createdAt"] = createdAt
+	}
+	pipe := []bson.M{
+		{"$match": match},
+		{"$group": bson.M{
+			"_id":      "$referrerId",
+			"pending":  bson.M{"$sum": bson.M{"$cond": []interface{}{bson.M{"$eq": []interface{}{"$status", config.ReferralPending}}, 1, 0}}},
+			"rewarded": bson.M{"$sum": bson.M{"$cond": []interface{}{bson.M{"$eq": []interface{}{"$status", config.ReferralRewarded}}, 1, 0}}},
+			"rejected": bson.M{"$sum": bson.M{"$cond": []interface{}{bson.M{"$eq": []interface{}{"$status", config.ReferralRejected}}, 1, 0}}},
+			"reward":   bson.M{"$sum": "$referrerReward"},
+		}},
+	}
+	// get total count of collection with initial query
+	totalCount := db.GetCountOfCollection(referralCollection, &pipe)
+
+	pipe = append(pipe, bson.M{"$sort": bson.M{"rewarded": -1, "_id": 1}})
+	// add page feature
+	if offset == 0 && count == 0 {
+	} else {
+		pipe = append(pipe, bson.M{"$skip": offset})
+		pipe = append(pipe, bson.M{"$limit": count})
+	}
+	reports := []*model.ReferralReport{}
+	if err := referralCollection.Pipe(pipe).All(&reports); err != nil {
+		return nil, 0, err
+	}
+	for _, report := range reports {
+		if user, err := userService.ReadUser(report.ReferrerID); err == nil {
+			report.Referrer = &model.PublicUser{User: user}
+		}
+	}
+	return reports, totalCount, nil
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
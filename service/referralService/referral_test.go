@@ -0,0 +1,40 @@
+package referralService
+
+import (
+	"testing"
+
+	"../../config"
+	"../../model"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestFraudReason(t *testing.T) {
+	referrer := &model.User{ID: bson.NewObjectId(), Email: "a@gogo.com", PhoneCode: "1", Phone: "555", DeviceID: "device-a"}
+	referee := func() *model.User {
+		return &model.User{ID: bson.NewObjectId(), Email: "b@gogo.com", PhoneCode: "1", Phone: "666", DeviceID: "device-b"}
+	}
+
+	if reason := FraudReason(referrer, referee(), 0, 0, 0); reason != "" {
+		t.Errorf("valid referral is rejected: %s", reason)
+	}
+	self := referee()
+	self.Email = "A@gogo.com"
+	if reason := FraudReason(referrer, self, 0, 0, 0); reason != "Self referral" {
+		t.Errorf("same email is %q", reason)
+	}
+	self = referee()
+	self.DeviceID = referrer.DeviceID
+	if reason := FraudReason(referrer, self, 0, 0, 0); reason != "Self referral" {
+		t.Errorf("same device is %q", reason)
+	}
+	if reason := FraudReason(referrer, referee(), 1, 0, 0); reason == "" {
+		t.Error("reused phone is not rejected")
+	}
+	if reason := FraudReason(referrer, referee(), 0, 1, 0); reason == "" {
+		t.Error("reused device is not rejected")
+	}
+	if reason := FraudReason(referrer, referee(), 0, 0, config.ReferralLimit); reason == "" {
+		t.Error("referral over limit is not rejected")
+	}
+}
@@ -0,0 +1,244 @@
+package reviewService
+
+import (
+	"errors"
+	"time"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../util/moderation"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func reviewCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("review"), session
+}
+
+// InitService inits service
+func InitService() {
+	reviewCollection, session := reviewCollection()
+	defer session.Close()
+
+	// one review for each order
+	reviewCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"orderId"},
+		Unique: true,
+	})
+	reviewCollection.EnsureIndex(mgo.Index{
+		Key:        []string{"businessId", "status", "-createdAt"},
+		Background: true,
+	})
+}
+
+// CreateReview creates review with feedback of user about business
+func CreateReview(order *model.Order, user *model.User) (*model.Review, error) {
+	reviewCollection, session := reviewCollection()
+	defer session.Close()
+
+	review := &model.Review{
+		ID:         bson.NewObjectId(),
+		OrderID:    order.ID,
+		BusinessID: order.BusinessID,
+		UserID:     order.UserID,
+		Rate:       order.BusinessRate,
+		Content:    order.BusinessFeedback,
+		CreatedAt:  timeHelper.GetCurrentTime(),
+		UpdatedAt:  timeHelper.GetCurrentTime(),
+	}
+	if user != nil {
+		review.UserName = user.Firstname
+		if len(user.Lastname) > 0 {
+			review.UserName += " " + user.Lastname[:1] + "."
+		}
+		review.UserAvatar = user.Avatar
+	}
+	review.EditableUntil = timeHelper.FewDurationLater(config.ReviewEditHours * time.Hour).Unix()
+	review.Status, review.FilterReasons = filterContent(review.Content)
+
+	err := reviewCollection.Insert(review)
+	return review, err
+}
+
+// ReadReview returns review with object id
+func ReadReview(objid bson.ObjectId) (*model.Review, error) {
+	reviewCollection, session := reviewCollection()
+	defer session.Close()
+
+	review := &model.Review{}
+	err := reviewCollection.FindId(objid).One(review)
+	return review, err
+}
+
+// UpdateReviewContent updates content of review by customer in grace period
+func UpdateReviewContent(objid bson.ObjectId, userID bson.ObjectId, content string) (*model.Review, error) {
+	reviewCollection, session := reviewCollection()
+	defer session.Close()
+
+	status, reasons := filterContent(content)
+	set := bson.M{
+		"content":       content,
+		"filterReasons": reasons,
+		"updatedAt":     timeHelper.GetCurrentTime(),
+	}
+	// filter only holds review, review that is pending for flags or content is kept for moderator
+	if status == config.ReviewPending {
+		set["status"] = status
+	}
+	change := mgo.Change{
+		Update:    bson.M{"$set": set},
+		ReturnNew: true,
+	}
+	review := &model.Review{}
+	if _, err := reviewCollection.Find(bson.M{
+		"_id":           objid,
+		"userId":        userID,
+		"status":        bson.M{"$ne": config.ReviewRejected},
+		"editableUntil": bson.M{"$gte": timeHelper.GetCurrentTime()},
+	}).Apply(change, review); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, errors.New("This review can't be edited")
+		}
+		return nil, err
+	}
+	if review.FlagCount >= config.ReviewFlagLimit && review.Status == config.ReviewPublished {
+		reviewCollection.Update(bson.M{"_id": objid, "status": config.ReviewPublished},
+			bson.M{"$set": bson.M{"status": config.ReviewPending}})
+		review.Status = config.ReviewPending
+	}
+	return review, nil
+}
+
+// ReplyReview saves reply of business to review
+func ReplyReview(objid bson.ObjectId, businessID bson.ObjectId, content string) (*model.Review, error) {
+	if content == "" {
+		return nil, errors.New("Reply is empty")
+	}
+	reviewCollection, session := reviewCollection()
+	defer session.Close()
+
+	review := &model.Review{}
+	if err := reviewCollection.Find(bson.M{"_id": objid, "businessId": businessID}).One(review); err != nil {
+		return nil, errors.New("This review is not for your business")
+	}
+	reply := review.Reply
+	if reply == nil {
+		reply = &model.ReviewReply{CreatedAt: timeHelper.GetCurrentTime()}
+	}
+	reply.Content = content
+	reply.UpdatedAt = timeHelper.GetCurrentTime()
+
+	change := mgo.Change{
+		Update:    bson.M{"$set": bson.M{"reply": reply, "updatedAt": timeHelper.GetCurrentTime()}},
+		ReturnNew: true,
+	}
+	_, err := reviewCollection.FindId(objid).Apply(change, review)
+	return review, err
+}
+
+// FlagReview reports review, review is held for moderation when flags reach limit
+func FlagReview(objid bson.ObjectId, flag *model.ReviewFlag) (*model.Review, error) {
+	reviewCollection, session := reviewCollection()
+	defer session.Close()
+
+	flag.CreatedAt = timeHelper.GetCurrentTime()
+	change := mgo.Change{
+		Update: bson.M{
+			"$push": bson.M{"flags": flag},
+			"$inc":  bson.M{"flagCount": 1},
+		},
+		ReturnNew: true,
+	}
+	review := &model.Review{}
+	// client can flag review once
+	if _, err := reviewCollection.Find(bson.M{
+		"_id":            objid,
+		"flags.clientId": bson.M{"$ne": flag.ClientID},
+	}).Apply(change, review); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, errors.New("This review is flagged already")
+		}
+		return nil, err
+	}
+	if review.FlagCount >= config.ReviewFlagLimit && review.Status == config.ReviewPublished {
+		reviewCollection.Update(bson.M{"_id": objid, "status": config.ReviewPublished},
+			bson.M{"$set": bson.M{"status": config.ReviewPending}})
+		review.Status = config.ReviewPending
+	}
+	return review, nil
+}
+
+// ModerateReview publishes or rejects pending review
+func ModerateReview(objid bson.ObjectId, moderatorID bson.ObjectId, status string, comment string) (*model.Review, error) {
+	if status != config.ReviewPublished && status != config.ReviewRejected {
+		return nil, errors.New("Status of review is invalid")
+	}
+	reviewCollection, session := reviewCollection()
+	defer session.Close()
+
+	set := bson.M{
+		"status":            status,
+		"moderatorId":       moderatorID,
+		"moderationComment": comment,
+		"moderatedAt":       timeHelper.GetCurrentTime(),
+		"updatedAt":         timeHelper.GetCurrentTime(),
+	}
+	if status == config.ReviewPublished {
+		// flags are resolved by moderator
+		set["flagCount"] = 0
+	}
+	review := &model.Review{}
+	if _, err := reviewCollection.Find(bson.M{
+		"_id":    objid,
+		"status": config.ReviewPending,
+	}).Apply(mgo.Change{Update: bson.M{"$set": set}, ReturnNew: true}, review); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, errors.New("This review is moderated already")
+		}
+		return nil, err
+	}
+	return review, nil
+}
+
+// ReadBusinessReviews returns published reviews of business
+func ReadBusinessReviews(businessID bson.ObjectId, offset int, count int) ([]*model.Review, int, error) {
+	return readReviews(bson.M{"businessId": businessID, "status": config.ReviewPublished}, -1, offset, count)
+}
+
+// ReadModerationQueue returns reviews that are waiting for moderation
+func ReadModerationQueue(offset int, count int) ([]*model.Review, int, error) {
+	return readReviews(bson.M{"status": config.ReviewPending}, 1, offset, count)
+}
+
+func readReviews(match bson.M, sort int, offset int, count int) ([]*model.Review, int, error) {
+	reviewCollection, session := reviewCollection()
+	defer session.Close()
+
+	pipe := []bson.M{{"$match": match}}
+	// get total count of collection with initial query
+	totalCount := db.GetCountOfCollection(reviewCollection, &pipe)
+
+	pipe = append(pipe, bson.M{"$sort": bson.M{"createdAt": sort}})
+	// add page feature
+	if offset == 0 && count == 0 {
+	} else {
+		pipe = append(pipe, bson.M{"$skip": offset})
+		pipe = append(pipe, bson.M{"$limit": count})
+	}
+	reviews := []*model.Review{}
+	err := reviewCollection.Pipe(pipe).All(&reviews)
+	return reviews, totalCount, err
+}
+
+// filterContent holds suspicious review for manual moderation
+func filterContent(content string) (string, []string) {
+	reasons := moderation.Check(content)
+	if len(reasons) > 0 {
+		return config.ReviewPending, reasons
+	}
+	return config.ReviewPublished, nil
+}
@@ -0,0 +1,262 @@
+package searchService
+
+import (
+	"log"
+	"regexp"
+	"sort"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../zoneService"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	distanceDecay = 5000 // relevance is halved at this distance
+	maxHits       = 200
+	maxFoods      = 5 // matched dishes that are shown under restaurant
+	maxSuggests   = 5
+	codeScore     = 0.5 // relevance of dietary or meal kind match
+)
+
+// textHit is a document that is found with text index
+type textHit struct {
+	ID    bson.ObjectId `bson:"_id"`
+	Code  int           `bson:"code"`
+	Score float64       `bson:"score"`
+}
+
+// foodHit is a food that is found with text index
+type foodHit struct {
+	model.Food `bson:",inline"`
+	Score      float64 `bson:"score"`
+}
+
+// InitService creates text indexes for search
+func InitService() {
+	mgoDB, session := db.MongoDB()
+	defer session.Close()
+
+	indexes := map[string]mgo.Index{
+		"business": {
+			Key:        []string{"$text:name", "$text:description"},
+			Weights:    map[string]int{"name": 10, "description": 2},
+			Background: true,
+		},
+		"food": {
+			Key:        []string{"$text:name", "$text:description"},
+			Weights:    map[string]int{"name": 10, "description": 2},
+			Background: true,
+		},
+		"dietary": {
+			Key:        []string{"$text:name"},
+			Background: true,
+		},
+		"meal_kind": {
+			Key:        []string{"$text:name"},
+			Background: true,
+		},
+	}
+	for name, index := range indexes {
+		if err := mgoDB.C(name).EnsureIndex(index); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// SearchBusinesses returns businesses in radius that match query and deliver to customer with their matched dishes
+func SearchBusinesses(queryBusiness *model.QueryBusiness, radius float64) ([]*model.PublicBusiness, int, error) {
+	mgoDB, session := db.MongoDB()
+	defer session.Close()
+
+	text := bson.M{"$text": bson.M{"$search": queryBusiness.Query}}
+	score := bson.M{"score": bson.M{"$meta": "textScore"}}
+
+	// $text can't be used with $geoNear in same pipeline, so text hits are merged first
+	businessHits := []*textHit{}
+	if err := mgoDB.C("business").Find(text).Select(score).Sort("$textScore:score").Limit(maxHits).All(&businessHits); err != nil {
+		return nil, 0, err
+	}
+	foodHits := []*foodHit{}
+	if err := mgoDB.C("food").Find(bson.M{
+		"$text":   text["$text"],
+		"status":  true,
+		"enabled": true,
+	}).Select(score).Sort("$textScore:score").Limit(maxHits).All(&foodHits); err != nil {
+		return nil, 0, err
+	}
+	dietaryHits := []*textHit{}
+	mgoDB.C("dietary").Find(text).Select(score).All(&dietaryHits)
+	mealKindHits := []*textHit{}
+	mgoDB.C("meal_kind").Find(text).Select(score).All(&mealKindHits)
+
+	scores := map[bson.ObjectId]float64{}
+	ids := []bson.ObjectId{}
+	for _, hit := range businessHits {
+		scores[hit.ID] = hit.Score
+		ids = append(ids, hit.ID)
+	}
+	foods := map[bson.ObjectId][]*model.Food{}
+	for _, hit := range foodHits {
+		food := hit.Food
+		if _, ok := foods[food.BusinessID]; !ok {
+			// best dish decides relevance of restaurant, hits are sorted by score
+			scores[food.BusinessID] += hit.Score
+			ids = append(ids, food.BusinessID)
+		}
+		if len(foods[food.BusinessID]) < maxFoods {
+			foods[food.BusinessID] = append(foods[food.BusinessID], &food)
+		}
+	}
+	dietaryCodes := []int{}
+	for _, hit := range dietaryHits {
+		dietaryCodes = append(dietaryCodes, hit.Code)
+	}
+	mealKindCodes := []int{}
+	for _, hit := range mealKindHits {
+		mealKindCodes = append(mealKindCodes, hit.Code)
+	}
+
+	or := []bson.M{{"_id": bson.M{"$in": ids}}}
+	if len(dietaryCodes) > 0 {
+		or = append(or, bson.M{"dietaryCodes": bson.M{"$in": dietaryCodes}})
+	}
+	if len(mealKindCodes) > 0 {
+		or = append(or, bson.M{"mealKindCodes": bson.M{"$in": mealKindCodes}})
+	}
+	// closed business is not searched
+	query := bson.M{"$or": or, "closed": bson.M{"$ne": true}}
+	if len(queryBusiness.Price) > 0 {
+		query["priceLevel"] = bson.M{"$in": queryBusiness.Price}
+	}
+	if len(queryBusiness.Dietary) > 0 {
+		query["dietaryCodes"] = bson.M{"$in": queryBusiness.Dietary}
+	}
+
+	businesses := []*model.PublicBusiness{}
+	if err := mgoDB.C("business").Pipe([]bson.M{
+		{"$geoNear": bson.M{
+			"near":          bson.M{"type": "Point", "coordinates": []float64{queryBusiness.Lng, queryBusiness.Lat}},
+			"distanceField": "distance",
+			"maxDistance":   radius,
+			"query":         query,
+			"includeLocs":   "geoLocation.geoJson",
+			"num":           maxHits,
+			"spherical":     true,
+		}},
+	}).All(&businesses); err != nil {
+		return nil, 0, err
+	}
+	businesses = zoneService.FilterDeliverable(businesses, []float64{queryBusiness.Lng, queryBusiness.Lat})
+
+	for _, b := range businesses {
+		b.Score = scores[b.ID]
+		if containsAny(b.DietaryCodes, dietaryCodes) {
+			b.Score += codeScore
+		}
+		if containsAny(b.MealKindCodes, mealKindCodes) {
+			b.Score += codeScore
+		}
+		b.MatchedFoods = foods[b.ID]
+	}
+	rankBusinesses(businesses)
+	if queryBusiness.Sort == config.SortRating {
+		sort.SliceStable(businesses, func(i, j int) bool {
+			return businesses[i].Rating > businesses[j].Rating
+		})
+	}
+
+	// add page feature
+	total := len(businesses)
+	if queryBusiness.Offset == 0 && queryBusiness.Count == 0 {
+	} else {
+		businesses = paginate(businesses, queryBusiness.Offset, queryBusiness.Count)
+	}
+	return businesses, total, nil
+}
+
+// rankBusinesses sorts businesses with relevance that decays with distance
+func rankBusinesses(businesses []*model.PublicBusiness) {
+	for _, b := range businesses {
+		b.Score = b.Score / (1 + b.Distance/distanceDecay)
+	}
+	sort.SliceStable(businesses, func(i, j int) bool {
+		if businesses[i].Score != businesses[j].Score {
+			return businesses[i].Score > businesses[j].Score
+		}
+		return businesses[i].Distance < businesses[j].Distance
+	})
+}
+
+func paginate(businesses []*model.PublicBusiness, offset int, count int) []*model.PublicBusiness {
+	// page of form can be negative
+	if offset < 0 {
+		offset = 0
+	}
+	if count < 0 {
+		count = 0
+	}
+	if offset >= len(businesses) {
+		return []*model.PublicBusiness{}
+	}
+	end := offset + count
+	if end > len(businesses) {
+		end = len(businesses)
+	}
+	return businesses[offset:end]
+}
+
+func containsAny(codes []int, targets []int) bool {
+	for _, code := range codes {
+		for _, target := range targets {
+			if code == target {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReadSuggestions returns autocomplete items that start with query
+func ReadSuggestions(query string) ([]*model.Suggestion, error) {
+	mgoDB, session := db.MongoDB()
+	defer session.Close()
+
+	prefix := bson.RegEx{Pattern: "^" + regexp.QuoteMeta(query), Options: "i"}
+	sources := []struct {
+		collection string
+		kind       string
+		filter     bson.M
+	}{
+		{"business", config.SuggestBusiness, bson.M{}},
+		{"food", config.SuggestFood, bson.M{"status": true, "enabled": true}},
+		{"dietary", config.SuggestDietary, bson.M{}},
+		{"meal_kind", config.SuggestMealKind, bson.M{}},
+	}
+
+	suggestions := []*model.Suggestion{}
+	for _, source := range sources {
+		source.filter["name"] = prefix
+		items := []struct {
+			ID   bson.ObjectId `bson:"_id"`
+			Code int           `bson:"code"`
+			Name string        `bson:"name"`
+		}{}
+		if err := mgoDB.C(source.collection).Find(source.filter).
+			Select(bson.M{"name": 1, "code": 1}).Sort("name").Limit(maxSuggests).All(&items); err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			suggestions = append(suggestions, &model.Suggestion{
+				Type: source.kind,
+				ID:   item.ID,
+				Code: item.Code,
+				Text: item.Name,
+			})
+		}
+	}
+	return suggestions, nil
+}
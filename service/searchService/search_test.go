@@ -0,0 +1,39 @@
+package searchService
+
+import (
+	"testing"
+
+	"../../model"
+)
+
+func TestRankBusinesses(t *testing.T) {
+	businesses := []*model.PublicBusiness{
+		{Name: "far", Score: 10, Distance: 9000},
+		{Name: "near", Score: 10, Distance: 500},
+		{Name: "weak", Score: 1, Distance: 100},
+	}
+	rankBusinesses(businesses)
+
+	expected := []string{"near", "far", "weak"}
+	for i, b := range businesses {
+		if b.Name != expected[i] {
+			t.Errorf("rank %d: expected %s, got %s", i, expected[i], b.Name)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	businesses := []*model.PublicBusiness{{}, {}, {}}
+	if n := len(paginate(businesses, 1, 5)); n != 2 {
+		t.Errorf("expected 2 businesses, got %d", n)
+	}
+	if n := len(paginate(businesses, 5, 5)); n != 0 {
+		t.Errorf("expected no business, got %d", n)
+	}
+	if n := len(paginate(businesses, -2, 2)); n != 2 {
+		t.Errorf("expected 2 businesses with negative offset, got %d", n)
+	}
+	if n := len(paginate(businesses, 1, -1)); n != 0 {
+		t.Errorf("expected no business with negative count, got %d", n)
+	}
+}
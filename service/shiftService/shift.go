@@ -0,0 +1,427 @@
+package shiftService
+
+import (
+	"errors"
+	"log"
+	"math"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../service/authService/driverService"
+	"../../service/orderService"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const weekSeconds = 7 * 24 * 3600
+
+func slotCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("shift_slot"), session
+}
+
+func bookingCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("shift_booking"), session
+}
+
+// InitService inits service
+func InitService() {
+	slotCollection, session := slotCollection()
+	defer session.Close()
+
+	slotCollection.EnsureIndex(mgo.Index{
+		Key:        []string{"placeId", "start"},
+		Background: true,
+	})
+
+	bookingCollection, bookingSession := bookingCollection()
+	defer bookingSession.Close()
+
+	bookingCollection.EnsureIndex(mgo.Index{
+		Key:        []string{"driverId", "start"},
+		Background: true,
+	})
+	bookingCollection.EnsureIndex(mgo.Index{
+		Key:        []string{"slotId", "status"},
+		Background: true,
+	})
+	// driver books slot once, mgo doesn't support partial index, so index command is run
+	if err := bookingCollection.Database.Run(bson.D{
+		{Name: "createIndexes", Value: bookingCollection.Name},
+		{Name: "indexes", Value: []bson.M{{
+			"key":                     bson.D{{Name: "slotId", Value: 1}, {Name: "driverId", Value: 1}},
+			"name":                    "slotId_1_driverId_1_booked",
+			"unique":                  true,
+			"partialFilterExpression": bson.M{"status": config.ShiftBooked},
+			"background":              true,
+		}}},
+	}, nil); err != nil {
+		log.Println(err)
+	}
+}
+
+// ValidSlot checks time, capacity and zone of slot
+func ValidSlot(slot *model.ShiftSlot) error {
+	if slot.PlaceID == "" || slot.Zone == "" {
+		return errors.New("City and zone of shift are required")
+	}
+	if slot.End <= slot.Start {
+		return errors.New("End of shift must be after start")
+	}
+	if slot.Capacity <= 0 {
+		return errors.New("Capacity of shift must be positive")
+	}
+	if slot.Center != nil && (len(slot.Center.Coordinates) != 2 || slot.Radius <= 0) {
+		return errors.New("Center and radius of zone are invalid")
+	}
+	return nil
+}
+
+// CreateSlot publishes shift slot
+func CreateSlot(slot *model.ShiftSlot) (*model.ShiftSlot, error) {
+	if err := ValidSlot(slot); err != nil {
+		return nil, err
+	}
+	slotCollection, session := slotCollection()
+	defer session.Close()
+
+	if slot.Center != nil {
+		slot.Center.Type = "Point"
+	}
+	slot.ID = bson.NewObjectId()
+	slot.Booked = 0
+	slot.CreatedAt = timeHelper.GetCurrentTime()
+	slot.UpdatedAt = slot.CreatedAt
+	err := slotCollection.Insert(slot)
+	return slot, err
+}
+
+// UpdateSlot updates capacity and forecast of slot, time and zone are fixed after drivers booked
+func UpdateSlot(objid bson.ObjectId, slot *model.ShiftSlot) (*model.ShiftSlot, error) {
+	old, err := ReadSlot(objid)
+	if err != nil {
+		return nil, err
+	}
+	if slot.Capacity < old.Booked {
+		return nil, errors.New("Capacity is less than booked drivers")
+	}
+	if old.Booked > 0 && (slot.Start != old.Start || slot.End != old.End || slot.PlaceID != old.PlaceID || slot.Zone != old.Zone) {
+		return nil, errors.New("Time and zone of booked shift can't be changed")
+	}
+	if err := ValidSlot(slot); err != nil {
+		return nil, err
+	}
+	slotCollection, session := slotCollection()
+	defer session.Close()
+
+	if slot.Center != nil {
+		slot.Center.Type = "Point"
+	}
+	change := mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"placeId":   slot.PlaceID,
+			"zone":      slot.Zone,
+			"center":    slot.Center,
+			"radius":    slot.Radius,
+			"start":     slot.Start,
+			"end":       slot.End,
+			"capacity":  slot.Capacity,
+			"forecast":  slot.Forecast,
+			"updatedAt": timeHelper.GetCurrentTime(),
+		}},
+		ReturnNew: true,
+	}
+	// capacity is checked again in case that drivers booked meanwhile
+	_, err = slotCollection.Find(bson.M{"_id": objid, "booked": bson.M{"$lte": slot.Capacity}}).Apply(change, slot)
+	return slot, err
+}
+
+// DeleteSlot deletes slot that no driver booked
+func DeleteSlot(objid bson.ObjectId) error {
+	slotCollection, session := slotCollection()
+	defer session.Close()
+
+	err := slotCollection.Remove(bson.M{"_id": objid, "booked": 0})
+	if err == mgo.ErrNotFound {
+		return errors.New("Shift is booked or not found")
+	}
+	return err
+}
+
+// ReadSlot reads slot with object id
+func ReadSlot(objid bson.ObjectId) (*model.ShiftSlot, error) {
+	slotCollection, session := slotCollection()
+	defer session.Close()
+
+	slot := &model.ShiftSlot{}
+	err := slotCollection.FindId(objid).One(slot)
+	return slot, err
+}
+
+// ReadSlots reads slots of city that end in period, only slots with free capacity when available is true
+func ReadSlots(placeID string, from int64, to int64, available bool) ([]*model.ShiftSlot, error) {
+	slotCollection, session := slotCollection()
+	defer session.Close()
+
+	query := bson.M{"end": bson.M{"$gt": from}}
+	if to > 0 {
+		query["start"] = bson.M{"$lt": to}
+	}
+	if placeID != "" {
+		query["placeId"] = placeID
+	}
+	if available {
+		query["$expr"] = bson.M{"$lt": []interface{}{"$booked", "$capacity"}}
+	}
+	slots := []*model.ShiftSlot{}
+	err := slotCollection.Find(query).Sort("start", "zone").All(&slots)
+	return slots, err
+}
+
+// BookShift books slot for driver that is approved, capacity is taken atomically
+func BookShift(slotID bson.ObjectId, driverID bson.ObjectId) (*model.ShiftBooking, error) {
+	slot, err := ReadSlot(slotID)
+	if err != nil {
+		return nil, err
+	}
+	now := timeHelper.GetCurrentTime()
+	if slot.Start <= now {
+		return nil, errors.New("Shift is already started")
+	}
+	driver, err := driverService.ReadDriver(driverID)
+	if err != nil {
+		return nil, err
+	}
+	if driver.Onboarding != config.OnboardingApproved {
+		return nil, errors.New("Driver is not approved to book shifts")
+	}
+
+	bookingCollection, session := bookingCollection()
+	defer session.Close()
+
+	noShows, _ := bookingCollection.Find(bson.M{
+		"driverId": driverID,
+		"status":   config.ShiftNoShow,
+		"start":    bson.M{"$gte": now - config.ShiftNoShowPeriod},
+	}).Count()
+	if noShows >= config.ShiftNoShowLimit {
+		return nil, errors.New("Driver missed too many shifts recently")
+	}
+	overlaps, _ := bookingCollection.Find(bson.M{
+		"driverId": driverID,
+		"status":   config.ShiftBooked,
+		"start":    bson.M{"$lt": slot.End},
+		"end":      bson.M{"$gt": slot.Start},
+	}).Count()
+	if overlaps > 0 {
+		return nil, errors.New("Driver booked other shift in same time")
+	}
+
+	slotCollection, slotSession := slotCollection()
+	defer slotSession.Close()
+
+	// capacity is compared with stored value in case that admin changed it after read
+	err = slotCollection.Update(
+		bson.M{"_id": slotID, "$expr": bson.M{"$lt": []interface{}{"$booked", "$capacity"}}},
+		bson.M{"$inc": bson.M{"booked": 1}})
+	if err == mgo.ErrNotFound {
+		return nil, errors.New("Shift is full")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	booking := &model.ShiftBooking{
+		ID:        bson.NewObjectId(),
+		SlotID:    slotID,
+		DriverID:  driverID,
+		PlaceID:   slot.PlaceID,
+		Zone:      slot.Zone,
+		Start:     slot.Start,
+		End:       slot.End,
+		Status:    config.ShiftBooked,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := bookingCollection.Insert(booking); err != nil {
+		slotCollection.UpdateId(slotID, bson.M{"$inc": bson.M{"booked": -1}})
+		if mgo.IsDup(err) {
+			return nil, errors.New("Driver already booked this shift")
+		}
+		return nil, err
+	}
+	return booking, nil
+}
+
+// CancelBooking cancels booking of driver before shift starts and frees capacity
+func CancelBooking(bookingID bson.ObjectId, driverID bson.ObjectId) (*model.ShiftBooking, error) {
+	bookingCollection, session := bookingCollection()
+	defer session.Close()
+
+	now := timeHelper.GetCurrentTime()
+	booking := &model.ShiftBooking{}
+	change := mgo.Change{
+		Update:    bson.M{"$set": bson.M{"status": config.ShiftCancelled, "updatedAt": now}},
+		ReturnNew: true,
+	}
+	_, err := bookingCollection.Find(bson.M{
+		"_id":      bookingID,
+		"driverId": driverID,
+		"status":   config.ShiftBooked,
+		"start":    bson.M{"$gt": now},
+	}).Apply(change, booking)
+	if err == mgo.ErrNotFound {
+		return nil, errors.New("Booking is not found or shift is already started")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	slotCollection, slotSession := slotCollection()
+	defer slotSession.Close()
+
+	err = slotCollection.UpdateId(booking.SlotID, bson.M{"$inc": bson.M{"booked": -1}})
+	return booking, err
+}
+
+// ReadBookings reads bookings of driver, latest shift first
+func ReadBookings(driverID bson.ObjectId, status string, offset int, count int) ([]*model.ShiftBooking, int, error) {
+	bookingCollection, session := bookingCollection()
+	defer session.Close()
+
+	query := bson.M{"driverId": driverID}
+	if status != "" {
+		query["status"] = status
+	}
+	totalCount, _ := bookingCollection.Find(query).Count()
+	bookings := []*model.ShiftBooking{}
+	err := bookingCollection.Find(query).Sort("-start").Skip(offset).Limit(count).All(&bookings)
+	return bookings, totalCount, err
+}
+
+// CheckIn marks current booked shift of driver as attended when driver goes online
+func CheckIn(driverID bson.ObjectId) {
+	bookingCollection, session := bookingCollection()
+	defer session.Close()
+
+	now := timeHelper.GetCurrentTime()
+	bookingCollection.UpdateAll(bson.M{
+		"driverId": driverID,
+		"status":   config.ShiftBooked,
+		"start":    bson.M{"$lte": now + config.ShiftCheckInGrace},
+		"end":      bson.M{"$gt": now},
+	}, bson.M{"$set": bson.M{"status": config.ShiftAttended, "checkedInAt": now, "updatedAt": now}})
+}
+
+// MarkNoShows marks booked shifts that driver didn't go online in grace time, returns marked bookings
+func MarkNoShows() ([]*model.ShiftBooking, error) {
+	bookingCollection, session := bookingCollection()
+	defer session.Close()
+
+	now := timeHelper.GetCurrentTime()
+	query := bson.M{"status": config.ShiftBooked, "start": bson.M{"$lt": now - config.ShiftCheckInGrace}}
+	bookings := []*model.ShiftBooking{}
+	if err := bookingCollection.Find(query).All(&bookings); err != nil {
+		return nil, err
+	}
+	marked := []*model.ShiftBooking{}
+	for _, booking := range bookings {
+		// driver may check in between find and update
+		err := bookingCollection.Update(
+			bson.M{"_id": booking.ID, "status": config.ShiftBooked},
+			bson.M{"$set": bson.M{"status": config.ShiftNoShow, "updatedAt": now}})
+		if err == nil {
+			booking.Status = config.ShiftNoShow
+			marked = append(marked, booking)
+		}
+	}
+	return marked, nil
+}
+
+// OnShiftDriverIDs returns drivers that booked or attended shift at time
+func OnShiftDriverIDs(now int64) map[bson.ObjectId]bool {
+	bookingCollection, session := bookingCollection()
+	defer session.Close()
+
+	bookings := []*model.ShiftBooking{}
+	bookingCollection.Find(bson.M{
+		"status": bson.M{"$in": []string{config.ShiftBooked, config.ShiftAttended}},
+		"start":  bson.M{"$lte": now},
+		"end":    bson.M{"$gt": now},
+	}).Select(bson.M{"driverId": 1}).All(&bookings)
+
+	driverIDs := map[bson.ObjectId]bool{}
+	for _, booking := range bookings {
+		driverIDs[booking.DriverID] = true
+	}
+	return driverIDs
+}
+
+// ReadCoverage returns supply of drivers against forecasted demand of slots in period
+func ReadCoverage(placeID string, from int64, to int64) ([]*model.ShiftCoverage, error) {
+	slots, err := ReadSlots(placeID, from, to, false)
+	if err != nil {
+		return nil, err
+	}
+	bookingCollection, session := bookingCollection()
+	defer session.Close()
+
+	coverages := []*model.ShiftCoverage{}
+	for _, slot := range slots {
+		counts := []struct {
+			Status string `bson:"_id"`
+			Count  int    `bson:"count"`
+		}{}
+		err := bookingCollection.Pipe([]bson.M{
+			{"$match": bson.M{"slotId": slot.ID}},
+			{"$group": bson.M{"_id": "$status", "count": bson.M{"$sum": 1}}},
+		}).All(&counts)
+		if err != nil {
+			return nil, err
+		}
+
+		coverage := &model.ShiftCoverage{Slot: slot, Demand: slot.Forecast}
+		for _, count := range counts {
+			switch count.Status {
+			case config.ShiftBooked:
+				coverage.Booked += count.Count
+			case config.ShiftAttended:
+				coverage.Booked += count.Count
+				coverage.Attended = count.Count
+			case config.ShiftNoShow:
+				coverage.Booked += count.Count
+				coverage.NoShow = count.Count
+			}
+		}
+		if coverage.Demand == 0 {
+			coverage.Demand = forecastDemand(slot)
+		}
+		coverage.Needed = DriversNeeded(coverage.Demand, slot.End-slot.Start)
+		coverage.Gap = coverage.Needed - (coverage.Booked - coverage.NoShow)
+		coverages = append(coverages, coverage)
+	}
+	return coverages, nil
+}
+
+// forecastDemand returns average orders of zone in same hours of past weeks
+func forecastDemand(slot *model.ShiftSlot) int {
+	total := 0
+	for week := 1; week <= config.ShiftForecastWeeks; week++ {
+		offset := int64(week) * weekSeconds
+		total += orderService.CountOrdersInArea(slot.PlaceID, slot.Center, slot.Radius, slot.Start-offset, slot.End-offset)
+	}
+	return int(math.Ceil(float64(total) / float64(config.ShiftForecastWeeks)))
+}
+
+// DriversNeeded returns drivers that deliver orders in seconds of shift
+func DriversNeeded(orders int, seconds int64) int {
+	hours := float64(seconds) / 3600
+	if orders <= 0 || hours <= 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(orders) / (hours * config.ShiftOrdersPerDriverHour)))
+}
@@ -0,0 +1,43 @@
+package shiftService
+
+import (
+	"testing"
+
+	"../../model"
+)
+
+func TestValidSlot(t *testing.T) {
+	slot := &model.ShiftSlot{PlaceID: "city", Zone: "Downtown", Start: 3600, End: 7200, Capacity: 5}
+	if err := ValidSlot(slot); err != nil {
+		t.Error(err)
+	}
+	for _, invalid := range []*model.ShiftSlot{
+		{PlaceID: "city", Start: 3600, End: 7200, Capacity: 5},
+		{PlaceID: "city", Zone: "Downtown", Start: 7200, End: 3600, Capacity: 5},
+		{PlaceID: "city", Zone: "Downtown", Start: 3600, End: 7200},
+		{PlaceID: "city", Zone: "Downtown", Start: 3600, End: 7200, Capacity: 5, Center: &model.GeoJSON{Coordinates: []float64{1, 2}}},
+	} {
+		if ValidSlot(invalid) == nil {
+			t.Errorf("slot %+v is valid", invalid)
+		}
+	}
+}
+
+func TestDriversNeeded(t *testing.T) {
+	// 2 orders per driver in an hour
+	for _, c := range []struct {
+		orders  int
+		seconds int64
+		want    int
+	}{
+		{0, 3600, 0},
+		{4, 3600, 2},
+		{5, 3600, 3},
+		{5, 3 * 3600, 1},
+		{13, 2 * 3600, 4},
+	} {
+		if got := DriversNeeded(c.orders, c.seconds); got != c.want {
+			t.Errorf("DriversNeeded(%d, %d) = %d, want %d", c.orders, c.seconds, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package surgeService
+
+import (
+	"math"
+	"time"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// settingID is id of global setting of surge
+const settingID = "global"
+
+func surgeCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("surge"), session
+}
+
+func historyCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("surge_history"), session
+}
+
+func settingCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("surge_setting"), session
+}
+
+func quoteCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("surge_quote"), session
+}
+
+// InitService inits service
+func InitService() {
+	historyCollection, session := historyCollection()
+	defer session.Close()
+
+	historyCollection.EnsureIndex(mgo.Index{
+		Key:         []string{"date"},
+		ExpireAfter: config.SurgeHistoryRetention,
+	})
+	historyCollection.EnsureIndex(mgo.Index{
+		Key: []string{"zoneId", "at"},
+	})
+
+	quoteCollection, quoteSession := quoteCollection()
+	defer quoteSession.Close()
+
+	// date of quote is its expiry
+	quoteCollection.EnsureIndex(mgo.Index{
+		Key:         []string{"date"},
+		ExpireAfter: time.Second,
+	})
+}
+
+// RawMultiplier returns multiplier of ratio of open orders to online drivers
+func RawMultiplier(demand int, supply int) float64 {
+	if demand == 0 {
+		return 1
+	}
+	if supply == 0 {
+		return config.SurgeCap
+	}
+	ratio := float64(demand) / float64(supply)
+	return math.Min(config.SurgeCap, math.Max(1, 1+config.SurgeSensitivity*(ratio-config.SurgeThreshold)))
+}
+
+// Smooth moves previous multiplier toward raw multiplier, it is rounded to step toward raw so that it never stalls short of raw
+func Smooth(previous float64, raw float64) float64 {
+	if previous < 1 {
+		previous = 1
+	}
+	steps := (previous + config.SurgeSmoothing*(raw-previous)) / config.SurgeStep
+	target := math.Floor(raw/config.SurgeStep + 0.5)
+	if raw > previous {
+		steps = math.Min(math.Ceil(steps-1e-9), target)
+	} else {
+		steps = math.Max(math.Floor(steps+1e-9), target)
+	}
+	smoothed := math.Floor(steps*config.SurgeStep*100+0.5) / 100
+	return math.Min(config.SurgeCap, math.Max(1, smoothed))
+}
+
+// Effective returns multiplier that is charged, override of admin wins until it ends
+func Effective(surge *model.Surge, enabled bool, now int64) float64 {
+	if !enabled || surge == nil {
+		return 1
+	}
+	if surge.Override > 0 && (surge.OverrideUntil == 0 || now < surge.OverrideUntil) {
+		return surge.Override
+	}
+	if surge.Multiplier < 1 {
+		return 1
+	}
+	return surge.Multiplier
+}
+
+// ApplySurge adds surge fee to booking fee of order
+func ApplySurge(order *model.Order, multiplier float64) {
+	order.Surge = 0
+	order.SurgeFee = 0
+	if multiplier <= 1 {
+		return
+	}
+	order.Surge = multiplier
+	order.SurgeFee = math.Floor(order.BookingFee*(multiplier-1)*100+0.5) / 100
+	order.BookingFee += order.SurgeFee
+}
+
+// UpdateSurge computes surge of zone with open orders and online drivers and records history
+func UpdateSurge(zone *model.DeliveryZone, demand int, supply int, enabled bool) (*model.Surge, error) {
+	surgeCollection, session := surgeCollection()
+	defer session.Close()
+
+	now := timeHelper.GetCurrentTime()
+	previous := &model.Surge{}
+	if err := surgeCollection.FindId(zone.ID).One(previous); err != nil && err != mgo.ErrNotFound {
+		return nil, err
+	}
+	raw := RawMultiplier(demand, supply)
+
+	surge := &model.Surge{}
+	change := mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"zone":       zone.Name,
+			"placeId":    zone.PlaceID,
+			"demand":     demand,
+			"supply":     supply,
+			"raw":        raw,
+			"multiplier": Smooth(previous.Multiplier, raw),
+			"updatedAt":  now,
+		}},
+		Upsert:    true,
+		ReturnNew: true,
+	}
+	if _, err := surgeCollection.FindId(zone.ID).Apply(change, surge); err != nil {
+		return nil, err
+	}
+
+	historyCollection, historySession := historyCollection()
+	defer historySession.Close()
+
+	err := historyCollection.Insert(&model.SurgeHistory{
+		ZoneID:     zone.ID,
+		Demand:     demand,
+		Supply:     supply,
+		Raw:        raw,
+		Multiplier: Effective(surge, enabled, now),
+		At:         now,
+		Date:       time.Unix(now, 0),
+	})
+	return surge, err
+}
+
+// Multiplier returns multiplier that is charged in zone now
+func Multiplier(zoneID bson.ObjectId) float64 {
+	if zoneID == "" || !ReadSetting().Enabled {
+		return 1
+	}
+	surgeCollection, session := surgeCollection()
+	defer session.Close()
+
+	surge := &model.Surge{}
+	if err := surgeCollection.FindId(zoneID).One(surge); err != nil {
+		return 1
+	}
+	return Effective(surge, true, timeHelper.GetCurrentTime())
+}
+
+// LockQuote locks multiplier of zone for order that is created with quote
+func LockQuote(zoneID bson.ObjectId, multiplier float64) (bson.ObjectId, error) {
+	quoteCollection, session := quoteCollection()
+	defer session.Close()
+
+	expiresAt := time.Now().Add(config.SurgeQuoteTTL)
+	quote := &model.SurgeQuote{
+		ID:         bson.NewObjectId(),
+		ZoneID:     zoneID,
+		Multiplier: multiplier,
+		ExpiresAt:  expiresAt.Unix(),
+		Date:       expiresAt,
+	}
+	err := quoteCollection.Insert(quote)
+	return quote.ID, err
+}
+
+// ClaimQuote returns locked multiplier of quote once, current multiplier is used when quote is expired or of other zone
+func ClaimQuote(quoteID bson.ObjectId, zoneID bson.ObjectId) float64 {
+	if quoteID.Valid() {
+		quoteCollection, session := quoteCollection()
+		defer session.Close()
+
+		quote := &model.SurgeQuote{}
+		_, err := quoteCollection.Find(bson.M{
+			"_id":       quoteID,
+			"zoneId":    zoneID,
+			"used":      false,
+			"expiresAt": bson.M{"$gte": timeHelper.GetCurrentTime()},
+		}).Apply(mgo.Change{Update: bson.M{"$set": bson.M{"used": true}}}, quote)
+		if err == nil {
+			return quote.Multiplier
+		}
+	}
+	return Multiplier(zoneID)
+}
+
+// ReadSurges reads current surges of city, every city when place is empty
+func ReadSurges(placeID string) ([]*model.Surge, error) {
+	surgeCollection, session := surgeCollection()
+	defer session.Close()
+
+	query := bson.M{}
+	if placeID != "" {
+		query["placeId"] = placeID
+	}
+	surges := []*model.Surge{}
+	err := surgeCollection.Find(query).Sort("-multiplier").All(&surges)
+	return surges, err
+}
+
+// SetOverride sets multiplier of admin for zone until time, zero until keeps it until it is cleared
+func SetOverride(zoneID bson.ObjectId, multiplier float64, until int64) (*model.Surge, error) {
+	surgeCollection, session := surgeCollection()
+	defer session.Close()
+
+	surge := &model.Surge{}
+	change := mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"override":      multiplier,
+			"overrideUntil": until,
+		}},
+		Upsert:    true,
+		ReturnNew: true,
+	}
+	_, err := surgeCollection.FindId(zoneID).Apply(change, surge)
+	return surge, err
+}
+
+// ClearOverride removes override of admin for zone
+func ClearOverride(zoneID bson.ObjectId) error {
+	surgeCollection, session := surgeCollection()
+	defer session.Close()
+
+	return surgeCollection.UpdateId(zoneID, bson.M{"$unset": bson.M{"override": "", "overrideUntil": ""}})
+}
+
+// ReadSetting reads global setting of surge, surge is enabled by default
+func ReadSetting() *model.SurgeSetting {
+	settingCollection, session := settingCollection()
+	defer session.Close()
+
+	setting := &model.SurgeSetting{ID: settingID, Enabled: true}
+	settingCollection.FindId(settingID).One(setting)
+	return setting
+}
+
+// UpdateSetting turns surge of every zone on or off
+func UpdateSetting(enabled bool, adminID bson.ObjectId) (*model.SurgeSetting, error) {
+	settingCollection, session := settingCollection()
+	defer session.Close()
+
+	setting := &model.SurgeSetting{
+		ID:        settingID,
+		Enabled:   enabled,
+		AdminID:   adminID,
+		UpdatedAt: timeHelper.GetCurrentTime(),
+	}
+	_, err := settingCollection.UpsertId(settingID, setting)
+	return setting, err
+}
+
+// ReadHistory reads surge of zone in period
+func ReadHistory(zoneID bson.ObjectId, from int64, to int64) ([]*model.SurgeHistory, error) {
+	historyCollection, session := historyCollection()
+	defer session.Close()
+
+	query := bson.M{"zoneId": zoneID, "at": bson.M{"$gte": from}}
+	if to > 0 {
+		query["at"] = bson.M{"$gte": from, "$lt": to}
+	}
+	histories := []*model.SurgeHistory{}
+	err := historyCollection.Find(query).Sort("at").All(&histories)
+	return histories, err
+}
@@ -0,0 +1,86 @@
+package surgeService
+
+import (
+	"math"
+	"testing"
+
+	"../../config"
+	"../../model"
+)
+
+func TestRawMultiplier(t *testing.T) {
+	cases := []struct {
+		demand, supply int
+		want           float64
+	}{
+		{0, 0, 1},
+		{5, 10, 1},
+		{10, 10, 1},
+		{20, 10, 1.5},
+		{100, 10, config.SurgeCap},
+		{3, 0, config.SurgeCap},
+	}
+	for _, c := range cases {
+		if got := RawMultiplier(c.demand, c.supply); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("RawMultiplier(%d, %d) = %v, want %v", c.demand, c.supply, got, c.want)
+		}
+	}
+}
+
+func TestSmooth(t *testing.T) {
+	// jump to cap is reached over several runs
+	multiplier := 1.0
+	for i := 0; i < 3; i++ {
+		multiplier = Smooth(multiplier, config.SurgeCap)
+	}
+	if multiplier <= 1.5 || multiplier >= config.SurgeCap {
+		t.Errorf("smoothed multiplier = %v", multiplier)
+	}
+	for i := 0; i < 30; i++ {
+		multiplier = Smooth(multiplier, 1)
+	}
+	if multiplier != 1 {
+		t.Errorf("multiplier after demand drops = %v", multiplier)
+	}
+	if got := Smooth(0, 1.04); got != 1 {
+		t.Errorf("Smooth of new zone = %v", got)
+	}
+	for i := 0; i < 30; i++ {
+		multiplier = Smooth(multiplier, 1.5)
+	}
+	if multiplier != 1.5 {
+		t.Errorf("multiplier after demand rises = %v", multiplier)
+	}
+}
+
+func TestEffective(t *testing.T) {
+	surge := &model.Surge{Multiplier: 1.5}
+	if got := Effective(surge, true, 100); got != 1.5 {
+		t.Errorf("Effective = %v", got)
+	}
+	if got := Effective(surge, false, 100); got != 1 {
+		t.Errorf("Effective with kill switch = %v", got)
+	}
+	surge.Override, surge.OverrideUntil = 2, 200
+	if got := Effective(surge, true, 100); got != 2 {
+		t.Errorf("Effective with override = %v", got)
+	}
+	if got := Effective(surge, true, 200); got != 1.5 {
+		t.Errorf("Effective after override = %v", got)
+	}
+	if got := Effective(nil, true, 100); got != 1 {
+		t.Errorf("Effective without surge = %v", got)
+	}
+}
+
+func TestApplySurge(t *testing.T) {
+	order := &model.Order{BookingFee: 3.99}
+	ApplySurge(order, 1.5)
+	if order.Surge != 1.5 || order.SurgeFee != 2 || order.BookingFee != 5.99 {
+		t.Errorf("order with surge = %v %v %v", order.Surge, order.SurgeFee, order.BookingFee)
+	}
+	order = &model.Order{BookingFee: 3.99}
+	if ApplySurge(order, 1); order.SurgeFee != 0 || order.BookingFee != 3.99 {
+		t.Errorf("order without surge = %v %v", order.SurgeFee, order.BookingFee)
+	}
+}
@@ -0,0 +1,162 @@
+package trackingService
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"../../config"
+	"../../model"
+	"../../util/geo"
+	"../../util/timeHelper"
+	"../driverLocationService"
+	"../orderService"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ReadTracking reads live tracking of order with latest location of assigned driver
+func ReadTracking(orderID bson.ObjectId) (*model.Tracking, error) {
+	order, err := orderService.ReadOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+	var location *model.DriverLocation
+	if IsTracked(order) {
+		location, err = driverLocationService.ReadDriverLocation(order.DriverID)
+		if err != nil {
+			location = nil
+		}
+	}
+	return BuildTracking(order, location, timeHelper.GetCurrentTime()), nil
+}
+
+// IsTracked returns true when driver is assigned and trip is between acceptance and completion
+func IsTracked(order *model.Order) bool {
+	if !order.DriverID.Valid() || order.OrderStatus == config.OrderCancelled {
+		return false
+	}
+	for _, status := range config.ActiveTripStatuses {
+		if order.TripStatus == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinished returns true when order can't be tracked anymore
+func IsFinished(tracking *model.Tracking) bool {
+	switch tracking.TripStatus {
+	case config.TripCompleted, config.TripCancelled:
+		return true
+	}
+	return tracking.OrderStatus == config.OrderCancelled || tracking.OrderStatus == config.OrderDeclined
+}
+
+// BuildTracking returns tracking of order, driver and location are hidden while trip is not active
+func BuildTracking(order *model.Order, location *model.DriverLocation, now int64) *model.Tracking {
+	tracking := &model.Tracking{
+		OrderID:     order.ID,
+		Number:      order.Number,
+		OrderStatus: order.OrderStatus,
+		TripStatus:  order.TripStatus,
+		Active:      IsTracked(order),
+		UpdatedAt:   now,
+	}
+	if order.DeliveryLocation != nil && len(order.DeliveryLocation.GeoJSON.Coordinates) >= 2 {
+		destination := order.DeliveryLocation.GeoJSON
+		tracking.Destination = &destination
+	}
+	if !tracking.Active {
+		return tracking
+	}
+	if order.Driver != nil {
+		tracking.Driver = trackingDriver(order.Driver, location)
+	}
+	if location == nil || location.DriverID != order.DriverID || len(location.Location.Coordinates) < 2 {
+		return tracking
+	}
+
+	current := location.Location
+	tracking.Location = &current
+	tracking.LocationAt = location.UpdatedAt
+	if tracking.Destination == nil {
+		return tracking
+	}
+	// driver goes to business before arrival and to delivery location after it
+	distance := 0.0
+	switch order.TripStatus {
+	case config.TripAccepted, config.TripConfirmed, config.TripStarted:
+		if order.Business != nil && len(order.Business.GeoLocation.GeoJSON.Coordinates) >= 2 {
+			pickup := order.Business.GeoLocation.GeoJSON.Coordinates
+			distance = geo.DistanceOfCoordinates(current.Coordinates, pickup) +
+				geo.DistanceOfCoordinates(pickup, tracking.Destination.Coordinates)
+		} else {
+			distance = geo.DistanceOfCoordinates(current.Coordinates, tracking.Destination.Coordinates)
+		}
+	case config.TripArrived:
+		distance = geo.DistanceOfCoordinates(current.Coordinates, tracking.Destination.Coordinates)
+	}
+	tracking.Distance = distance * config.TrackingRouteFactor
+	tracking.ETA = int64(tracking.Distance / config.DeliverySpeed * 60)
+	return tracking
+}
+
+func trackingDriver(driver *model.Driver, location *model.DriverLocation) *model.TrackingDriver {
+	trackingDriver := &model.TrackingDriver{
+		Firstname: driver.Firstname,
+		Avatar:    driver.Avatar,
+		Rating:    driver.Rating,
+	}
+	if location == nil {
+		return trackingDriver
+	}
+	for _, vehicle := range driver.DriverVehicles {
+		if vehicle.VehicleID == location.VehicleID {
+			trackingDriver.Vehicle = strings.TrimSpace(vehicle.Brand + " " + vehicle.Model + " " + vehicle.Color)
+			trackingDriver.Number = vehicle.Number
+			break
+		}
+	}
+	return trackingDriver
+}
+
+// Changed returns true when tracking is different from tracking that was sent
+func Changed(previous, current *model.Tracking) bool {
+	if previous == nil {
+		return true
+	}
+	return previous.OrderStatus != current.OrderStatus || previous.TripStatus != current.TripStatus ||
+		previous.Active != current.Active || previous.LocationAt != current.LocationAt || previous.ETA != current.ETA
+}
+
+// ShareToken returns token of tracking link of order that is valid until expiresAt
+func ShareToken(orderID bson.ObjectId, expiresAt int64) string {
+	payload := orderID.Hex() + "." + strconv.FormatInt(expiresAt, 10)
+	return payload + "." + signature(payload)
+}
+
+// ParseShareToken returns order and expiry of tracking link, error when token is forged or expired
+func ParseShareToken(token string, now int64) (bson.ObjectId, int64, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || !bson.IsObjectIdHex(parts[0]) {
+		return "", 0, errors.New("Tracking link is invalid")
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(signature(parts[0]+"."+parts[1]))) {
+		return "", 0, errors.New("Tracking link is invalid")
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || expiresAt < now {
+		return "", 0, errors.New("Tracking link is expired")
+	}
+	return bson.ObjectIdHex(parts[0]), expiresAt, nil
+}
+
+func signature(payload string) string {
+	mac := hmac.New(sha256.New, []byte(config.AuthTokenKey))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,76 @@
+package trackingService
+
+import (
+	"strings"
+	"testing"
+
+	"../../config"
+	"../../model"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestBuildTracking(t *testing.T) {
+	driverID, vehicleID := bson.NewObjectId(), bson.NewObjectId()
+	order := &model.Order{
+		ID:               bson.NewObjectId(),
+		DriverID:         driverID,
+		Driver:           &model.Driver{Firstname: "Sam", DriverVehicles: []*model.DriverVehicle{{Number: "ABC1", VehicleID: vehicleID, Brand: "Honda"}}},
+		Business:         &model.Business{GeoLocation: model.GeoLocation{GeoJSON: model.GeoJSON{Coordinates: []float64{0, 0.01}}}},
+		DeliveryLocation: &model.GeoLocation{GeoJSON: model.GeoJSON{Coordinates: []float64{0, 0.02}}},
+		TripStatus:       config.TripAccepted,
+	}
+	location := &model.DriverLocation{DriverID: driverID, VehicleID: vehicleID, Location: model.GeoJSON{Coordinates: []float64{0, 0}}, UpdatedAt: 100}
+
+	tracking := BuildTracking(order, location, 200)
+	if !tracking.Active || tracking.Location == nil || tracking.Driver.Number != "ABC1" {
+		t.Fatalf("tracking = %+v", tracking)
+	}
+	// driver goes through business, about 2.2km
+	if tracking.Distance < 2800 || tracking.Distance > 2950 || tracking.ETA != int64(tracking.Distance/config.DeliverySpeed*60) {
+		t.Errorf("distance = %f, eta = %d", tracking.Distance, tracking.ETA)
+	}
+
+	// driver at business goes only to delivery location
+	order.TripStatus = config.TripArrived
+	location.Location.Coordinates = []float64{0, 0.01}
+	if tracking = BuildTracking(order, location, 200); tracking.Distance > 1500 || tracking.Distance < 1400 {
+		t.Errorf("distance after arrival = %f", tracking.Distance)
+	}
+	order.TripStatus = config.TripDropped
+	if tracking = BuildTracking(order, location, 200); tracking.ETA != 0 || tracking.Location == nil {
+		t.Errorf("tracking after drop = %+v", tracking)
+	}
+
+	// location is hidden before driver is assigned and after completion
+	for _, status := range []string{config.TripRequest, config.TripCompleted, config.TripCancelled} {
+		order.TripStatus = status
+		if tracking = BuildTracking(order, location, 200); tracking.Active || tracking.Location != nil || tracking.Driver != nil {
+			t.Errorf("tracking of %s = %+v", status, tracking)
+		}
+	}
+
+	// location of other driver is not shown
+	order.TripStatus = config.TripStarted
+	location.DriverID = bson.NewObjectId()
+	if tracking = BuildTracking(order, location, 200); tracking.Location != nil {
+		t.Errorf("location of other driver = %+v", tracking.Location)
+	}
+}
+
+func TestShareToken(t *testing.T) {
+	orderID := bson.NewObjectId()
+	token := ShareToken(orderID, 1000)
+
+	if id, expiresAt, err := ParseShareToken(token, 999); err != nil || id != orderID || expiresAt != 1000 {
+		t.Errorf("ParseShareToken = %s, %d, %v", id.Hex(), expiresAt, err)
+	}
+	if _, _, err := ParseShareToken(token, 1001); err == nil {
+		t.Error("expired token is valid")
+	}
+	// expiry can't be extended without key
+	forged := orderID.Hex() + ".5000." + strings.Split(token, ".")[2]
+	if _, _, err := ParseShareToken(forged, 1001); err == nil {
+		t.Error("forged token is valid")
+	}
+}
@@ -0,0 +1,257 @@
+package walletService
+
+import (
+	"errors"
+	"sort"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../util/timeHelper"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ErrInsufficientBalance is returned when debit is more than balance
+var ErrInsufficientBalance = errors.New("Balance of wallet is insufficient")
+
+func walletCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("wallet_transaction"), session
+}
+
+// InitService inits service
+func InitService() {
+	walletCollection, session := walletCollection()
+	defer session.Close()
+
+	// sequence serializes concurrent transactions of user
+	walletCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"userId", "seq"},
+		Unique: true,
+	})
+	walletCollection.EnsureIndex(mgo.Index{
+		Key:    []string{"key"},
+		Unique: true,
+		Sparse: true,
+	})
+}
+
+// Credit appends credit to wallet of user
+func Credit(transaction *model.WalletTransaction) (*model.WalletTransaction, error) {
+	if transaction.Amount <= 0 {
+		return nil, errors.New("Amount of credit is invalid")
+	}
+	return appendTransaction(transaction)
+}
+
+// Debit appends debit to wallet of user, amount is positive
+func Debit(transaction *model.WalletTransaction) (*model.WalletTransaction, error) {
+	if transaction.Amount <= 0 {
+		return nil, errors.New("Amount of debit is invalid")
+	}
+	expireCredits(transaction.UserID)
+	transaction.Amount = -transaction.Amount
+	return appendTransaction(transaction)
+}
+
+// ApplyToOrder pays total of order with wallet as much as possible and returns paid amount
+func ApplyToOrder(userID bson.ObjectId, orderID bson.ObjectId, total int64) (int64, error) {
+	for i := 0; i < config.WalletRetry; i++ {
+		wallet, err := ReadWallet(userID)
+		if err != nil {
+			return 0, err
+		}
+		amount := wallet.Balance
+		if amount > total {
+			amount = total
+		}
+		if amount <= 0 {
+			return 0, nil
+		}
+		_, err = appendTransaction(&model.WalletTransaction{
+			UserID:  userID,
+			Source:  config.WalletOrder,
+			Amount:  -amount,
+			OrderID: orderID,
+			Key:     "order:" + orderID.Hex(),
+		})
+		// balance is changed by other order
+		if err == ErrInsufficientBalance {
+			continue
+		}
+		return amount, err
+	}
+	return 0, ErrInsufficientBalance
+}
+
+// ReadWallet returns balance of user after expired credits are removed
+func ReadWallet(userID bson.ObjectId) (*model.Wallet, error) {
+	expireCredits(userID)
+	transactions, err := readAllTransactions(userID)
+	if err != nil {
+		return nil, err
+	}
+	wallet := &model.Wallet{
+		UserID:   userID,
+		Currency: config.PaymentCurrency,
+		Expiring: []*model.WalletLot{},
+	}
+	for _, lot := range Lots(transactions) {
+		wallet.Balance += lot.Amount
+		if lot.ExpiresAt > 0 {
+			wallet.Expiring = append(wallet.Expiring, lot)
+		}
+	}
+	return wallet, nil
+}
+
+// ReadTransactions returns ledger of user in order of sequence
+func ReadTransactions(userID bson.ObjectId, offset int, count int) ([]*model.WalletTransaction, int, error) {
+	walletCollection, session := walletCollection()
+	defer session.Close()
+
+	query := bson.M{"userId": userID}
+	totalCount, _ := walletCollection.Find(query).Count()
+	transactions := []*model.WalletTransaction{}
+	err := walletCollection.Find(query).Sort("-seq").Skip(offset).Limit(count).All(&transactions)
+	return transactions, totalCount, err
+}
+
+// Lots replays ledger and returns remaining amount of credits,
+// debits consume credits that expire first and credits without expiry last
+func Lots(transactions []*model.WalletTransaction) []*model.WalletLot {
+	lots := []*model.WalletLot{}
+	for _, transaction := range transactions {
+		if transaction.Amount > 0 {
+			lots = append(lots, &model.WalletLot{
+				CreditID:  transaction.ID,
+				Amount:    transaction.Amount,
+				ExpiresAt: transaction.ExpiresAt,
+			})
+			continue
+		}
+		debit := -transaction.Amount
+		if transaction.CreditID != "" {
+			// expiry consumes expired credit only
+			for _, lot := range lots {
+				if lot.CreditID == transaction.CreditID {
+					consumed := min(lot.Amount, debit)
+					lot.Amount -= consumed
+					debit -= consumed
+				}
+			}
+			continue
+		}
+		sort.SliceStable(lots, func(i, j int) bool {
+			return expiry(lots[i]) < expiry(lots[j])
+		})
+		for _, lot := range lots {
+			consumed := min(lot.Amount, debit)
+			lot.Amount -= consumed
+			debit -= consumed
+		}
+	}
+	remaining := []*model.WalletLot{}
+	for _, lot := range lots {
+		if lot.Amount > 0 {
+			remaining = append(remaining, lot)
+		}
+	}
+	return remaining
+}
+
+// ExpiredLots returns remaining credits that are expired at time
+func ExpiredLots(transactions []*model.WalletTransaction, now int64) []*model.WalletLot {
+	expired := []*model.WalletLot{}
+	for _, lot := range Lots(transactions) {
+		if lot.ExpiresAt > 0 && lot.ExpiresAt <= now {
+			expired = append(expired, lot)
+		}
+	}
+	return expired
+}
+
+// expireCredits appends expiry of expired credits of user
+func expireCredits(userID bson.ObjectId) {
+	transactions, err := readAllTransactions(userID)
+	if err != nil {
+		return
+	}
+	for _, lot := range ExpiredLots(transactions, timeHelper.GetCurrentTime()) {
+		appendTransaction(&model.WalletTransaction{
+			UserID:   userID,
+			Source:   config.WalletExpiry,
+			Amount:   -lot.Amount,
+			CreditID: lot.CreditID,
+			Key:      "expiry:" + lot.CreditID.Hex(),
+		})
+	}
+}
+
+// appendTransaction appends transaction after last sequence of user,
+// transaction with same key is appended once
+func appendTransaction(transaction *model.WalletTransaction) (*model.WalletTransaction, error) {
+	walletCollection, session := walletCollection()
+	defer session.Close()
+
+	for i := 0; i < config.WalletRetry; i++ {
+		if existed := readTransactionByKey(walletCollection, transaction.Key); existed != nil {
+			return existed, nil
+		}
+		last := &model.WalletTransaction{}
+		if err := walletCollection.Find(bson.M{"userId": transaction.UserID}).Sort("-seq").One(last); err != nil && err != mgo.ErrNotFound {
+			return nil, err
+		}
+		if last.Balance+transaction.Amount < 0 {
+			return nil, ErrInsufficientBalance
+		}
+		transaction.ID = bson.NewObjectId()
+		transaction.Seq = last.Seq + 1
+		transaction.Balance = last.Balance + transaction.Amount
+		transaction.CreatedAt = timeHelper.GetCurrentTime()
+		err := walletCollection.Insert(transaction)
+		// other transaction took sequence, retry with new balance
+		if mgo.IsDup(err) {
+			continue
+		}
+		return transaction, err
+	}
+	return nil, errors.New("Wallet is busy, try again")
+}
+
+func readTransactionByKey(walletCollection *mgo.Collection, key string) *model.WalletTransaction {
+	if key == "" {
+		return nil
+	}
+	transaction := &model.WalletTransaction{}
+	if err := walletCollection.Find(bson.M{"key": key}).One(transaction); err != nil {
+		return nil
+	}
+	return transaction
+}
+
+func readAllTransactions(userID bson.ObjectId) ([]*model.WalletTransaction, error) {
+	walletCollection, session := walletCollection()
+	defer session.Close()
+
+	transactions := []*model.WalletTransaction{}
+	err := walletCollection.Find(bson.M{"userId": userID}).Sort("seq").All(&transactions)
+	return transactions, err
+}
+
+// expiry returns sort key of lot, credit without expiry is consumed last
+func expiry(lot *model.WalletLot) int64 {
+	if lot.ExpiresAt == 0 {
+		return 1<<63 - 1
+	}
+	return lot.ExpiresAt
+}
+
+func min(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
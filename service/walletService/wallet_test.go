@@ -0,0 +1,50 @@
+package walletService
+
+import (
+	"testing"
+
+	"../../model"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestLots(t *testing.T) {
+	permanent := bson.NewObjectId()
+	later := bson.NewObjectId()
+	sooner := bson.NewObjectId()
+	transactions := []*model.WalletTransaction{
+		{ID: permanent, Amount: 500},
+		{ID: later, Amount: 300, ExpiresAt: 200},
+		{ID: sooner, Amount: 200, ExpiresAt: 100},
+		// order consumes credit that expires first
+		{ID: bson.NewObjectId(), Amount: -250},
+	}
+
+	lots := Lots(transactions)
+	balance := int64(0)
+	for _, lot := range lots {
+		balance += lot.Amount
+	}
+	if balance != 750 {
+		t.Fatalf("balance is %d, expected 750", balance)
+	}
+
+	expired := ExpiredLots(transactions, 150)
+	if len(expired) != 0 {
+		t.Errorf("consumed credit is expired: %v", expired)
+	}
+	expired = ExpiredLots(transactions, 200)
+	if len(expired) != 1 || expired[0].CreditID != later || expired[0].Amount != 250 {
+		t.Fatalf("expired lots are %v", expired)
+	}
+
+	// expiry removes expired credit only
+	transactions = append(transactions, &model.WalletTransaction{ID: bson.NewObjectId(), Amount: -250, CreditID: later})
+	if expired := ExpiredLots(transactions, 300); len(expired) != 0 {
+		t.Errorf("expired credit is expired again: %v", expired)
+	}
+	lots = Lots(transactions)
+	if len(lots) != 1 || lots[0].CreditID != permanent || lots[0].Amount != 500 {
+		t.Errorf("remaining lots are %v", lots)
+	}
+}
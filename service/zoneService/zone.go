@@ -0,0 +1,274 @@
+package zoneService
+
+import (
+	"errors"
+
+	"../../config"
+	"../../db"
+	"../../model"
+	"../../util/geo"
+	"../../util/timeHelper"
+	"../authService/businessService"
+	"../locationService"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func zoneCollection() (*mgo.Collection, *mgo.Session) {
+	mgoDB, session := db.MongoDB()
+	return mgoDB.C("delivery_zone"), session
+}
+
+// InitService inits service
+func InitService() {
+	zoneCollection, session := zoneCollection()
+	defer session.Close()
+
+	// zone is resolved from coordinates
+	zoneCollection.EnsureIndex(mgo.Index{
+		Key: []string{"$2dsphere:area"},
+	})
+	zoneCollection.EnsureIndex(mgo.Index{
+		Key: []string{"locationId"},
+	})
+}
+
+// ValidZone checks kind and polygon of zone
+func ValidZone(zone *model.DeliveryZone) error {
+	if zone.Name == "" {
+		return errors.New("Name of zone is empty")
+	}
+	if zone.Kind != config.ZoneService && zone.Kind != config.ZoneExclusion {
+		return errors.New("Kind of zone must be service or exclusion")
+	}
+	return ValidArea(&zone.Area)
+}
+
+// ValidArea checks geojson polygon
+func ValidArea(area *model.Polygon) error {
+	if area.Type != "Polygon" {
+		return errors.New("Area must be geojson polygon")
+	}
+	return geo.ValidPolygon(area.Coordinates)
+}
+
+// CreateZone creates zone in location
+func CreateZone(locationID bson.ObjectId, zone *model.DeliveryZone) (*model.DeliveryZone, error) {
+	if err := ValidZone(zone); err != nil {
+		return nil, err
+	}
+	location, err := locationService.ReadLocation(locationID)
+	if err != nil {
+		return nil, err
+	}
+	zoneCollection, session := zoneCollection()
+	defer session.Close()
+
+	zone.ID = bson.NewObjectId()
+	zone.LocationID = location.ID
+	zone.PlaceID = location.PlaceID
+	zone.CreatedAt = timeHelper.GetCurrentTime()
+	zone.UpdatedAt = zone.CreatedAt
+	err = zoneCollection.Insert(zone)
+	return zone, err
+}
+
+// UpdateZone updates name, kind, area and activation of zone
+func UpdateZone(objid bson.ObjectId, zone *model.DeliveryZone) (*model.DeliveryZone, error) {
+	if err := ValidZone(zone); err != nil {
+		return nil, err
+	}
+	zoneCollection, session := zoneCollection()
+	defer session.Close()
+
+	change := mgo.Change{
+		Update: bson.M{"$set": bson.M{
+			"name":      zone.Name,
+			"kind":      zone.Kind,
+			"area":      zone.Area,
+			"active":    zone.Active,
+			"updatedAt": timeHelper.GetCurrentTime(),
+		}},
+		ReturnNew: true,
+	}
+	_, err := zoneCollection.FindId(objid).Apply(change, zone)
+	return zone, err
+}
+
+// DeleteZone deletes zone
+func DeleteZone(objid bson.ObjectId) error {
+	zoneCollection, session := zoneCollection()
+	defer session.Close()
+
+	return zoneCollection.RemoveId(objid)
+}
+
+// ReadZone reads zone
+func ReadZone(objid bson.ObjectId) (*model.DeliveryZone, error) {
+	zoneCollection, session := zoneCollection()
+	defer session.Close()
+
+	zone := &model.DeliveryZone{}
+	err := zoneCollection.FindId(objid).One(zone)
+	return zone, err
+}
+
+// ReadZones reads zones of location
+func ReadZones(locationID bson.ObjectId) ([]*model.DeliveryZone, error) {
+	zoneCollection, session := zoneCollection()
+	defer session.Close()
+
+	zones := []*model.DeliveryZone{}
+	err := zoneCollection.Find(bson.M{"locationId": locationID}).Sort("kind", "name").All(&zones)
+	return zones, err
+}
+
+// ReadActiveZones reads active zones of kind
+func ReadActiveZones(kind string) ([]*model.DeliveryZone, error) {
+	zoneCollection, session := zoneCollection()
+	defer session.Close()
+
+	zones := []*model.DeliveryZone{}
+	err := zoneCollection.Find(bson.M{"kind": kind, "active": true}).All(&zones)
+	return zones, err
+}
+
+// ResolveZone returns service zone that point is in, error when point is out of service or in exclusion zone,
+// whole city is service zone without id until zones of city are drawn
+func ResolveZone(point []float64) (*model.DeliveryZone, error) {
+	if len(point) < 2 {
+		return nil, errors.New("Location is invalid")
+	}
+	zoneCollection, session := zoneCollection()
+	defer session.Close()
+
+	zones := []*model.DeliveryZone{}
+	err := zoneCollection.Find(bson.M{
+		"active": true,
+		"area": bson.M{"$geoIntersects": bson.M{
+			"$geometry": bson.M{"type": "Point", "coordinates": point},
+		}},
+	}).All(&zones)
+	if err != nil {
+		return nil, err
+	}
+	if len(zones) == 0 {
+		return fallbackZone(point)
+	}
+	return PickZone(zones)
+}
+
+// fallbackZone returns city of point by radius of location when city has no service zone drawn yet
+func fallbackZone(point []float64) (*model.DeliveryZone, error) {
+	locations, _, err := locationService.ReadLocations("", 0, 0, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	zoneCollection, session := zoneCollection()
+	defer session.Close()
+
+	locationIDs := []bson.ObjectId{}
+	if err := zoneCollection.Find(bson.M{"kind": config.ZoneService, "active": true}).Distinct("locationId", &locationIDs); err != nil {
+		return nil, err
+	}
+	zoned := map[bson.ObjectId]bool{}
+	for _, locationID := range locationIDs {
+		zoned[locationID] = true
+	}
+	return CityZone(point, locations, zoned)
+}
+
+// CityZone returns whole city of nearest location that point is in radius of as service zone,
+// city that has service zones is served only in its zones
+func CityZone(point []float64, locations []*model.Location, zoned map[bson.ObjectId]bool) (*model.DeliveryZone, error) {
+	var city *model.Location
+	nearest := 0.0
+	for _, location := range locations {
+		radius := location.SearchRadius
+		if radius <= 0 {
+			radius = config.DefaultDiscoverRadius
+		}
+		distance := geo.DistanceOfCoordinates([]float64{location.Longitude, location.Latitude}, point)
+		if distance <= radius && (city == nil || distance < nearest) {
+			city, nearest = location, distance
+		}
+	}
+	if city == nil || zoned[city.ID] {
+		return nil, errors.New("This address is out of our service area")
+	}
+	return &model.DeliveryZone{
+		LocationID: city.ID,
+		PlaceID:    city.PlaceID,
+		Name:       city.City,
+		Kind:       config.ZoneService,
+		Active:     true,
+	}, nil
+}
+
+// PickZone returns service zone of zones that contain point, exclusion zone wins over service zones
+func PickZone(zones []*model.DeliveryZone) (*model.DeliveryZone, error) {
+	var service *model.DeliveryZone
+	for _, zone := range zones {
+		switch zone.Kind {
+		case config.ZoneExclusion:
+			return nil, errors.New("We don't deliver to this address")
+		case config.ZoneService:
+			if service == nil {
+				service = zone
+			}
+		}
+	}
+	if service == nil {
+		return nil, errors.New("This address is out of our service area")
+	}
+	return service, nil
+}
+
+// CheckDeliverable returns error when business doesn't deliver to point
+func CheckDeliverable(business *model.Business, point []float64) error {
+	if !Deliverable(&business.GeoLocation, business.DeliveryArea, point) {
+		return errors.New("This business doesn't deliver to this address")
+	}
+	return nil
+}
+
+// Deliverable returns true when delivery area of business at location contains point,
+// area without polygon is radius around business
+func Deliverable(location *model.GeoLocation, area *model.DeliveryArea, point []float64) bool {
+	if area != nil && area.Area != nil {
+		return geo.InPolygon(point, area.Area.Coordinates)
+	}
+	radius := config.DefaultDeliveryRadius
+	if area != nil && area.Radius > 0 {
+		radius = area.Radius
+	}
+	return geo.DistanceOfCoordinates(location.GeoJSON.Coordinates, point) <= radius
+}
+
+// FilterDeliverable returns businesses that deliver to point
+func FilterDeliverable(businesses []*model.PublicBusiness, point []float64) []*model.PublicBusiness {
+	result := []*model.PublicBusiness{}
+	for _, business := range businesses {
+		if Deliverable(&business.GeoLocation, business.DeliveryArea, point) {
+			result = append(result, business)
+		}
+	}
+	return result
+}
+
+// CheckDelivery resolves zone of delivery location and checks that business delivers to it
+func CheckDelivery(businessID bson.ObjectId, location *model.GeoLocation) (*model.DeliveryZone, error) {
+	if location == nil {
+		return nil, errors.New("Delivery location is empty")
+	}
+	zone, err := ResolveZone(location.GeoJSON.Coordinates)
+	if err != nil {
+		return nil, err
+	}
+	business, err := businessService.ReadBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	return zone, CheckDeliverable(business, location.GeoJSON.Coordinates)
+}
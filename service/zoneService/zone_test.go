@@ -0,0 +1,107 @@
+package zoneService
+
+import (
+	"testing"
+
+	"../../config"
+	"../../model"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+var square = model.Polygon{Type: "Polygon", Coordinates: [][][]float64{{{0, 0}, {0.1, 0}, {0.1, 0.1}, {0, 0.1}, {0, 0}}}}
+
+func TestValidZone(t *testing.T) {
+	if err := ValidZone(&model.DeliveryZone{Name: "Downtown", Kind: config.ZoneService, Area: square}); err != nil {
+		t.Error(err)
+	}
+	for _, invalid := range []*model.DeliveryZone{
+		{Kind: config.ZoneService, Area: square},
+		{Name: "Downtown", Kind: "other", Area: square},
+		{Name: "Downtown", Kind: config.ZoneExclusion, Area: model.Polygon{Type: "Point"}},
+	} {
+		if ValidZone(invalid) == nil {
+			t.Errorf("zone %+v is valid", invalid)
+		}
+	}
+}
+
+func TestPickZone(t *testing.T) {
+	service := &model.DeliveryZone{Name: "Downtown", Kind: config.ZoneService}
+	exclusion := &model.DeliveryZone{Name: "Airport", Kind: config.ZoneExclusion}
+
+	if zone, err := PickZone([]*model.DeliveryZone{service}); err != nil || zone != service {
+		t.Errorf("PickZone = %v, %v", zone, err)
+	}
+	if _, err := PickZone([]*model.DeliveryZone{service, exclusion}); err == nil {
+		t.Error("point in exclusion zone is served")
+	}
+	if _, err := PickZone(nil); err == nil {
+		t.Error("point out of service is served")
+	}
+}
+
+func TestCheckDeliverable(t *testing.T) {
+	business := &model.Business{GeoLocation: model.GeoLocation{GeoJSON: model.GeoJSON{Coordinates: []float64{0, 0}}}}
+	// default radius is 10km
+	if err := CheckDeliverable(business, []float64{0, 0.05}); err != nil {
+		t.Error(err)
+	}
+	if err := CheckDeliverable(business, []float64{0, 0.1}); err == nil {
+		t.Error("address out of default radius is deliverable")
+	}
+
+	business.DeliveryArea = &model.DeliveryArea{Radius: 3000}
+	if err := CheckDeliverable(business, []float64{0, 0.05}); err == nil {
+		t.Error("address out of radius of business is deliverable")
+	}
+
+	area := square
+	business.DeliveryArea = &model.DeliveryArea{Area: &area}
+	if err := CheckDeliverable(business, []float64{0.09, 0.09}); err != nil {
+		t.Error(err)
+	}
+	if err := CheckDeliverable(business, []float64{-0.01, 0.05}); err == nil {
+		t.Error("address out of area of business is deliverable")
+	}
+}
+
+func TestFilterDeliverable(t *testing.T) {
+	area := square
+	near := &model.PublicBusiness{GeoLocation: model.GeoLocation{GeoJSON: model.GeoJSON{Coordinates: []float64{0, 0.04}}}}
+	small := &model.PublicBusiness{GeoLocation: model.GeoLocation{GeoJSON: model.GeoJSON{Coordinates: []float64{0, 0}}},
+		DeliveryArea: &model.DeliveryArea{Radius: 3000}}
+	zoned := &model.PublicBusiness{GeoLocation: model.GeoLocation{GeoJSON: model.GeoJSON{Coordinates: []float64{1, 1}}},
+		DeliveryArea: &model.DeliveryArea{Area: &area}}
+
+	businesses := FilterDeliverable([]*model.PublicBusiness{near, small, zoned}, []float64{0.01, 0.05})
+	if len(businesses) != 2 || businesses[0] != near || businesses[1] != zoned {
+		t.Errorf("deliverable businesses = %v", businesses)
+	}
+}
+
+func TestCityZone(t *testing.T) {
+	city := &model.Location{ID: bson.NewObjectId(), City: "Lagos", PlaceID: "lagos", Latitude: 0, Longitude: 0, SearchRadius: 5000}
+	other := &model.Location{ID: bson.NewObjectId(), City: "Ibadan", PlaceID: "ibadan", Latitude: 0, Longitude: 0.2}
+	locations := []*model.Location{city, other}
+
+	// no zones configured, radius of location is used
+	zone, err := CityZone([]float64{0.03, 0}, locations, nil)
+	if err != nil || zone.PlaceID != "lagos" || zone.LocationID != city.ID || zone.ID != "" {
+		t.Errorf("CityZone = %+v, %v", zone, err)
+	}
+	// default radius is used when location has no radius
+	if zone, err := CityZone([]float64{0.12, 0}, locations, nil); err != nil || zone.PlaceID != "ibadan" {
+		t.Errorf("CityZone with default radius = %+v, %v", zone, err)
+	}
+	if _, err := CityZone([]float64{1, 1}, locations, nil); err == nil {
+		t.Error("address out of every city is served")
+	}
+	if _, err := CityZone([]float64{0.03, 0}, nil, nil); err == nil {
+		t.Error("address is served without locations")
+	}
+	// city with zones drawn is served only in zones
+	if _, err := CityZone([]float64{0.03, 0}, locations, map[bson.ObjectId]bool{city.ID: true}); err == nil {
+		t.Error("address out of zones of city is served")
+	}
+}
@@ -2,6 +2,8 @@ package email
 
 import (
 	"fmt"
+	"html"
+	"io"
 
 	"../../config"
 	"../../util/log"
@@ -34,6 +36,33 @@ func SendVerifyEmail(email, fullname, verifyCode string) error {
 	return nil
 }
 
+// SendReceiptEmail sends receipt of order with pdf attachment
+func SendReceiptEmail(email, fullname, subject, receipt string, attachment []byte, filename string) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", config.EmailUsername)
+	m.SetHeader("To", email)
+	m.SetHeader("Subject", subject)
+
+	text := `<p>Hi, <strong>` + html.EscapeString(fullname) + `</strong></p>
+			 <p>Thanks for your order. Here is your receipt.</p>
+			 ` + receipt
+	m.SetBody("text/html", getEmailBody(text))
+	m.Attach(filename, gomail.SetCopyFunc(func(w io.Writer) error {
+		_, err := w.Write(attachment)
+		return err
+	}))
+
+	// send the email to User
+	d := gomail.NewPlainDialer(config.EmailServer, config.EmailPort, config.EmailUsername, config.EmailPassword)
+	if err := d.DialAndSend(m); err != nil {
+		fmt.Println("ReceiptEmail sending is failed!", err)
+		return err
+	}
+
+	log.Info("ReceiptEmail sending is successed!")
+	return nil
+}
+
 func getEmailBody(text string) string {
 	body := `
 		<!doctype html>
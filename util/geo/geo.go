@@ -0,0 +1,27 @@
+package geo
+
+import "math"
+
+// EarthRadius is mean radius of earth in meters
+const EarthRadius = 6371000.0
+
+func radians(degree float64) float64 {
+	return degree * math.Pi / 180
+}
+
+// Distance returns great circle distance between two points in meters by haversine formula
+func Distance(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := radians(lat2 - lat1)
+	dLng := radians(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(radians(lat1))*math.Cos(radians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// DistanceOfCoordinates returns distance between geojson coordinates that are longitude and latitude
+func DistanceOfCoordinates(from []float64, to []float64) float64 {
+	if len(from) < 2 || len(to) < 2 {
+		return 0
+	}
+	return Distance(from[1], from[0], to[1], to[0])
+}
@@ -0,0 +1,116 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		lat1, lng1, lat2, lng2 float64
+		want                   float64 // meters
+	}{
+		{40.7128, -74.0060, 40.7128, -74.0060, 0},
+		{0, 0, 0, 1, 111195},
+		{51.5074, -0.1278, 48.8566, 2.3522, 343556},
+	}
+	for _, c := range cases {
+		got := Distance(c.lat1, c.lng1, c.lat2, c.lng2)
+		if math.Abs(got-c.want) > 1 {
+			t.Errorf("Distance(%v, %v, %v, %v) = %v, want %v", c.lat1, c.lng1, c.lat2, c.lng2, got, c.want)
+		}
+	}
+
+	if got := DistanceOfCoordinates([]float64{0, 0}, []float64{1, 0}); math.Abs(got-111195) > 1 {
+		t.Errorf("DistanceOfCoordinates = %v", got)
+	}
+	if got := DistanceOfCoordinates(nil, []float64{1, 0}); got != 0 {
+		t.Errorf("DistanceOfCoordinates of empty = %v", got)
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	// 0.0001 degree of latitude is about 11 meters
+	path := [][]float64{{0, 0}, {0.001, 0.00001}, {0.002, 0}, {0.003, 0.001}, {0.004, 0.002}}
+	simplified := Simplify(path, 5)
+	if len(simplified) != 3 || simplified[1][0] != 0.002 {
+		t.Errorf("Simplify = %v", simplified)
+	}
+	if got := Simplify(path, 0); len(got) != len(path) {
+		t.Errorf("Simplify without tolerance = %v", got)
+	}
+	if got := PathLength([][]float64{{0, 0}, {1, 0}, {1, 1}}); math.Abs(got-2*111195) > 20 {
+		t.Errorf("PathLength = %v", got)
+	}
+}
+
+func TestPolygon(t *testing.T) {
+	square := [][]float64{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}
+	hole := [][]float64{{1, 1}, {2, 1}, {2, 2}, {1, 2}, {1, 1}}
+	polygon := [][][]float64{square, hole}
+	if err := ValidPolygon(polygon); err != nil {
+		t.Error(err)
+	}
+	for _, invalid := range [][][][]float64{
+		nil,
+		{{{0, 0}, {1, 0}, {0, 0}}},
+		{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}},
+		{{{0, 0}, {200, 0}, {1, 1}, {0, 0}}},
+	} {
+		if ValidPolygon(invalid) == nil {
+			t.Errorf("polygon %v is valid", invalid)
+		}
+	}
+
+	cases := []struct {
+		point []float64
+		want  bool
+	}{
+		{[]float64{3, 3}, true},
+		{[]float64{1.5, 1.5}, false}, // in hole
+		{[]float64{5, 1}, false},
+		{[]float64{-1, 2}, false},
+	}
+	for _, c := range cases {
+		if got := InPolygon(c.point, polygon); got != c.want {
+			t.Errorf("InPolygon(%v) = %v, want %v", c.point, got, c.want)
+		}
+	}
+}
+
+func TestGeohash(t *testing.T) {
+	cases := []struct {
+		point     []float64
+		precision int
+		want      string
+	}{
+		{[]float64{10.40744, 57.64911}, 11, "u4pruydqqvj"},
+		{[]float64{-5.6, 42.6}, 5, "ezs42"},
+		{[]float64{-74.0060, 40.7128}, 6, "dr5reg"},
+		{nil, 6, ""},
+	}
+	for _, c := range cases {
+		if got := Geohash(c.point, c.precision); got != c.want {
+			t.Errorf("Geohash(%v, %d) = %q, want %q", c.point, c.precision, got, c.want)
+		}
+	}
+
+	sw, ne, err := DecodeGeohash("ezs42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sw[0] > -5.6 || ne[0] < -5.6 || sw[1] > 42.6 || ne[1] < 42.6 {
+		t.Errorf("cell of ezs42 = %v %v", sw, ne)
+	}
+	if math.Abs(ne[0]-sw[0]-0.0439453125) > 1e-12 || math.Abs(ne[1]-sw[1]-0.0439453125) > 1e-12 {
+		t.Errorf("size of cell of ezs42 = %v %v", sw, ne)
+	}
+	if _, _, err := DecodeGeohash("ezs4a"); err == nil {
+		t.Error("geohash with invalid character is decoded")
+	}
+
+	rings, err := GeohashPolygon("dr5reg")
+	if err != nil || ValidPolygon(rings) != nil || !InPolygon([]float64{-74.0060, 40.7128}, rings) {
+		t.Errorf("GeohashPolygon = %v, %v", rings, err)
+	}
+}
@@ -0,0 +1,92 @@
+package geo
+
+import (
+	"errors"
+	"strings"
+)
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Geohash encodes geojson point to geohash of precision characters
+func Geohash(point []float64, precision int) string {
+	if len(point) < 2 || precision <= 0 {
+		return ""
+	}
+	minLng, maxLng := -180.0, 180.0
+	minLat, maxLat := -90.0, 90.0
+	hash := make([]byte, 0, precision)
+	bits, char := 0, 0
+	// bits of longitude and latitude are interleaved, longitude first
+	for even := true; len(hash) < precision; even = !even {
+		char <<= 1
+		if even {
+			if mid := (minLng + maxLng) / 2; point[0] >= mid {
+				char |= 1
+				minLng = mid
+			} else {
+				maxLng = mid
+			}
+		} else {
+			if mid := (minLat + maxLat) / 2; point[1] >= mid {
+				char |= 1
+				minLat = mid
+			} else {
+				maxLat = mid
+			}
+		}
+		if bits++; bits == 5 {
+			hash = append(hash, geohashBase32[char])
+			bits, char = 0, 0
+		}
+	}
+	return string(hash)
+}
+
+// DecodeGeohash returns south west and north east corners of cell of geohash as geojson points
+func DecodeGeohash(hash string) ([]float64, []float64, error) {
+	if hash == "" {
+		return nil, nil, errors.New("Geohash is empty")
+	}
+	minLng, maxLng := -180.0, 180.0
+	minLat, maxLat := -90.0, 90.0
+	even := true
+	for _, c := range strings.ToLower(hash) {
+		char := strings.IndexRune(geohashBase32, c)
+		if char < 0 {
+			return nil, nil, errors.New("Geohash is invalid")
+		}
+		for bit := 4; bit >= 0; bit-- {
+			on := char>>uint(bit)&1 == 1
+			if even {
+				if mid := (minLng + maxLng) / 2; on {
+					minLng = mid
+				} else {
+					maxLng = mid
+				}
+			} else {
+				if mid := (minLat + maxLat) / 2; on {
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return []float64{minLng, minLat}, []float64{maxLng, maxLat}, nil
+}
+
+// GeohashPolygon returns cell of geohash as rings of geojson polygon
+func GeohashPolygon(hash string) ([][][]float64, error) {
+	sw, ne, err := DecodeGeohash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return [][][]float64{{
+		{sw[0], sw[1]},
+		{ne[0], sw[1]},
+		{ne[0], ne[1]},
+		{sw[0], ne[1]},
+		{sw[0], sw[1]},
+	}}, nil
+}
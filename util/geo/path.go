@@ -0,0 +1,65 @@
+package geo
+
+import "math"
+
+// PathLength returns length of path of geojson coordinates in meters
+func PathLength(path [][]float64) float64 {
+	length := 0.0
+	for i := 1; i < len(path); i++ {
+		length += DistanceOfCoordinates(path[i-1], path[i])
+	}
+	return length
+}
+
+// Simplify reduces points of path of geojson coordinates by Douglas-Peucker algorithm,
+// points that are closer than tolerance meters to simplified path are removed
+func Simplify(path [][]float64, tolerance float64) [][]float64 {
+	if len(path) < 3 || tolerance <= 0 {
+		return path
+	}
+	keep := make([]bool, len(path))
+	keep[0], keep[len(path)-1] = true, true
+
+	// ranges are processed with stack instead of recursion for long trips
+	stack := [][2]int{{0, len(path) - 1}}
+	for len(stack) > 0 {
+		first, last := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+
+		index, maxDistance := 0, 0.0
+		for i := first + 1; i < last; i++ {
+			if d := segmentDistance(path[i], path[first], path[last]); d > maxDistance {
+				index, maxDistance = i, d
+			}
+		}
+		if maxDistance > tolerance {
+			keep[index] = true
+			stack = append(stack, [2]int{first, index}, [2]int{index, last})
+		}
+	}
+
+	simplified := [][]float64{}
+	for i, point := range path {
+		if keep[i] {
+			simplified = append(simplified, point)
+		}
+	}
+	return simplified
+}
+
+// segmentDistance returns distance in meters from point to segment on local plane of segment
+func segmentDistance(point, from, to []float64) float64 {
+	scale := math.Cos(radians(from[1]))
+	project := func(p []float64) (float64, float64) {
+		return radians(p[0]-from[0]) * scale * EarthRadius, radians(p[1]-from[1]) * EarthRadius
+	}
+	px, py := project(point)
+	tx, ty := project(to)
+
+	length := tx*tx + ty*ty
+	if length == 0 {
+		return math.Hypot(px, py)
+	}
+	t := math.Max(0, math.Min(1, (px*tx+py*ty)/length))
+	return math.Hypot(px-t*tx, py-t*ty)
+}
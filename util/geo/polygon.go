@@ -0,0 +1,51 @@
+package geo
+
+import "errors"
+
+// ValidPolygon checks rings of geojson polygon, first ring is boundary and others are holes
+func ValidPolygon(rings [][][]float64) error {
+	if len(rings) == 0 {
+		return errors.New("Polygon has no ring")
+	}
+	for _, ring := range rings {
+		if len(ring) < 4 {
+			return errors.New("Ring of polygon needs at least 4 positions")
+		}
+		for _, position := range ring {
+			if len(position) < 2 || position[0] < -180 || position[0] > 180 || position[1] < -90 || position[1] > 90 {
+				return errors.New("Position of polygon is invalid")
+			}
+		}
+		first, last := ring[0], ring[len(ring)-1]
+		if first[0] != last[0] || first[1] != last[1] {
+			return errors.New("Ring of polygon is not closed")
+		}
+	}
+	return nil
+}
+
+// InPolygon returns true when geojson point is in boundary and out of holes of polygon
+func InPolygon(point []float64, rings [][][]float64) bool {
+	if len(point) < 2 || len(rings) == 0 || !inRing(point, rings[0]) {
+		return false
+	}
+	for _, hole := range rings[1:] {
+		if inRing(point, hole) {
+			return false
+		}
+	}
+	return true
+}
+
+// inRing casts ray from point to east and counts edges that it crosses
+func inRing(point []float64, ring [][]float64) bool {
+	x, y := point[0], point[1]
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi, xj, yj := ring[i][0], ring[i][1], ring[j][0], ring[j][1]
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
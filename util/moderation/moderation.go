@@ -0,0 +1,55 @@
+package moderation
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Reasons that hold text for manual review
+const (
+	ReasonProfanity = "profanity"
+	ReasonEmail     = "email"
+	ReasonPhone     = "phone"
+	ReasonURL       = "url"
+)
+
+var (
+	profanities = []string{
+		"asshole", "bastard", "bitch", "bullshit", "cunt", "dick", "fuck", "motherfucker", "shit", "slut", "whore",
+	}
+	wordRegex  = regexp.MustCompile(`[a-z]+`)
+	emailRegex = regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.-]+`)
+	phoneRegex = regexp.MustCompile(`\+?\d[\d\s().-]{7,}\d`)
+	urlRegex   = regexp.MustCompile(`(?i)(https?://|www\.)\S+`)
+)
+
+// Check returns reasons why text should be reviewed by admin, empty result means text is clean
+func Check(text string) []string {
+	reasons := []string{}
+	if containsProfanity(text) {
+		reasons = append(reasons, ReasonProfanity)
+	}
+	if emailRegex.MatchString(text) {
+		reasons = append(reasons, ReasonEmail)
+	}
+	if phoneRegex.MatchString(text) {
+		reasons = append(reasons, ReasonPhone)
+	}
+	if urlRegex.MatchString(text) {
+		reasons = append(reasons, ReasonURL)
+	}
+	return reasons
+}
+
+func containsProfanity(text string) bool {
+	// letters that are replaced to avoid filter
+	replacer := strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "@", "a", "$", "s", "!", "i")
+	for _, word := range wordRegex.FindAllString(replacer.Replace(strings.ToLower(text)), -1) {
+		for _, profanity := range profanities {
+			if strings.HasPrefix(word, profanity) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package moderation
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCheck(t *testing.T) {
+
+	Convey("Check that the review text is suspicious or not", t, func() {
+
+		Convey("when the clean text is passed", func() {
+			So(Check("Great pad thai, delivered hot in 20 minutes."), ShouldBeEmpty)
+		})
+
+		Convey("when the text contains masked profanity", func() {
+			So(Check("This food is $hit"), ShouldContain, ReasonProfanity)
+		})
+
+		Convey("when the text contains personal information", func() {
+			reasons := Check("Call me at +1 (555) 123-4567 or mail test@gogo.github.io, see www.example.com")
+			So(reasons, ShouldContain, ReasonPhone)
+			So(reasons, ShouldContain, ReasonEmail)
+			So(reasons, ShouldContain, ReasonURL)
+		})
+	})
+}
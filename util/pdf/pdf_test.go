@@ -0,0 +1,47 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	d := New()
+	d.Text(50, 700, 12, true, "Receipt (copy)")
+	d.Line(50, 690, 560, 690)
+	d.AddPage()
+	d.TextRight(560, 700, 10, false, "Café €5")
+	data := d.Bytes()
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Fatalf("pdf is not framed: %q", data)
+	}
+	if !bytes.Contains(data, []byte(`(Receipt \(copy\)) Tj`)) {
+		t.Error("text is not escaped")
+	}
+	if !bytes.Contains(data, []byte(`(Caf\351 ?5) Tj`)) {
+		t.Error("text is not encoded in WinAnsi")
+	}
+	if !bytes.Contains(data, []byte("/Count 2")) {
+		t.Error("pages are not counted")
+	}
+
+	// every offset of cross reference table points to its object
+	start := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(data)
+	xref, _ := strconv.Atoi(string(start[1]))
+	if !bytes.HasPrefix(data[xref:], []byte("xref\n0 9\n")) {
+		t.Fatalf("startxref %d does not point to xref", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	if len(entries) != 8 {
+		t.Fatalf("xref has %d entries", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := strconv.Itoa(i+1) + " 0 obj"; !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("offset of object %d is wrong", i+1)
+		}
+	}
+}
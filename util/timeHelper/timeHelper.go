@@ -82,3 +82,8 @@ func IsExpired(expirationTime time.Time) bool {
 	log.Debugf("after : %t", after)
 	return after
 }
+
+// ScheduleTime returns time of day on 1990-01-01 that business schedules are saved with
+func ScheduleTime(date time.Time) int64 {
+	return time.Date(1990, 1, 1, date.Hour(), date.Minute(), date.Second(), 0, time.Now().Location()).Unix()
+}
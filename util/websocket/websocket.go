@@ -0,0 +1,200 @@
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// opcodes of frames
+const (
+	OpText  = 0x1
+	OpClose = 0x8
+	OpPing  = 0x9
+	OpPong  = 0xA
+)
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxControlPayload is max length of control frame that client sends
+const maxControlPayload = 125
+
+// Conn is server side websocket connection that pushes messages to client
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	mutex  sync.Mutex
+	closed chan struct{}
+	once   sync.Once
+}
+
+// AcceptKey returns accept key of handshake for key of client
+func AcceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// Upgrade upgrades http request to websocket connection
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet ||
+		!strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		!strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade") {
+		return nil, errors.New("Request is not websocket handshake")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, errors.New("Websocket version is not supported")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, errors.New("Websocket key is empty")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("Connection can't be hijacked")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + AcceptKey(key) + "\r\n\r\n"
+	if _, err := conn.Write([]byte(response)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	c := &Conn{conn: conn, reader: rw.Reader, closed: make(chan struct{})}
+	go c.readLoop()
+	return c, nil
+}
+
+// Closed returns channel that is closed when connection is closed
+func (c *Conn) Closed() <-chan struct{} {
+	return c.closed
+}
+
+// WriteJSON sends value as json text message
+func (c *Conn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeFrame(OpText, data)
+}
+
+// Close sends close frame and closes connection
+func (c *Conn) Close() error {
+	c.writeFrame(OpClose, []byte{0x03, 0xE8}) // 1000 normal closure
+	return c.close()
+}
+
+func (c *Conn) close() error {
+	var err error
+	c.once.Do(func() {
+		close(c.closed)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := c.conn.Write(EncodeFrame(opcode, payload))
+	return err
+}
+
+// readLoop answers ping of client and closes connection when client closes it,
+// messages of client are ignored since connection only pushes to client
+func (c *Conn) readLoop() {
+	defer c.close()
+	for {
+		opcode, payload, err := ReadFrame(c.reader)
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case OpPing:
+			c.writeFrame(OpPong, payload)
+		case OpClose:
+			c.writeFrame(OpClose, payload)
+			return
+		}
+	}
+}
+
+// EncodeFrame returns unmasked final frame of server
+func EncodeFrame(opcode byte, payload []byte) []byte {
+	frame := []byte{0x80 | opcode}
+	length := len(payload)
+	switch {
+	case length <= 125:
+		frame = append(frame, byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, 126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(length))
+	default:
+		frame = append(frame, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(length))
+	}
+	return append(frame, payload...)
+}
+
+// ReadFrame reads frame of client and unmasks payload
+func ReadFrame(reader io.Reader) (byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return 0, nil, err
+	}
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(reader, extended); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		if _, err := io.ReadFull(reader, extended); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended)
+	}
+	// client only sends control frames to push connection
+	if opcode >= OpClose && length > maxControlPayload || length > 1<<20 {
+		return 0, nil, errors.New("Frame is too large")
+	}
+	var mask []byte
+	if masked {
+		mask = make([]byte, 4)
+		if _, err := io.ReadFull(reader, mask); err != nil {
+			return 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		if masked {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return opcode, payload, nil
+}
@@ -0,0 +1,73 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// example of RFC 6455
+	if got := AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("AcceptKey = %s", got)
+	}
+}
+
+func TestFrame(t *testing.T) {
+	for _, length := range []int{0, 125, 126, 70000} {
+		payload := bytes.Repeat([]byte{'a'}, length)
+		opcode, read, err := ReadFrame(bytes.NewReader(EncodeFrame(OpText, payload)))
+		if err != nil || opcode != OpText || !bytes.Equal(read, payload) {
+			t.Errorf("frame of %d bytes = %d %d %v", length, opcode, len(read), err)
+		}
+	}
+	// masked frame of client
+	masked := []byte{0x89, 0x82, 1, 2, 3, 4, 'h' ^ 1, 'i' ^ 2}
+	if opcode, payload, err := ReadFrame(bytes.NewReader(masked)); err != nil || opcode != OpPing || string(payload) != "hi" {
+		t.Errorf("masked frame = %d %q %v", opcode, payload, err)
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		conn.WriteJSON(map[string]string{"status": "ok"})
+		<-conn.Closed()
+	}))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n"))
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil || response.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake = %v %v", response, err)
+	}
+	if response.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("accept key = %s", response.Header.Get("Sec-WebSocket-Accept"))
+	}
+	opcode, payload, err := ReadFrame(reader)
+	if err != nil || opcode != OpText || string(payload) != `{"status":"ok"}` {
+		t.Errorf("message = %d %s %v", opcode, payload, err)
+	}
+
+	// close of client is answered
+	conn.Write([]byte{0x88, 0x80, 0, 0, 0, 0})
+	if opcode, _, err := ReadFrame(reader); err != nil || opcode != OpClose {
+		t.Errorf("close = %d %v", opcode, err)
+	}
+}